@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/alexandraswan/gcli/internal/auth"
@@ -27,18 +29,26 @@ You need to provide your Google OAuth client credentials. You can get these from
 the Google Cloud Console (https://console.cloud.google.com):
 
 1. Create a new project or select an existing one
-2. Enable the Gmail API and Google Calendar API
+2. Enable the Gmail API, Google Calendar API and People API
 3. Create OAuth 2.0 credentials (Desktop app type)
 4. Add http://localhost:8085/callback as an authorized redirect URI
 
-Example:
-  gcli auth add personal --client-id YOUR_CLIENT_ID --client-secret YOUR_CLIENT_SECRET`,
+Workspace administrators can instead use a service account with domain-wide
+delegation: pass its JSON key with --service-account-key and the user to act
+as with --subject. No browser sign-in is needed. Some settings, such as mail
+delegates, can only be managed this way.
+
+Examples:
+  gcli auth add personal --client-id YOUR_CLIENT_ID --client-secret YOUR_CLIENT_SECRET
+  gcli auth add ceo --service-account-key key.json --subject ceo@example.com`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		accountName := args[0]
 		clientID, _ := cmd.Flags().GetString("client-id")
 		clientSecret, _ := cmd.Flags().GetString("client-secret")
 		calendarID, _ := cmd.Flags().GetString("calendar-id")
+		keyFile, _ := cmd.Flags().GetString("service-account-key")
+		subject, _ := cmd.Flags().GetString("subject")
 
 		// Load existing config
 		cfg, err := config.Load()
@@ -51,6 +61,10 @@ Example:
 			return fmt.Errorf("account '%s' already exists. Use 'gcli auth remove %s' first", accountName, accountName)
 		}
 
+		if keyFile != "" || subject != "" {
+			return addServiceAccount(cfg, accountName, keyFile, subject, calendarID)
+		}
+
 		// Prompt for credentials if not provided
 		if clientID == "" {
 			fmt.Print("Enter Google Client ID: ")
@@ -107,7 +121,7 @@ var authListCmd = &cobra.Command{
 			accounts = append(accounts, output.AccountInfo{
 				Name:       name,
 				IsDefault:  name == cfg.DefaultAccount,
-				HasToken:   auth.TokenExists(name),
+				HasToken:   acc.IsServiceAccount() || auth.TokenExists(name),
 				CalendarID: acc.CalendarID,
 			})
 		}
@@ -175,6 +189,9 @@ var authReauthCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if account.IsServiceAccount() {
+			return fmt.Errorf("account '%s' uses a service account and has no token to renew", accountName)
+		}
 
 		// Remove existing token
 		auth.RemoveToken(accountName)
@@ -189,6 +206,42 @@ var authReauthCmd = &cobra.Command{
 	},
 }
 
+// addServiceAccount adds an account that acts as a user through a service
+// account with domain-wide delegation, checking that a token can be issued
+func addServiceAccount(cfg *config.Config, accountName, keyFile, subject, calendarID string) error {
+	if keyFile == "" || subject == "" {
+		return fmt.Errorf("--service-account-key and --subject must be used together")
+	}
+
+	keyPath, err := filepath.Abs(keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to resolve key path: %w", err)
+	}
+
+	account := config.AccountConfig{
+		CalendarID:        calendarID,
+		ServiceAccountKey: keyPath,
+		Subject:           subject,
+	}
+
+	// Gmail is the minimum; Calendar and Contacts scopes are only needed
+	// for the commands that use them
+	jwtConfig, err := auth.LoadServiceAccount(account, auth.GmailScopes)
+	if err != nil {
+		return err
+	}
+	if _, err := jwtConfig.TokenSource(context.Background()).Token(); err != nil {
+		return fmt.Errorf("failed to act as %s (check the domain-wide delegation scopes): %w", subject, err)
+	}
+
+	if err := cfg.AddAccount(accountName, account); err != nil {
+		return err
+	}
+
+	output.PrintSuccess("Account '%s' added, acting as %s", accountName, subject)
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(authCmd)
 	authCmd.AddCommand(authAddCmd)
@@ -200,4 +253,6 @@ func init() {
 	authAddCmd.Flags().String("client-id", "", "Google OAuth Client ID")
 	authAddCmd.Flags().String("client-secret", "", "Google OAuth Client Secret")
 	authAddCmd.Flags().String("calendar-id", "", "Calendar ID to use (default: primary)")
+	authAddCmd.Flags().String("service-account-key", "", "Service account JSON key with domain-wide delegation")
+	authAddCmd.Flags().String("subject", "", "User to act as with the service account")
 }
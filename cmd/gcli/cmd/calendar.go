@@ -233,6 +233,11 @@ Examples:
 			return err
 		}
 
+		attendeesStr, err = resolveRecipients(ctx, cfg, name, attendeesStr, true)
+		if err != nil {
+			return err
+		}
+
 		client, err := calendar.NewClient(ctx, name, acc)
 		if err != nil {
 			return err
@@ -291,6 +296,11 @@ Examples:
 			return err
 		}
 
+		attendeesStr, err = resolveRecipients(ctx, cfg, name, attendeesStr, true)
+		if err != nil {
+			return err
+		}
+
 		client, err := calendar.NewClient(ctx, name, acc)
 		if err != nil {
 			return err
@@ -427,10 +437,6 @@ func init() {
 	calCmd.AddCommand(calCalendarsCmd)
 
 	// Common flags
-	addAccountFlag := func(cmd *cobra.Command) {
-		cmd.Flags().StringP("account", "a", "", "Account to use (default: default account)")
-	}
-
 	addEventFlags := func(cmd *cobra.Command) {
 		cmd.Flags().StringP("summary", "s", "", "Event title/summary")
 		cmd.Flags().StringP("description", "d", "", "Event description")
@@ -438,7 +444,7 @@ func init() {
 		cmd.Flags().String("start", "", "Start time (ISO 8601 format)")
 		cmd.Flags().String("end", "", "End time (ISO 8601 format)")
 		cmd.Flags().Bool("all-day", false, "All-day event")
-		cmd.Flags().StringSlice("attendees", nil, "Attendee email addresses")
+		cmd.Flags().StringSlice("attendees", nil, "Attendee email addresses or contact names")
 	}
 
 	// calListCmd flags
@@ -0,0 +1,702 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/contacts"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// contactChange is a planned or applied change from 'contacts import'
+type contactChange struct {
+	Account string   `json:"account"`
+	Action  string   `json:"action"`
+	Name    string   `json:"name"`
+	Match   string   `json:"match,omitempty"`
+	Added   []string `json:"added,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+var contactsCmd = &cobra.Command{
+	Use:   "contacts",
+	Short: "Manage Google Contacts",
+	Long: `List, search, and edit the contacts of your Google accounts.
+
+Contacts also let you give recipients by name: 'gcli mail send-now --to jane'
+and 'gcli cal add --attendees jane' look up "jane" in the account's contacts.
+Contacts are cached for a day; changes made with gcli refresh the cache.`,
+}
+
+var contactsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List contacts",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		var all []output.Contact
+		for _, name := range accounts {
+			client, err := newContactsClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			found, err := client.ListContacts(ctx, limit)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+			if limit == 0 {
+				updateContactIndex(name, found)
+			}
+			all = append(all, found...)
+		}
+
+		output.PrintContacts(all)
+		return nil
+	},
+}
+
+var contactsSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search contacts",
+	Long: `Find contacts whose name, email address, phone number or organization
+starts with the query.
+
+Example:
+  gcli contacts search jane --all`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		var all []output.Contact
+		for _, name := range accounts {
+			client, err := newContactsClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			found, err := client.SearchContacts(ctx, args[0])
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+			all = append(all, found...)
+		}
+
+		output.PrintContacts(all)
+		return nil
+	},
+}
+
+var contactsGetCmd = &cobra.Command{
+	Use:   "get <contact-id>",
+	Short: "Show a contact",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		client, _, err := contactsClientFromFlags(ctx, cmd)
+		if err != nil {
+			return err
+		}
+
+		contact, err := client.GetContact(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		output.PrintContactDetail(contact)
+		return nil
+	},
+}
+
+var contactsCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a contact",
+	Long: `Create a contact.
+
+Example:
+  gcli contacts create --name "Jane Doe" --email jane@example.com --phone "+1 555 0100" --org Example`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		input := contactInputFromFlags(cmd)
+		if input.Name == "" && len(input.Emails) == 0 {
+			return fmt.Errorf("a name or email address is required (--name or --email)")
+		}
+
+		client, name, err := contactsClientFromFlags(ctx, cmd)
+		if err != nil {
+			return err
+		}
+
+		contact, err := client.CreateContact(ctx, input)
+		if err != nil {
+			return err
+		}
+		invalidateContactIndex(name)
+
+		if output.JSONOutput {
+			output.PrintJSON(contact)
+			return nil
+		}
+		output.PrintSuccess("Contact created (ID: %s)", contact.ID)
+		return nil
+	},
+}
+
+var contactsUpdateCmd = &cobra.Command{
+	Use:   "update <contact-id>",
+	Short: "Update a contact",
+	Long: `Update a contact. Only the given fields are changed; --email and --phone
+replace all of the contact's addresses or numbers.
+
+Example:
+  gcli contacts update c1234567890 --title "CTO" --email jane@example.com --email jane@home.example`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		input := contactInputFromFlags(cmd)
+		if input.Name == "" && input.Emails == nil && input.Phones == nil &&
+			input.Organization == "" && input.Title == "" && input.Notes == "" {
+			return fmt.Errorf("nothing to change (use --name, --email, --phone, --org, --title, or --notes)")
+		}
+
+		client, name, err := contactsClientFromFlags(ctx, cmd)
+		if err != nil {
+			return err
+		}
+
+		contact, err := client.UpdateContact(ctx, args[0], input)
+		if err != nil {
+			return err
+		}
+		invalidateContactIndex(name)
+
+		if output.JSONOutput {
+			output.PrintJSON(contact)
+			return nil
+		}
+		output.PrintSuccess("Contact updated")
+		return nil
+	},
+}
+
+var contactsDeleteCmd = &cobra.Command{
+	Use:   "delete <contact-id>",
+	Short: "Delete a contact",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		client, name, err := contactsClientFromFlags(ctx, cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := client.DeleteContact(ctx, args[0]); err != nil {
+			return err
+		}
+		invalidateContactIndex(name)
+
+		output.PrintSuccess("Contact deleted")
+		return nil
+	},
+}
+
+var contactsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export contacts as vCard or CSV",
+	Long: `Export all contacts of an account as vCard (.vcf) or CSV.
+
+CSV files use the column names of Google Contacts exports, so they can also
+be imported in the Google Contacts web app.
+
+Examples:
+  gcli contacts export -a personal -o contacts.vcf
+  gcli contacts export -a work --format csv > contacts.csv`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		outPath, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+
+		format = contactFileFormat(format, outPath)
+		if format != "vcf" && format != "csv" {
+			return fmt.Errorf("invalid format '%s' (use vcf or csv)", format)
+		}
+
+		client, _, err := contactsClientFromFlags(ctx, cmd)
+		if err != nil {
+			return err
+		}
+
+		list, err := client.ListContacts(ctx, 0)
+		if err != nil {
+			return err
+		}
+		for i := range list {
+			list[i].ID = ""
+			list[i].Account = ""
+		}
+
+		var w io.Writer = os.Stdout
+		if outPath != "" {
+			f, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", outPath, err)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		if format == "csv" {
+			err = contacts.WriteCSV(w, list)
+		} else {
+			err = contacts.WriteVCards(w, list)
+		}
+		if err != nil {
+			return err
+		}
+
+		if outPath != "" {
+			output.PrintSuccess("Exported %d contacts to %s", len(list), outPath)
+		}
+		return nil
+	},
+}
+
+var contactsImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import contacts from vCard or CSV",
+	Long: `Create the contacts in a vCard (.vcf) or CSV file, reading stdin for "-".
+
+A contact that shares an email address or phone number with an existing one
+is a duplicate and is skipped. With --merge, what the imported contact adds
+(email addresses, phone numbers, addresses, websites and empty fields) is
+merged into the existing contact instead. Use --dry-run to show the changes
+without applying them.
+
+Examples:
+  gcli contacts import contacts.vcf -a work --dry-run
+  gcli contacts export -a personal | gcli contacts import - -a work --merge`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		format, _ := cmd.Flags().GetString("format")
+		merge, _ := cmd.Flags().GetBool("merge")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		format = contactFileFormat(format, args[0])
+		if format != "vcf" && format != "csv" {
+			return fmt.Errorf("invalid format '%s' (use vcf or csv)", format)
+		}
+
+		var r io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open contacts: %w", err)
+			}
+			defer f.Close()
+			r = f
+		}
+
+		var imported []output.Contact
+		var err error
+		if format == "csv" {
+			imported, err = contacts.ReadCSV(r)
+		} else {
+			imported, err = contacts.ReadVCards(r)
+		}
+		if err != nil {
+			return err
+		}
+
+		client, name, err := contactsClientFromFlags(ctx, cmd)
+		if err != nil {
+			return err
+		}
+
+		existing, err := client.ListContacts(ctx, 0)
+		if err != nil {
+			return err
+		}
+		// Contacts created by this import count as existing too, so a file
+		// with duplicates does not create them twice
+		matcher := contacts.NewMatcher(existing)
+
+		changes := []contactChange{}
+		counts := make(map[string]int)
+		for _, c := range imported {
+			change := contactChange{Account: name, Name: contactLabel(c)}
+
+			match, found := matcher.Find(c)
+			switch {
+			case !found:
+				change.Action = "create"
+				if !dryRun {
+					created, err := client.CreateContact(ctx, contacts.InputFromContact(c))
+					if err != nil {
+						change.Error = err.Error()
+						break
+					}
+					c = created
+				}
+				matcher.Add(c)
+			case merge:
+				change.Match = match.ID
+				merged, input, added := contacts.Merge(match, c)
+				if len(added) == 0 {
+					change.Action = "skip"
+					break
+				}
+				change.Action = "merge"
+				change.Added = added
+				if !dryRun {
+					merged, err = client.UpdateContact(ctx, match.ID, input)
+					if err != nil {
+						change.Error = err.Error()
+						break
+					}
+				}
+				matcher.Add(merged)
+			default:
+				change.Action = "skip"
+				change.Match = match.ID
+			}
+
+			changes = append(changes, change)
+			if change.Error == "" {
+				counts[change.Action]++
+			}
+			if !output.JSONOutput {
+				printContactChange(change)
+			}
+		}
+		if !dryRun && (counts["create"] > 0 || counts["merge"] > 0) {
+			invalidateContactIndex(name)
+		}
+
+		if output.JSONOutput {
+			output.PrintJSON(changes)
+			return nil
+		}
+
+		verb := "Imported"
+		if dryRun {
+			verb = "Would import"
+		}
+		output.PrintSuccess("%s %d of %d contacts: %d created, %d merged, %d skipped",
+			verb, counts["create"]+counts["merge"], len(imported), counts["create"], counts["merge"], counts["skip"])
+		if !merge && counts["skip"] > 0 {
+			output.PrintInfo("Duplicates were skipped (use --merge to add their details to the existing contacts)")
+		}
+		return nil
+	},
+}
+
+// newContactsClient creates a People client for the named account
+func newContactsClient(ctx context.Context, cfg *config.Config, name string) (*contacts.Client, error) {
+	_, acc, err := cfg.GetAccount(name)
+	if err != nil {
+		return nil, err
+	}
+	return contacts.NewClient(ctx, name, acc)
+}
+
+// contactsClientFromFlags creates a People client for the account selected
+// by --account
+func contactsClientFromFlags(ctx context.Context, cmd *cobra.Command) (*contacts.Client, string, error) {
+	accountName, _ := cmd.Flags().GetString("account")
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	name, _, err := cfg.GetAccount(accountName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	client, err := newContactsClient(ctx, cfg, name)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, name, nil
+}
+
+// contactInputFromFlags builds a contact from the create and update flags
+func contactInputFromFlags(cmd *cobra.Command) contacts.ContactInput {
+	var input contacts.ContactInput
+	input.Name, _ = cmd.Flags().GetString("name")
+	input.Organization, _ = cmd.Flags().GetString("org")
+	input.Title, _ = cmd.Flags().GetString("title")
+	input.Notes, _ = cmd.Flags().GetString("notes")
+
+	if cmd.Flags().Changed("email") {
+		emails, _ := cmd.Flags().GetStringSlice("email")
+		input.Emails = []output.ContactField{}
+		for _, e := range emails {
+			input.Emails = append(input.Emails, output.ContactField{Value: e})
+		}
+	}
+	if cmd.Flags().Changed("phone") {
+		phones, _ := cmd.Flags().GetStringSlice("phone")
+		input.Phones = []output.ContactField{}
+		for _, p := range phones {
+			input.Phones = append(input.Phones, output.ContactField{Value: p})
+		}
+	}
+	return input
+}
+
+// contactFileFormat picks the contacts file format from the flag or file
+// extension
+func contactFileFormat(format, path string) string {
+	if format != "" {
+		return strings.ToLower(strings.TrimPrefix(format, "."))
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return "csv"
+	}
+	return "vcf"
+}
+
+// contactLabel names a contact by its name, or its first email address or
+// phone number
+func contactLabel(c output.Contact) string {
+	switch {
+	case c.Name != "":
+		return c.Name
+	case c.GivenName != "" || c.FamilyName != "":
+		return strings.TrimSpace(c.GivenName + " " + c.FamilyName)
+	case len(c.Emails) > 0:
+		return c.Emails[0].Value
+	case len(c.Phones) > 0:
+		return c.Phones[0].Value
+	}
+	return "(no name)"
+}
+
+// printContactChange prints a planned or applied import change in diff
+// style
+func printContactChange(c contactChange) {
+	// Contacts not created yet in a dry run have no ID
+	match := c.Match
+	if match == "" {
+		match = "a contact earlier in the file"
+	}
+
+	var line string
+	switch c.Action {
+	case "create":
+		line = "  + " + c.Name
+	case "merge":
+		line = fmt.Sprintf("  ~ %s (merged into %s: %s)", c.Name, match, strings.Join(c.Added, ", "))
+	default:
+		line = fmt.Sprintf("  = %s (duplicate of %s, skipped)", c.Name, match)
+	}
+	if c.Error != "" {
+		line += "  (failed: " + c.Error + ")"
+	}
+	fmt.Println(line)
+}
+
+// updateContactIndex caches an account's full contact list for resolving
+// names. Failures only cost a refresh later, so they are not reported.
+func updateContactIndex(account string, list []output.Contact) {
+	index, err := contacts.LoadIndex()
+	if err != nil {
+		return
+	}
+	index.Update(account, list, time.Now())
+	contacts.SaveIndex(index)
+}
+
+// invalidateContactIndex makes the next name lookup fetch the account's
+// contacts again
+func invalidateContactIndex(account string) {
+	index, err := contacts.LoadIndex()
+	if err != nil {
+		return
+	}
+	index.Invalidate(account)
+	contacts.SaveIndex(index)
+}
+
+// loadContactIndex loads the contacts index, fetching the account's
+// contacts when they are not cached or are out of date
+func loadContactIndex(ctx context.Context, cfg *config.Config, account string) (*contacts.Index, error) {
+	index, err := contacts.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+	if index.Fresh(account, time.Now()) {
+		return index, nil
+	}
+
+	client, err := newContactsClient(ctx, cfg, account)
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.ListContacts(ctx, 0)
+	if err != nil {
+		if index.Has(account) {
+			output.PrintWarning("Using cached contacts: %v", err)
+			return index, nil
+		}
+		return nil, err
+	}
+
+	index.Update(account, list, time.Now())
+	if err := contacts.SaveIndex(index); err != nil {
+		output.PrintWarning("%v", err)
+	}
+	return index, nil
+}
+
+// resolveRecipients replaces contact names among addresses with the
+// contact's address, as "Name <address>" or, with bare, just the address.
+// Values containing @ are kept as they are. A name matching several
+// contacts is asked about when running interactively.
+func resolveRecipients(ctx context.Context, cfg *config.Config, account string, values []string, bare bool) ([]string, error) {
+	var index *contacts.Index
+	resolved := make([]string, 0, len(values))
+	for _, v := range values {
+		if strings.Contains(v, "@") || strings.TrimSpace(v) == "" {
+			resolved = append(resolved, v)
+			continue
+		}
+
+		if index == nil {
+			var err error
+			if index, err = loadContactIndex(ctx, cfg, account); err != nil {
+				return nil, fmt.Errorf("failed to look up '%s' in contacts: %w", v, err)
+			}
+		}
+
+		entry, err := chooseContact(v, index.Lookup(account, v))
+		if err != nil {
+			return nil, err
+		}
+		if bare {
+			resolved = append(resolved, entry.Email)
+		} else {
+			resolved = append(resolved, entry.String())
+		}
+	}
+	return resolved, nil
+}
+
+// chooseContact picks the contact a name refers to, asking on the terminal
+// when it matches several
+func chooseContact(name string, matches []contacts.Entry) (contacts.Entry, error) {
+	switch len(matches) {
+	case 0:
+		return contacts.Entry{}, fmt.Errorf("no contact matches '%s' (use an email address)", name)
+	case 1:
+		return matches[0], nil
+	}
+
+	var options []string
+	for _, m := range matches {
+		options = append(options, m.String())
+	}
+	if stat, err := os.Stdin.Stat(); err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		return contacts.Entry{}, fmt.Errorf("'%s' matches several contacts: %s", name, strings.Join(options, ", "))
+	}
+
+	fmt.Fprintf(os.Stderr, "'%s' matches several contacts:\n", name)
+	for i, o := range options {
+		fmt.Fprintf(os.Stderr, "  %d. %s\n", i+1, o)
+	}
+	fmt.Fprintf(os.Stderr, "Which one? [1-%d]: ", len(options))
+
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	n, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || n < 1 || n > len(matches) {
+		return contacts.Entry{}, fmt.Errorf("no contact chosen for '%s'", name)
+	}
+	return matches[n-1], nil
+}
+
+func init() {
+	rootCmd.AddCommand(contactsCmd)
+	contactsCmd.AddCommand(contactsListCmd)
+	contactsCmd.AddCommand(contactsSearchCmd)
+	contactsCmd.AddCommand(contactsGetCmd)
+	contactsCmd.AddCommand(contactsCreateCmd)
+	contactsCmd.AddCommand(contactsUpdateCmd)
+	contactsCmd.AddCommand(contactsDeleteCmd)
+	contactsCmd.AddCommand(contactsExportCmd)
+	contactsCmd.AddCommand(contactsImportCmd)
+
+	addAccountFlag(contactsListCmd)
+	contactsListCmd.Flags().Bool("all", false, "List contacts of all accounts")
+	contactsListCmd.Flags().IntP("limit", "n", 0, "Maximum number of contacts per account (default: all)")
+
+	addAccountFlag(contactsSearchCmd)
+	contactsSearchCmd.Flags().Bool("all", false, "Search contacts of all accounts")
+
+	addAccountFlag(contactsGetCmd)
+	addAccountFlag(contactsDeleteCmd)
+
+	for _, c := range []*cobra.Command{contactsCreateCmd, contactsUpdateCmd} {
+		addAccountFlag(c)
+		c.Flags().String("name", "", "Full name")
+		c.Flags().StringSlice("email", nil, "Email address (repeatable)")
+		c.Flags().StringSlice("phone", nil, "Phone number (repeatable)")
+		c.Flags().String("org", "", "Organization")
+		c.Flags().String("title", "", "Job title")
+		c.Flags().String("notes", "", "Notes")
+	}
+
+	addAccountFlag(contactsExportCmd)
+	contactsExportCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+	contactsExportCmd.Flags().String("format", "", "Output format: vcf or csv (default: from file extension, else vcf)")
+
+	addAccountFlag(contactsImportCmd)
+	contactsImportCmd.Flags().String("format", "", "Input format: vcf or csv (default: from file extension, else vcf)")
+	contactsImportCmd.Flags().Bool("merge", false, "Merge duplicates into the existing contacts instead of skipping them")
+	contactsImportCmd.Flags().Bool("dry-run", false, "Show changes without applying them")
+}
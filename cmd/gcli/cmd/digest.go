@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/calendar"
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/markdown"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// digestDays is the number of days of events in the digest: today and
+// tomorrow
+const digestDays = 2
+
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Summarize mail and calendar across accounts",
+	Long: `Show one report covering all accounts: unread counts and top unread
+senders, starred emails, today's and tomorrow's events with any
+overlapping ones, and scheduled emails that have not been sent yet.
+
+The report is printed for the terminal, or as markdown or HTML with
+--format. With --email it is sent instead, as markdown with an HTML
+version, to your own address (or --to) from the default account (or
+--account). Use -a to cover a single account.
+
+Examples:
+  gcli digest
+  gcli digest --format html --theme github > digest.html
+  gcli digest --email`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		format, _ := cmd.Flags().GetString("format")
+		theme, _ := cmd.Flags().GetString("theme")
+		sendEmail, _ := cmd.Flags().GetBool("email")
+		to, _ := cmd.Flags().GetStringSlice("to")
+		top, _ := cmd.Flags().GetInt("top")
+		starred, _ := cmd.Flags().GetInt64("starred")
+		limit, _ := cmd.Flags().GetInt64("limit")
+
+		switch format {
+		case "terminal", "markdown", "html":
+		default:
+			return fmt.Errorf("unknown format '%s' (use terminal, markdown, or html)", format)
+		}
+		if len(to) > 0 {
+			sendEmail = true
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		// The digest covers every account unless one is chosen
+		accounts, err := resolveAccounts(cfg, accountName, accountName == "")
+		if err != nil {
+			return err
+		}
+
+		digest, err := buildDigest(ctx, cfg, accounts, top, starred, limit, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if sendEmail {
+			return sendDigest(ctx, cfg, accountName, to, theme, digest)
+		}
+
+		switch format {
+		case "markdown":
+			fmt.Print(output.DigestMarkdown(digest))
+		case "html":
+			rendered, err := markdown.Render(output.DigestMarkdown(digest), markdown.Options{Theme: theme})
+			if err != nil {
+				return err
+			}
+			fmt.Print(rendered.HTML)
+		default:
+			output.PrintDigest(digest)
+		}
+		return nil
+	},
+}
+
+// buildDigest collects mail, calendar and scheduled email summaries for the
+// given accounts. Accounts that cannot be reached are reported in the
+// digest rather than failing it.
+func buildDigest(ctx context.Context, cfg *config.Config, accounts []string, top int, starred, limit int64, now time.Time) (output.Digest, error) {
+	digest := output.Digest{GeneratedAt: now}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	end := today.AddDate(0, 0, digestDays)
+
+	var events []output.CalendarEventSummary
+	for _, name := range accounts {
+		summary := output.DigestAccount{Account: name}
+
+		if err := digestMail(ctx, cfg, &summary, top, starred, limit); err != nil {
+			summary.MailError = err.Error()
+		}
+
+		found, err := digestEvents(ctx, cfg, name, today, end)
+		if err != nil {
+			summary.CalendarError = err.Error()
+		}
+		events = append(events, found...)
+
+		digest.Accounts = append(digest.Accounts, summary)
+	}
+
+	events = mergeSharedEvents(events)
+	sortEventsByStart(events)
+	for i := range digestDays {
+		day := output.DigestDay{Date: today.AddDate(0, 0, i)}
+		for _, e := range events {
+			if eventOnDay(e, day.Date) {
+				day.Events = append(day.Events, e)
+			}
+		}
+		digest.Days = append(digest.Days, day)
+	}
+	digest.Conflicts = calendar.FindConflicts(events)
+
+	scheduled, err := gmail.GetScheduledEmailsByAccount("")
+	if err != nil {
+		return output.Digest{}, err
+	}
+	included := make(map[string]bool, len(accounts))
+	for _, name := range accounts {
+		included[name] = true
+	}
+	for _, s := range scheduled {
+		if !s.Sent && included[s.Account] {
+			digest.Scheduled = append(digest.Scheduled, s)
+		}
+	}
+	sort.Slice(digest.Scheduled, func(i, j int) bool {
+		return digest.Scheduled[i].ScheduledAt.Before(digest.Scheduled[j].ScheduledAt)
+	})
+
+	return digest, nil
+}
+
+// digestMail fills in an account's unread count, top unread senders and
+// starred emails
+func digestMail(ctx context.Context, cfg *config.Config, summary *output.DigestAccount, top int, starred, limit int64) error {
+	client, err := newGmailClient(ctx, cfg, summary.Account)
+	if err != nil {
+		return err
+	}
+
+	if summary.Unread, err = client.InboxUnreadCount(ctx); err != nil {
+		return err
+	}
+
+	if top > 0 && summary.Unread > 0 {
+		// Top senders are only a ranking, so emails that could not be read
+		// are left out rather than failing the digest
+		msgs, _, err := client.ListStatMessages(ctx, "in:inbox is:unread", limit)
+		if err != nil {
+			return err
+		}
+		summary.TopSenders = gmail.ComputeStats(msgs, top, time.Now()).Senders
+	}
+
+	if starred > 0 {
+		if summary.Starred, err = client.ListMessages(ctx, "is:starred", starred); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// digestEvents lists an account's events in the digest's date range
+func digestEvents(ctx context.Context, cfg *config.Config, name string, from, to time.Time) ([]output.CalendarEventSummary, error) {
+	_, acc, err := cfg.GetAccount(name)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := calendar.NewClient(ctx, name, acc)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.ListEvents(ctx, from, to, 0)
+}
+
+// mergeSharedEvents lists a meeting that appears on several accounts'
+// calendars once, naming all of the accounts
+func mergeSharedEvents(events []output.CalendarEventSummary) []output.CalendarEventSummary {
+	var merged []output.CalendarEventSummary
+	index := make(map[string]int)
+	for _, e := range events {
+		key := fmt.Sprintf("%s\x00%d\x00%d", e.Summary, e.Start.Unix(), e.End.Unix())
+		if i, ok := index[key]; ok {
+			merged[i].Account += ", " + e.Account
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, e)
+	}
+	return merged
+}
+
+// eventOnDay reports whether an event takes place on the day starting at
+// the given local midnight
+func eventOnDay(e output.CalendarEventSummary, day time.Time) bool {
+	if e.AllDay {
+		// All-day events are dates with an exclusive end date
+		date := day.Format("2006-01-02")
+		return e.Start.Format("2006-01-02") <= date && date < e.End.Format("2006-01-02")
+	}
+	return e.Start.Before(day.AddDate(0, 0, 1)) && e.End.After(day)
+}
+
+// sendDigest emails the digest from the chosen or default account, to the
+// account's own address unless recipients are given
+func sendDigest(ctx context.Context, cfg *config.Config, accountName string, to []string, theme string, digest output.Digest) error {
+	name, _, err := cfg.GetAccount(accountName)
+	if err != nil {
+		return err
+	}
+
+	client, err := newGmailClient(ctx, cfg, name)
+	if err != nil {
+		return err
+	}
+
+	alias, err := client.ResolveSendAs(ctx, "")
+	if err != nil {
+		return err
+	}
+	if len(to) == 0 {
+		to = []string{alias.Email}
+	}
+
+	email := gmail.DraftEmail{
+		From:    alias.Email,
+		To:      to,
+		Subject: "Digest for " + digest.GeneratedAt.Format("Monday, January 2"),
+		Body:    output.DigestMarkdown(digest),
+	}
+	if err := renderMarkdownBody(&email, theme, "."); err != nil {
+		return err
+	}
+
+	if _, err := client.SendEmail(ctx, email); err != nil {
+		return err
+	}
+
+	output.PrintSuccess("Digest sent to %s", strings.Join(to, ", "))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(digestCmd)
+
+	addAccountFlag(digestCmd)
+	digestCmd.Flags().String("format", "terminal", "Output format: terminal, markdown, or html")
+	digestCmd.Flags().String("theme", "", fmt.Sprintf("Inline CSS theme for HTML output and email (%s)", strings.Join(markdown.ThemeNames(), ", ")))
+	digestCmd.Flags().Bool("email", false, "Email the digest instead of printing it")
+	digestCmd.Flags().StringSlice("to", nil, "Email the digest to these addresses (default: yourself)")
+	digestCmd.Flags().Int("top", 5, "Number of top unread senders per account")
+	digestCmd.Flags().Int64("starred", 10, "Maximum number of starred emails per account")
+	digestCmd.Flags().Int64P("limit", "n", 500, "Maximum number of unread emails examined for top senders")
+}
@@ -3,13 +3,18 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/alexandraswan/gcli/internal/config"
 	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/markdown"
 	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/alexandraswan/gcli/internal/templates"
 	"github.com/spf13/cobra"
 )
 
@@ -30,19 +35,36 @@ Examples:
   gcli mail read -a work              # Read from work account
   gcli mail read --all                # Read from all accounts
   gcli mail read -q "is:unread"       # Filter unread emails
-  gcli mail read -n 20                # Limit to 20 emails`,
+  gcli mail read -n 20                # Limit to 20 emails
+  gcli mail read --offline            # Read from the local cache
+
+Once an account has been synced with 'gcli mail sync', emails are served
+from the local cache and only changes since the last sync are fetched.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 		accountName, _ := cmd.Flags().GetString("account")
 		allAccounts, _ := cmd.Flags().GetBool("all")
 		query, _ := cmd.Flags().GetString("query")
 		limit, _ := cmd.Flags().GetInt64("limit")
+		offline, _ := cmd.Flags().GetBool("offline")
+
+		if offline && query != "" {
+			return fmt.Errorf("--query is not supported with --offline")
+		}
 
 		cfg, err := config.Load()
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
+		cache, err := openCache(offline)
+		if err != nil {
+			return err
+		}
+		if cache != nil {
+			defer cache.Close()
+		}
+
 		if !cfg.HasAccounts() {
 			return fmt.Errorf("no accounts configured. Run 'gcli auth add <name>' first")
 		}
@@ -68,19 +90,7 @@ Examples:
 			go func(name string) {
 				defer wg.Done()
 
-				_, acc, err := cfg.GetAccount(name)
-				if err != nil {
-					errChan <- fmt.Errorf("[%s] %w", name, err)
-					return
-				}
-
-				client, err := gmail.NewClient(ctx, name, acc)
-				if err != nil {
-					errChan <- fmt.Errorf("[%s] %w", name, err)
-					return
-				}
-
-				emails, err := client.ListMessages(ctx, query, limit)
+				emails, err := readAccountMessages(ctx, cfg, cache, name, query, limit, offline)
 				if err != nil {
 					errChan <- fmt.Errorf("[%s] %w", name, err)
 					return
@@ -108,28 +118,104 @@ Examples:
 var mailGetCmd = &cobra.Command{
 	Use:   "get <message-id>",
 	Short: "Get email details",
-	Args:  cobra.ExactArgs(1),
+	Long: `Get the details and body of an email.
+
+The --format flag selects the body representation:
+  text   Plain text; HTML-only emails are rendered to readable text (default)
+  html   The HTML part, falling back to plain text
+  raw    The body part exactly as received, without conversion
+
+To look at the message itself rather than its body:
+  --headers    Every header, in order and undecoded
+  --raw        The full RFC 822 source, headers and all MIME parts, as
+               Gmail stores it (unlike --format raw, which is one body part)
+  --mime-tree  The MIME part structure with content types, charsets,
+               encodings and sizes, marking the parts shown as the body
+
+Examples:
+  gcli mail get MESSAGE_ID
+  gcli mail get MESSAGE_ID --format html > message.html
+  gcli mail get MESSAGE_ID --raw > message.eml
+  gcli mail get MESSAGE_ID --mime-tree`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 		messageID := args[0]
 		accountName, _ := cmd.Flags().GetString("account")
+		formatStr, _ := cmd.Flags().GetString("format")
+		offline, _ := cmd.Flags().GetBool("offline")
+		showHeaders, _ := cmd.Flags().GetBool("headers")
+		showRaw, _ := cmd.Flags().GetBool("raw")
+		showTree, _ := cmd.Flags().GetBool("mime-tree")
+
+		format, err := gmail.ParseBodyFormat(formatStr)
+		if err != nil {
+			return err
+		}
+
+		views := 0
+		for _, set := range []bool{showHeaders, showRaw, showTree} {
+			if set {
+				views++
+			}
+		}
+		if views > 1 {
+			return fmt.Errorf("only one of --headers, --raw, or --mime-tree can be used")
+		}
+		if views > 0 && offline {
+			return fmt.Errorf("--headers, --raw, and --mime-tree cannot be used with --offline")
+		}
 
 		cfg, err := config.Load()
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		name, acc, err := cfg.GetAccount(accountName)
+		name, _, err := cfg.GetAccount(accountName)
 		if err != nil {
 			return err
 		}
 
-		client, err := gmail.NewClient(ctx, name, acc)
+		if views > 0 {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case showHeaders:
+				headers, err := client.GetMessageHeaders(ctx, messageID)
+				if err != nil {
+					return err
+				}
+				output.PrintHeaders(headers)
+			case showRaw:
+				msg, err := client.GetRawMessage(ctx, messageID)
+				if err != nil {
+					return err
+				}
+				if _, err := os.Stdout.Write(msg.Raw); err != nil {
+					return fmt.Errorf("failed to write message: %w", err)
+				}
+			case showTree:
+				tree, err := client.GetMIMETree(ctx, messageID)
+				if err != nil {
+					return err
+				}
+				output.PrintMIMETree(tree)
+			}
+			return nil
+		}
+
+		cache, err := openCache(offline)
 		if err != nil {
 			return err
 		}
+		if cache != nil {
+			defer cache.Close()
+		}
 
-		email, err := client.GetMessage(ctx, messageID)
+		email, err := getAccountMessage(ctx, cfg, cache, name, messageID, format, offline)
 		if err != nil {
 			return err
 		}
@@ -149,21 +235,10 @@ Example:
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 		accountName, _ := cmd.Flags().GetString("account")
-		to, _ := cmd.Flags().GetStringSlice("to")
-		cc, _ := cmd.Flags().GetStringSlice("cc")
-		bcc, _ := cmd.Flags().GetStringSlice("bcc")
-		subject, _ := cmd.Flags().GetString("subject")
-		body, _ := cmd.Flags().GetString("body")
-		html, _ := cmd.Flags().GetBool("html")
 
-		if len(to) == 0 {
-			return fmt.Errorf("at least one recipient is required (--to)")
-		}
-		if subject == "" {
-			return fmt.Errorf("subject is required (--subject)")
-		}
-		if body == "" {
-			return fmt.Errorf("body is required (--body)")
+		draft, err := composeFromFlags(cmd)
+		if err != nil {
+			return err
 		}
 
 		cfg, err := config.Load()
@@ -181,13 +256,11 @@ Example:
 			return err
 		}
 
-		draft := gmail.DraftEmail{
-			To:      to,
-			CC:      cc,
-			BCC:     bcc,
-			Subject: subject,
-			Body:    body,
-			IsHTML:  html,
+		if err := resolveDraftRecipients(ctx, cfg, name, &draft); err != nil {
+			return err
+		}
+		if err := applySender(ctx, cmd, client, &draft); err != nil {
+			return err
 		}
 
 		draftID, err := client.CreateDraft(ctx, draft)
@@ -239,26 +312,45 @@ var mailSendNowCmd = &cobra.Command{
 	Short: "Compose and send an email immediately",
 	Long: `Compose and send an email directly without creating a draft first.
 
-Example:
-  gcli mail send-now -t "user@example.com" -s "Hello" -b "Message body"`,
+Use --markdown to write the body in markdown. The message is sent as
+multipart/alternative with the markdown source as the plain text part and
+the rendered HTML alongside it. Local images referenced from the markdown
+are embedded inline.
+
+Use --from to send from one of the account's verified send-as aliases. The
+signature configured for the sending alias (the default alias without
+--from) is appended unless --no-signature is given.
+
+With --remind-if-no-reply the email is tracked until someone replies; see
+'mail followups'. It takes a duration (3d) or a time (friday 9am), and with
+--remind-calendar a calendar reminder is also added at that time.
+
+Examples:
+  gcli mail send-now -t "user@example.com" -s "Hello" -b "Message body"
+  gcli mail send-now -t "user@example.com" -s "Hello" -b "Hi" --from support@example.com
+  gcli mail send-now -t "vendor@example.com" -s "Quote?" -b "..." --remind-if-no-reply 3d
+  gcli mail send-now -t "user@example.com" -s "Report" --body-file report.md --markdown --theme github`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 		accountName, _ := cmd.Flags().GetString("account")
-		to, _ := cmd.Flags().GetStringSlice("to")
-		cc, _ := cmd.Flags().GetStringSlice("cc")
-		bcc, _ := cmd.Flags().GetStringSlice("bcc")
-		subject, _ := cmd.Flags().GetString("subject")
-		body, _ := cmd.Flags().GetString("body")
-		html, _ := cmd.Flags().GetBool("html")
+		remindStr, _ := cmd.Flags().GetString("remind-if-no-reply")
+		remindCalendar, _ := cmd.Flags().GetBool("remind-calendar")
 
-		if len(to) == 0 {
-			return fmt.Errorf("at least one recipient is required (--to)")
-		}
-		if subject == "" {
-			return fmt.Errorf("subject is required (--subject)")
+		draft, err := composeFromFlags(cmd)
+		if err != nil {
+			return err
 		}
-		if body == "" {
-			return fmt.Errorf("body is required (--body)")
+
+		var deadline time.Time
+		if remindStr != "" {
+			if deadline, err = parseWhen(remindStr, time.Now()); err != nil {
+				return err
+			}
+			if !deadline.After(time.Now()) {
+				return fmt.Errorf("--remind-if-no-reply must be in the future")
+			}
+		} else if remindCalendar {
+			return fmt.Errorf("--remind-calendar requires --remind-if-no-reply")
 		}
 
 		cfg, err := config.Load()
@@ -276,21 +368,26 @@ Example:
 			return err
 		}
 
-		email := gmail.DraftEmail{
-			To:      to,
-			CC:      cc,
-			BCC:     bcc,
-			Subject: subject,
-			Body:    body,
-			IsHTML:  html,
+		if err := resolveDraftRecipients(ctx, cfg, name, &draft); err != nil {
+			return err
+		}
+		if err := applySender(ctx, cmd, client, &draft); err != nil {
+			return err
 		}
 
-		msgID, err := client.SendEmail(ctx, email)
+		msgID, err := client.SendEmail(ctx, draft)
 		if err != nil {
 			return err
 		}
 
 		output.PrintSuccess("Email sent (Message ID: %s)", msgID)
+
+		if !deadline.IsZero() {
+			if err := trackFollowup(ctx, client, acc, msgID, draft, deadline, remindCalendar); err != nil {
+				return fmt.Errorf("email sent but reply tracking failed: %w", err)
+			}
+			output.PrintInfo("Waiting for a reply until %s", deadline.Format("Mon 2006-01-02 15:04"))
+		}
 		return nil
 	},
 }
@@ -307,22 +404,11 @@ Example:
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 		accountName, _ := cmd.Flags().GetString("account")
-		to, _ := cmd.Flags().GetStringSlice("to")
-		cc, _ := cmd.Flags().GetStringSlice("cc")
-		bcc, _ := cmd.Flags().GetStringSlice("bcc")
-		subject, _ := cmd.Flags().GetString("subject")
-		body, _ := cmd.Flags().GetString("body")
-		html, _ := cmd.Flags().GetBool("html")
 		atStr, _ := cmd.Flags().GetString("at")
 
-		if len(to) == 0 {
-			return fmt.Errorf("at least one recipient is required (--to)")
-		}
-		if subject == "" {
-			return fmt.Errorf("subject is required (--subject)")
-		}
-		if body == "" {
-			return fmt.Errorf("body is required (--body)")
+		draft, err := composeFromFlags(cmd)
+		if err != nil {
+			return err
 		}
 		if atStr == "" {
 			return fmt.Errorf("schedule time is required (--at)")
@@ -353,16 +439,14 @@ Example:
 			return err
 		}
 
-		// Create draft
-		draft := gmail.DraftEmail{
-			To:      to,
-			CC:      cc,
-			BCC:     bcc,
-			Subject: subject,
-			Body:    body,
-			IsHTML:  html,
+		if err := resolveDraftRecipients(ctx, cfg, name, &draft); err != nil {
+			return err
+		}
+		if err := applySender(ctx, cmd, client, &draft); err != nil {
+			return err
 		}
 
+		// Create draft
 		draftID, err := client.CreateDraft(ctx, draft)
 		if err != nil {
 			return err
@@ -372,12 +456,12 @@ Example:
 		scheduled := gmail.ScheduledEmailData{
 			Account:     name,
 			DraftID:     draftID,
-			To:          to,
-			CC:          cc,
-			BCC:         bcc,
-			Subject:     subject,
-			Body:        body,
-			IsHTML:      html,
+			To:          draft.To,
+			CC:          draft.CC,
+			BCC:         draft.BCC,
+			Subject:     draft.Subject,
+			Body:        draft.Body,
+			IsHTML:      draft.IsHTML || draft.HTMLBody != "",
 			ScheduledAt: scheduledAt,
 		}
 
@@ -533,17 +617,20 @@ func init() {
 	mailScheduledCmd.AddCommand(mailScheduledClearCmd)
 
 	// Common flags
-	addAccountFlag := func(cmd *cobra.Command) {
-		cmd.Flags().StringP("account", "a", "", "Account to use (default: default account)")
-	}
-
 	addEmailFlags := func(cmd *cobra.Command) {
-		cmd.Flags().StringSliceP("to", "t", nil, "Recipient email addresses")
-		cmd.Flags().StringSlice("cc", nil, "CC email addresses")
-		cmd.Flags().StringSlice("bcc", nil, "BCC email addresses")
+		cmd.Flags().StringSliceP("to", "t", nil, "Recipient email addresses or contact names")
+		cmd.Flags().StringSlice("cc", nil, "CC email addresses or contact names")
+		cmd.Flags().StringSlice("bcc", nil, "BCC email addresses or contact names")
 		cmd.Flags().StringP("subject", "s", "", "Email subject")
 		cmd.Flags().StringP("body", "b", "", "Email body")
+		cmd.Flags().String("body-file", "", "Read email body from a file ('-' for stdin)")
 		cmd.Flags().Bool("html", false, "Body is HTML format")
+		cmd.Flags().Bool("markdown", false, "Body is markdown; send plain text and rendered HTML")
+		cmd.Flags().String("theme", "", fmt.Sprintf("Inline CSS theme for markdown (%s)", strings.Join(markdown.ThemeNames(), ", ")))
+		cmd.Flags().String("from", "", "Send from this verified alias (see 'mail aliases list')")
+		cmd.Flags().Bool("no-signature", false, "Do not append the alias's signature")
+		cmd.Flags().String("template", "", "Compose from a template in the templates directory (see 'mail templates list')")
+		cmd.Flags().StringArray("var", nil, "Template variable as key=value (repeatable)")
 	}
 
 	// mailReadCmd flags
@@ -551,9 +638,15 @@ func init() {
 	mailReadCmd.Flags().Bool("all", false, "Read from all accounts")
 	mailReadCmd.Flags().StringP("query", "q", "", "Gmail search query")
 	mailReadCmd.Flags().Int64P("limit", "n", 25, "Maximum number of emails to fetch")
+	mailReadCmd.Flags().Bool("offline", false, "Read from the local cache without network access")
 
 	// mailGetCmd flags
 	addAccountFlag(mailGetCmd)
+	mailGetCmd.Flags().String("format", "text", "Body format: text, html, or raw")
+	mailGetCmd.Flags().Bool("offline", false, "Read from the local cache without network access")
+	mailGetCmd.Flags().Bool("headers", false, "Show all headers instead of the email")
+	mailGetCmd.Flags().Bool("raw", false, "Print the full RFC 822 source of the email")
+	mailGetCmd.Flags().Bool("mime-tree", false, "Show the MIME part structure of the email")
 
 	// mailDraftCmd flags
 	addAccountFlag(mailDraftCmd)
@@ -565,6 +658,8 @@ func init() {
 	// mailSendNowCmd flags
 	addAccountFlag(mailSendNowCmd)
 	addEmailFlags(mailSendNowCmd)
+	mailSendNowCmd.Flags().String("remind-if-no-reply", "", "Track the email until a reply arrives, due after this time (e.g. 3d)")
+	mailSendNowCmd.Flags().Bool("remind-calendar", false, "Also add a calendar reminder at the reply deadline")
 
 	// mailScheduleCmd flags
 	addAccountFlag(mailScheduleCmd)
@@ -585,6 +680,197 @@ func init() {
 	mailScheduledClearCmd.Flags().Bool("all", false, "Clear all scheduled emails")
 }
 
+// composeFromFlags builds an email from the common compose flags
+func composeFromFlags(cmd *cobra.Command) (gmail.DraftEmail, error) {
+	to, _ := cmd.Flags().GetStringSlice("to")
+	cc, _ := cmd.Flags().GetStringSlice("cc")
+	bcc, _ := cmd.Flags().GetStringSlice("bcc")
+	subject, _ := cmd.Flags().GetString("subject")
+	body, _ := cmd.Flags().GetString("body")
+	bodyFile, _ := cmd.Flags().GetString("body-file")
+	html, _ := cmd.Flags().GetBool("html")
+	isMarkdown, _ := cmd.Flags().GetBool("markdown")
+	theme, _ := cmd.Flags().GetString("theme")
+	templateName, _ := cmd.Flags().GetString("template")
+	vars, _ := cmd.Flags().GetStringArray("var")
+
+	if body != "" && bodyFile != "" {
+		return gmail.DraftEmail{}, fmt.Errorf("--body and --body-file cannot be used together")
+	}
+	if html && isMarkdown {
+		return gmail.DraftEmail{}, fmt.Errorf("--html and --markdown cannot be used together")
+	}
+
+	// Relative image paths in markdown resolve against the body file's directory
+	baseDir := "."
+
+	if templateName != "" {
+		if body != "" || bodyFile != "" {
+			return gmail.DraftEmail{}, fmt.Errorf("--template cannot be used with --body or --body-file")
+		}
+		if html || isMarkdown {
+			return gmail.DraftEmail{}, fmt.Errorf("--template cannot be used with --html or --markdown; the format follows the template's file extension")
+		}
+
+		tmpl, err := templates.Load(templateName)
+		if err != nil {
+			return gmail.DraftEmail{}, err
+		}
+		values, err := parseVars(vars)
+		if err != nil {
+			return gmail.DraftEmail{}, err
+		}
+		msg, err := tmpl.Render(values)
+		if err != nil {
+			return gmail.DraftEmail{}, err
+		}
+
+		// Flags take precedence over the template's header fields
+		if subject == "" {
+			subject = msg.Subject
+		}
+		if len(to) == 0 {
+			to = msg.To
+		}
+		if len(cc) == 0 {
+			cc = msg.CC
+		}
+		if len(bcc) == 0 {
+			bcc = msg.BCC
+		}
+		body = msg.Body
+		html = msg.Format == templates.FormatHTML
+		isMarkdown = msg.Format == templates.FormatMarkdown
+		baseDir = filepath.Dir(tmpl.Path)
+	} else if len(vars) > 0 {
+		return gmail.DraftEmail{}, fmt.Errorf("--var requires --template")
+	}
+
+	if len(to) == 0 {
+		return gmail.DraftEmail{}, fmt.Errorf("at least one recipient is required (--to)")
+	}
+	if subject == "" {
+		return gmail.DraftEmail{}, fmt.Errorf("subject is required (--subject)")
+	}
+	if theme != "" && !isMarkdown {
+		return gmail.DraftEmail{}, fmt.Errorf("--theme requires --markdown")
+	}
+
+	if bodyFile != "" {
+		data, err := readBodyFile(bodyFile)
+		if err != nil {
+			return gmail.DraftEmail{}, err
+		}
+		body = data
+		if bodyFile != "-" {
+			baseDir = filepath.Dir(bodyFile)
+		}
+	}
+
+	if body == "" {
+		return gmail.DraftEmail{}, fmt.Errorf("body is required (--body or --body-file)")
+	}
+
+	email := gmail.DraftEmail{
+		To:      to,
+		CC:      cc,
+		BCC:     bcc,
+		Subject: subject,
+		Body:    body,
+		IsHTML:  html,
+	}
+
+	if isMarkdown {
+		if err := renderMarkdownBody(&email, theme, baseDir); err != nil {
+			return gmail.DraftEmail{}, err
+		}
+	}
+
+	return email, nil
+}
+
+// renderMarkdownBody renders the markdown body of an email as its HTML
+// alternative, embedding local images inline
+func renderMarkdownBody(email *gmail.DraftEmail, theme, baseDir string) error {
+	rendered, err := markdown.Render(email.Body, markdown.Options{
+		Theme:   theme,
+		BaseDir: baseDir,
+	})
+	if err != nil {
+		return err
+	}
+	email.HTMLBody = rendered.HTML
+	for _, img := range rendered.Images {
+		email.InlineImages = append(email.InlineImages, gmail.InlineImage{
+			ContentID:   img.ContentID,
+			Filename:    img.Filename,
+			ContentType: img.ContentType,
+			Data:        img.Data,
+		})
+	}
+	return nil
+}
+
+// parseVars parses template variables given as key=value
+func parseVars(vars []string) (map[string]string, error) {
+	values := make(map[string]string, len(vars))
+	for _, v := range vars {
+		key, value, ok := strings.Cut(v, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid variable '%s' (expected key=value)", v)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// resolveDraftRecipients replaces contact names among an email's recipients
+// with their addresses
+func resolveDraftRecipients(ctx context.Context, cfg *config.Config, account string, draft *gmail.DraftEmail) error {
+	for _, field := range []*[]string{&draft.To, &draft.CC, &draft.BCC} {
+		resolved, err := resolveRecipients(ctx, cfg, account, *field, false)
+		if err != nil {
+			return err
+		}
+		*field = resolved
+	}
+	return nil
+}
+
+// applySender sets the From address from --from and appends the sending
+// alias's signature unless --no-signature is given
+func applySender(ctx context.Context, cmd *cobra.Command, client *gmail.Client, draft *gmail.DraftEmail) error {
+	from, _ := cmd.Flags().GetString("from")
+	noSignature, _ := cmd.Flags().GetBool("no-signature")
+
+	if from == "" && noSignature {
+		return nil
+	}
+
+	alias, err := client.ResolveSendAs(ctx, from)
+	if err != nil {
+		return err
+	}
+	draft.SetSender(alias, !noSignature)
+	return nil
+}
+
+// readBodyFile reads a message body from a file, or stdin when path is "-"
+func readBodyFile(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read body file: %w", err)
+	}
+	return string(data), nil
+}
+
 // parseDateTime parses a datetime string in various formats
 func parseDateTime(s string) (time.Time, error) {
 	formats := []string{
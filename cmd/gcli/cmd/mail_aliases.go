@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var mailAliasesCmd = &cobra.Command{
+	Use:   "aliases",
+	Short: "Manage send-as aliases",
+	Long: `List the addresses an account can send mail as.
+
+Aliases and their signatures are configured in Gmail's settings under
+"Send mail as". Use --from on draft, send-now and schedule to send from a
+verified alias.`,
+}
+
+var mailAliasesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List send-as aliases",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		var all []output.SendAs
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			aliases, err := client.ListSendAs(ctx)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+			all = append(all, aliases...)
+		}
+
+		output.PrintSendAs(all)
+		return nil
+	},
+}
+
+func init() {
+	mailCmd.AddCommand(mailAliasesCmd)
+	mailAliasesCmd.AddCommand(mailAliasesListCmd)
+
+	addAccountFlag(mailAliasesListCmd)
+	mailAliasesListCmd.Flags().Bool("all", false, "List aliases of all accounts")
+}
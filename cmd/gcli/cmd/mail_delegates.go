@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var mailDelegatesCmd = &cobra.Command{
+	Use:   "delegates",
+	Short: "Manage mailbox delegates",
+	Long: `List, add, and remove users who can read, send, and delete mail on an
+account's behalf.
+
+Gmail only allows delegates to be managed for Workspace accounts through a
+service account with domain-wide delegation (see 'gcli auth add --help').
+Delegates in the same organization are accepted right away; others are
+sent an invitation and show as pending until they accept it.`,
+}
+
+var mailDelegatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List mailbox delegates",
+	Long: `List the users who can access an account's mailbox and whether they
+have accepted.
+
+Examples:
+  gcli mail delegates list -a ceo
+  gcli mail delegates list --all --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		var all []output.Delegate
+		for _, name := range accounts {
+			client, err := newGmailSharingClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			delegates, err := client.ListDelegates(ctx)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+			all = append(all, delegates...)
+		}
+
+		output.PrintDelegates(all)
+		return nil
+	},
+}
+
+var mailDelegatesAddCmd = &cobra.Command{
+	Use:   "add <email>",
+	Short: "Give a user access to the mailbox",
+	Long: `Give a user access to an account's mailbox.
+
+Example:
+  gcli mail delegates add assistant@example.com -a ceo`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		name, _, err := cfg.GetAccount(accountName)
+		if err != nil {
+			return err
+		}
+
+		client, err := newGmailSharingClient(ctx, cfg, name)
+		if err != nil {
+			return err
+		}
+
+		delegate, err := client.AddDelegate(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		if output.JSONOutput {
+			output.PrintJSON(delegate)
+			return nil
+		}
+		if delegate.VerificationStatus == "pending" {
+			output.PrintSuccess("Invited %s as a delegate of %s; access starts once they accept", delegate.Email, name)
+			return nil
+		}
+		output.PrintSuccess("Added %s as a delegate of %s", delegate.Email, name)
+		return nil
+	},
+}
+
+var mailDelegatesRemoveCmd = &cobra.Command{
+	Use:   "remove <email>",
+	Short: "Revoke a user's access to the mailbox",
+	Long: `Revoke a delegate's access to an account's mailbox, including pending
+invitations.
+
+Example:
+  gcli mail delegates remove assistant@example.com -a ceo`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		name, _, err := cfg.GetAccount(accountName)
+		if err != nil {
+			return err
+		}
+
+		client, err := newGmailSharingClient(ctx, cfg, name)
+		if err != nil {
+			return err
+		}
+
+		if err := client.RemoveDelegate(ctx, args[0]); err != nil {
+			return err
+		}
+
+		output.PrintSuccess("Removed %s as a delegate of %s", args[0], name)
+		return nil
+	},
+}
+
+func init() {
+	mailCmd.AddCommand(mailDelegatesCmd)
+	mailDelegatesCmd.AddCommand(mailDelegatesListCmd)
+	mailDelegatesCmd.AddCommand(mailDelegatesAddCmd)
+	mailDelegatesCmd.AddCommand(mailDelegatesRemoveCmd)
+
+	addAccountFlag(mailDelegatesListCmd)
+	mailDelegatesListCmd.Flags().Bool("all", false, "List delegates of all accounts")
+	addAccountFlag(mailDelegatesAddCmd)
+	addAccountFlag(mailDelegatesRemoveCmd)
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/mailbox"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var mailExportCmd = &cobra.Command{
+	Use:   "export [message-id...]",
+	Short: "Export emails to .eml files or an mbox archive",
+	Long: `Download emails in their original RFC 822 form and write them to disk.
+
+Messages can be selected by ID or with a Gmail search query. With --format eml
+(the default) each message is written to its own file named by date and
+subject; with --format mbox all messages are appended to a single mbox file.
+
+A manifest (manifest.json in the output directory, or <file>.manifest.json
+for mbox) lists every exported message with its SHA-256 checksum. Re-running
+the same export skips messages already in the manifest, so an interrupted
+export can be resumed. An existing mbox file without a manifest is only
+overwritten with --force.
+
+Examples:
+  gcli mail export MSG_ID1 MSG_ID2 -o ./export
+  gcli mail export -q "from:legal@example.com" --all -o ./legal-hold
+  gcli mail export -q "label:project-x" --format mbox -o project-x.mbox`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+		query, _ := cmd.Flags().GetString("query")
+		limit, _ := cmd.Flags().GetInt64("limit")
+		format, _ := cmd.Flags().GetString("format")
+		outPath, _ := cmd.Flags().GetString("output")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if len(args) == 0 && query == "" {
+			return fmt.Errorf("specify message IDs or a search query (--query)")
+		}
+		if len(args) > 0 && query != "" {
+			return fmt.Errorf("message IDs and --query cannot be used together")
+		}
+		if len(args) > 0 && allAccounts {
+			return fmt.Errorf("message IDs belong to a single account; use --account instead of --all")
+		}
+		if format != "eml" && format != "mbox" {
+			return fmt.Errorf("unknown format '%s' (use eml or mbox)", format)
+		}
+
+		if outPath == "" {
+			outPath = "gcli-export"
+			if format == "mbox" {
+				outPath = "gcli-export.mbox"
+			}
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		exporter, err := newMailExporter(format, outPath, query, len(accounts) > 1, force)
+		if err != nil {
+			return err
+		}
+		defer exporter.Close()
+
+		var exported, skipped, failed int
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				failed++
+				continue
+			}
+
+			var refs []gmail.MessageRef
+			if len(args) > 0 {
+				for _, id := range args {
+					refs = append(refs, gmail.MessageRef{ID: id})
+				}
+			} else {
+				refs, err = client.ListMessageIDs(ctx, query, limit)
+				if err != nil {
+					output.PrintError("[%s] %v", name, err)
+					failed++
+					continue
+				}
+			}
+
+			for i, ref := range refs {
+				if exporter.Done(name, ref.ID) {
+					skipped++
+					continue
+				}
+
+				entry, err := exporter.Export(ctx, client, name, ref.ID)
+				if err != nil {
+					output.PrintError("[%s] %s: %v", name, ref.ID, err)
+					failed++
+					continue
+				}
+				exported++
+
+				if !output.JSONOutput {
+					fmt.Printf("[%s %d/%d] %s\n", name, i+1, len(refs), entry.Subject)
+				}
+			}
+		}
+
+		if err := exporter.Finish(failed == 0); err != nil {
+			return err
+		}
+
+		if output.JSONOutput {
+			output.PrintJSON(exporter.manifest)
+			return nil
+		}
+
+		fmt.Printf("\nSummary: %d exported, %d already exported, %d failed\n", exported, skipped, failed)
+		output.PrintInfo("Manifest: %s", exporter.manifestPath)
+		if failed > 0 {
+			output.PrintWarning("Re-run the same command to retry failed messages")
+		}
+		return nil
+	},
+}
+
+// mailExporter writes messages to .eml files or an mbox file and keeps the
+// export manifest up to date after every message
+type mailExporter struct {
+	format       string
+	outPath      string
+	perAccount   bool
+	manifestPath string
+	manifest     *mailbox.Manifest
+	done         map[string]bool
+	mboxFile     *os.File
+	mbox         *mailbox.MboxWriter
+	// mboxErr is set when a partial message could not be removed from the
+	// mbox file, after which nothing more is written to it
+	mboxErr error
+}
+
+// newMailExporter prepares the output location, resuming from an existing
+// manifest when there is one. An mbox file without a manifest is only
+// overwritten when force is set.
+func newMailExporter(format, outPath, query string, perAccount, force bool) (*mailExporter, error) {
+	e := &mailExporter{
+		format:     format,
+		outPath:    outPath,
+		perAccount: perAccount,
+	}
+
+	if format == "eml" {
+		if err := os.MkdirAll(outPath, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+		e.manifestPath = filepath.Join(outPath, "manifest.json")
+	} else {
+		if dir := filepath.Dir(outPath); dir != "." {
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				return nil, fmt.Errorf("failed to create output directory: %w", err)
+			}
+		}
+		e.manifestPath = outPath + ".manifest.json"
+	}
+
+	manifest, err := mailbox.LoadManifest(e.manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	resumed := manifest != nil
+	if manifest == nil {
+		manifest = &mailbox.Manifest{
+			Format:    format,
+			Query:     query,
+			CreatedAt: time.Now(),
+		}
+	} else if manifest.Format != format {
+		return nil, fmt.Errorf("existing export at %s uses format '%s'", outPath, manifest.Format)
+	}
+	e.manifest = manifest
+	e.done = manifest.Exported()
+
+	if format == "mbox" {
+		if !resumed && !force {
+			if info, err := os.Stat(outPath); err == nil && info.Size() > 0 {
+				return nil, fmt.Errorf("%s already exists and is not a gcli export (use --force to overwrite it)", outPath)
+			}
+		}
+
+		f, err := os.OpenFile(outPath, os.O_CREATE|os.O_RDWR, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open mbox file: %w", err)
+		}
+		// Drop anything written after the last message recorded in the
+		// manifest, such as a partial message from an interrupted run. A new
+		// export starts from an empty file.
+		if err := f.Truncate(manifest.Bytes); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to prepare mbox file: %w", err)
+		}
+		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to prepare mbox file: %w", err)
+		}
+		e.mboxFile = f
+		e.mbox = mailbox.NewMboxWriter(f)
+	}
+
+	return e, nil
+}
+
+// Done reports whether a message was already exported
+func (e *mailExporter) Done(account, id string) bool {
+	return e.done[account+"/"+id]
+}
+
+// Export downloads a message, writes it out and records it in the manifest
+func (e *mailExporter) Export(ctx context.Context, client *gmail.Client, account, id string) (mailbox.ManifestEntry, error) {
+	msg, err := client.GetRawMessage(ctx, id)
+	if err != nil {
+		return mailbox.ManifestEntry{}, err
+	}
+
+	header, err := mailbox.ParseHeader(msg.Raw)
+	if err != nil {
+		return mailbox.ManifestEntry{}, err
+	}
+	date := header.Date
+	if date.IsZero() {
+		date = msg.InternalDate
+	}
+
+	sum := sha256.Sum256(msg.Raw)
+	entry := mailbox.ManifestEntry{
+		Account:   account,
+		ID:        msg.ID,
+		ThreadID:  msg.ThreadID,
+		MessageID: header.MessageID,
+		From:      header.From,
+		Subject:   header.Subject,
+		Date:      date,
+		Size:      len(msg.Raw),
+		SHA256:    hex.EncodeToString(sum[:]),
+	}
+
+	if e.format == "eml" {
+		dir := e.outPath
+		if e.perAccount {
+			dir = filepath.Join(dir, account)
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				return mailbox.ManifestEntry{}, fmt.Errorf("failed to create output directory: %w", err)
+			}
+		}
+		path := filepath.Join(dir, mailbox.EMLFileName(date, header.Subject, msg.ID))
+		if err := os.WriteFile(path, msg.Raw, 0600); err != nil {
+			return mailbox.ManifestEntry{}, fmt.Errorf("failed to write message: %w", err)
+		}
+		entry.File, _ = filepath.Rel(e.outPath, path)
+	} else {
+		if e.mboxErr != nil {
+			return mailbox.ManifestEntry{}, e.mboxErr
+		}
+		n, err := e.mbox.WriteMessage(header.From, date, msg.Raw)
+		if err != nil {
+			// Remove the partial message so the file still ends at the last
+			// message in the manifest
+			if rewindErr := e.rewindMbox(); rewindErr != nil {
+				e.mboxErr = rewindErr
+				return mailbox.ManifestEntry{}, errors.Join(err, rewindErr)
+			}
+			return mailbox.ManifestEntry{}, err
+		}
+		e.manifest.Bytes += n
+		entry.File = filepath.Base(e.outPath)
+	}
+
+	e.manifest.Messages = append(e.manifest.Messages, entry)
+	e.done[account+"/"+msg.ID] = true
+	if err := e.manifest.Save(e.manifestPath); err != nil {
+		return mailbox.ManifestEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// rewindMbox truncates the mbox file to the end of the last message recorded
+// in the manifest and continues writing from there
+func (e *mailExporter) rewindMbox() error {
+	if err := e.mboxFile.Truncate(e.manifest.Bytes); err != nil {
+		return fmt.Errorf("failed to remove partial message from mbox file: %w", err)
+	}
+	if _, err := e.mboxFile.Seek(e.manifest.Bytes, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to remove partial message from mbox file: %w", err)
+	}
+	return nil
+}
+
+// Finish records whether the export completed without failures
+func (e *mailExporter) Finish(complete bool) error {
+	e.manifest.Complete = complete
+	return e.manifest.Save(e.manifestPath)
+}
+
+// Close closes the mbox file, if any
+func (e *mailExporter) Close() {
+	if e.mboxFile != nil {
+		e.mboxFile.Close()
+	}
+}
+
+func init() {
+	mailCmd.AddCommand(mailExportCmd)
+
+	addAccountFlag(mailExportCmd)
+	mailExportCmd.Flags().Bool("all", false, "Export from all accounts")
+	mailExportCmd.Flags().StringP("query", "q", "", "Gmail search query selecting messages to export")
+	mailExportCmd.Flags().Int64P("limit", "n", 0, "Maximum number of messages per account (0 for no limit)")
+	mailExportCmd.Flags().String("format", "eml", "Output format: eml or mbox")
+	mailExportCmd.Flags().StringP("output", "o", "", "Output directory (eml) or file (mbox)")
+	mailExportCmd.Flags().Bool("force", false, "Overwrite an existing mbox file that has no export manifest")
+}
@@ -0,0 +1,540 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// filterFile is the import/export format for a filter set
+type filterFile struct {
+	Filters []output.Filter `json:"filters" yaml:"filters"`
+}
+
+// filterChange is a planned change from 'mail filters import'
+type filterChange struct {
+	Account string        `json:"account"`
+	Change  string        `json:"change"`
+	Filter  output.Filter `json:"filter"`
+	Error   string        `json:"error,omitempty"`
+}
+
+var mailFiltersCmd = &cobra.Command{
+	Use:   "filters",
+	Short: "Manage Gmail filters",
+	Long: `List, create, delete, export and import server-side Gmail filters.
+
+Filter sets can be exported to YAML or JSON, kept under version control, and
+imported into every account with --all. Labels are referred to by name and
+are created on import if needed.`,
+}
+
+var mailFiltersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List filters",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		var all []output.Filter
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			filters, err := client.ListFilters(ctx)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+			all = append(all, filters...)
+		}
+
+		output.PrintFilters(all)
+		return nil
+	},
+}
+
+var mailFiltersCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a filter",
+	Long: `Create a filter from criteria and actions given as flags.
+
+Examples:
+  gcli mail filters create --from newsletter@example.com --add-label Newsletters --archive
+  gcli mail filters create --query "invoice" --has-attachment --add-label Finance --all
+  gcli mail filters create --larger 10M --add-label Large`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+
+		filter, err := filterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			id, err := client.CreateFilter(ctx, filter)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+			output.PrintSuccess("[%s] Filter created (ID: %s)", name, id)
+		}
+		return nil
+	},
+}
+
+var mailFiltersDeleteCmd = &cobra.Command{
+	Use:   "delete <filter-id>",
+	Short: "Delete a filter",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		name, _, err := cfg.GetAccount(accountName)
+		if err != nil {
+			return err
+		}
+
+		client, err := newGmailClient(ctx, cfg, name)
+		if err != nil {
+			return err
+		}
+
+		if err := client.DeleteFilter(ctx, args[0]); err != nil {
+			return err
+		}
+
+		output.PrintSuccess("Filter deleted")
+		return nil
+	},
+}
+
+var mailFiltersExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export filters to YAML or JSON",
+	Long: `Export an account's filters to a file, or stdout if no file is given.
+
+The format is taken from --format, or from the file extension (.json for
+JSON, anything else for YAML).
+
+Examples:
+  gcli mail filters export -o filters.yaml
+  gcli mail filters export -a work --format json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		outPath, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		name, _, err := cfg.GetAccount(accountName)
+		if err != nil {
+			return err
+		}
+
+		client, err := newGmailClient(ctx, cfg, name)
+		if err != nil {
+			return err
+		}
+
+		filters, err := client.ListFilters(ctx)
+		if err != nil {
+			return err
+		}
+
+		file := filterFile{Filters: []output.Filter{}}
+		for _, f := range filters {
+			f.ID = ""
+			f.Account = ""
+			file.Filters = append(file.Filters, f)
+		}
+
+		data, err := encodeFilterFile(file, filterFileFormat(format, outPath))
+		if err != nil {
+			return err
+		}
+
+		if outPath == "" {
+			os.Stdout.Write(data)
+			return nil
+		}
+		if err := os.WriteFile(outPath, data, 0644); err != nil {
+			return fmt.Errorf("failed to write filters: %w", err)
+		}
+		output.PrintSuccess("Exported %d filters to %s", len(file.Filters), outPath)
+		return nil
+	},
+}
+
+var mailFiltersImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import filters from YAML or JSON",
+	Long: `Create the filters in a file that an account does not have yet.
+
+Filters are matched on their criteria and actions. Gmail filters cannot be
+edited, so a changed filter shows up as one to create plus, with --prune,
+one to delete. Use --diff to show the changes without applying them.
+
+Examples:
+  gcli mail filters import filters.yaml --all --diff
+  gcli mail filters import filters.yaml --all --prune`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+		diffOnly, _ := cmd.Flags().GetBool("diff")
+		prune, _ := cmd.Flags().GetBool("prune")
+		format, _ := cmd.Flags().GetString("format")
+
+		var r io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open filters: %w", err)
+			}
+			defer f.Close()
+			r = f
+		}
+
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("failed to read filters: %w", err)
+		}
+
+		desired, err := decodeFilterFile(data, filterFileFormat(format, args[0]))
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		var changes []filterChange
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			current, err := client.ListFilters(ctx)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			missing, extra := gmail.DiffFilters(current, desired.Filters)
+			var planned []filterChange
+			for _, f := range missing {
+				f.Account = name
+				planned = append(planned, filterChange{Account: name, Change: "create", Filter: f})
+			}
+			if prune {
+				for _, f := range extra {
+					planned = append(planned, filterChange{Account: name, Change: "delete", Filter: f})
+				}
+			}
+
+			if !output.JSONOutput {
+				if len(planned) == 0 {
+					output.PrintInfo("[%s] Filters are up to date", name)
+				} else {
+					fmt.Printf("[%s]\n", name)
+				}
+			}
+
+			for _, c := range planned {
+				if !diffOnly {
+					if c.Change == "create" {
+						c.Filter.ID, err = client.CreateFilter(ctx, c.Filter)
+					} else {
+						err = client.DeleteFilter(ctx, c.Filter.ID)
+					}
+					if err != nil {
+						c.Error = err.Error()
+					}
+				}
+				changes = append(changes, c)
+
+				if !output.JSONOutput {
+					printFilterChange(c)
+				}
+			}
+
+			if !output.JSONOutput && !prune && len(extra) > 0 {
+				output.PrintInfo("[%s] %d filter(s) not in the file were kept (use --prune to delete)", name, len(extra))
+			}
+		}
+
+		if output.JSONOutput {
+			if changes == nil {
+				changes = []filterChange{}
+			}
+			output.PrintJSON(changes)
+		}
+		return nil
+	},
+}
+
+// printFilterChange prints a planned or applied filter change in diff style
+func printFilterChange(c filterChange) {
+	sign := "+"
+	if c.Change == "delete" {
+		sign = "-"
+	}
+	line := fmt.Sprintf("  %s %s  ⇒  %s", sign, c.Filter.Criteria, c.Filter.Action)
+	if c.Error != "" {
+		line += "  (failed: " + c.Error + ")"
+	}
+	fmt.Println(line)
+}
+
+// filterFromFlags builds a filter from the create command's flags
+func filterFromFlags(cmd *cobra.Command) (output.Filter, error) {
+	var f output.Filter
+	f.Criteria.From, _ = cmd.Flags().GetString("from")
+	f.Criteria.To, _ = cmd.Flags().GetString("to")
+	f.Criteria.Subject, _ = cmd.Flags().GetString("subject")
+	f.Criteria.Query, _ = cmd.Flags().GetString("query")
+	f.Criteria.NegatedQuery, _ = cmd.Flags().GetString("negated-query")
+	f.Criteria.HasAttachment, _ = cmd.Flags().GetBool("has-attachment")
+	larger, _ := cmd.Flags().GetString("larger")
+	smaller, _ := cmd.Flags().GetString("smaller")
+
+	if larger != "" && smaller != "" {
+		return f, fmt.Errorf("--larger and --smaller cannot be used together")
+	}
+	if larger != "" || smaller != "" {
+		f.Criteria.SizeComparison = "larger"
+		sizeStr := larger
+		if smaller != "" {
+			f.Criteria.SizeComparison = "smaller"
+			sizeStr = smaller
+		}
+		size, err := parseSize(sizeStr)
+		if err != nil {
+			return f, err
+		}
+		f.Criteria.Size = size
+	}
+
+	f.Action.AddLabels, _ = cmd.Flags().GetStringSlice("add-label")
+	f.Action.RemoveLabels, _ = cmd.Flags().GetStringSlice("remove-label")
+	f.Action.Forward, _ = cmd.Flags().GetString("forward")
+
+	flagLabels := []struct {
+		flag   string
+		label  string
+		remove bool
+	}{
+		{"archive", "INBOX", true},
+		{"mark-read", "UNREAD", true},
+		{"star", "STARRED", false},
+		{"important", "IMPORTANT", false},
+		{"never-important", "IMPORTANT", true},
+		{"trash", "TRASH", false},
+		{"never-spam", "SPAM", true},
+	}
+	for _, fl := range flagLabels {
+		if set, _ := cmd.Flags().GetBool(fl.flag); set {
+			if fl.remove {
+				f.Action.RemoveLabels = append(f.Action.RemoveLabels, fl.label)
+			} else {
+				f.Action.AddLabels = append(f.Action.AddLabels, fl.label)
+			}
+		}
+	}
+
+	if f.Criteria == (output.FilterCriteria{}) {
+		return f, fmt.Errorf("at least one criterion is required (--from, --to, --subject, --query, ...)")
+	}
+	if len(f.Action.AddLabels) == 0 && len(f.Action.RemoveLabels) == 0 && f.Action.Forward == "" {
+		return f, fmt.Errorf("at least one action is required (--add-label, --archive, --forward, ...)")
+	}
+	return f, nil
+}
+
+// parseSize parses a size in bytes with an optional K, M or G suffix
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimSuffix(s, "B")
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(s, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(s, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		s = s[:len(s)-1]
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size '%s' (use e.g. 500K or 10M)", s)
+	}
+	return n * multiplier, nil
+}
+
+// filterFileFormat picks the file format from the flag or file extension
+func filterFileFormat(format, path string) string {
+	if format != "" {
+		return strings.ToLower(format)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return "json"
+	}
+	return "yaml"
+}
+
+// encodeFilterFile encodes a filter set as YAML or JSON
+func encodeFilterFile(file filterFile, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(file, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode filters: %w", err)
+		}
+		return append(data, '\n'), nil
+	case "yaml", "yml":
+		data, err := yaml.Marshal(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode filters: %w", err)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("unknown format '%s' (use yaml or json)", format)
+}
+
+// decodeFilterFile decodes a filter set from YAML or JSON
+func decodeFilterFile(data []byte, format string) (filterFile, error) {
+	var file filterFile
+	switch format {
+	case "json":
+		if err := json.Unmarshal(data, &file); err != nil {
+			return file, fmt.Errorf("failed to parse filters: %w", err)
+		}
+	case "yaml", "yml":
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return file, fmt.Errorf("failed to parse filters: %w", err)
+		}
+	default:
+		return file, fmt.Errorf("unknown format '%s' (use yaml or json)", format)
+	}
+
+	for i, f := range file.Filters {
+		if f.Criteria == (output.FilterCriteria{}) {
+			return file, fmt.Errorf("filter %d has no criteria", i+1)
+		}
+		file.Filters[i].ID = ""
+	}
+	return file, nil
+}
+
+func init() {
+	mailCmd.AddCommand(mailFiltersCmd)
+	mailFiltersCmd.AddCommand(mailFiltersListCmd)
+	mailFiltersCmd.AddCommand(mailFiltersCreateCmd)
+	mailFiltersCmd.AddCommand(mailFiltersDeleteCmd)
+	mailFiltersCmd.AddCommand(mailFiltersExportCmd)
+	mailFiltersCmd.AddCommand(mailFiltersImportCmd)
+
+	addAccountFlag(mailFiltersListCmd)
+	mailFiltersListCmd.Flags().Bool("all", false, "List filters for all accounts")
+
+	addAccountFlag(mailFiltersCreateCmd)
+	mailFiltersCreateCmd.Flags().Bool("all", false, "Create the filter in all accounts")
+	mailFiltersCreateCmd.Flags().String("from", "", "Match sender")
+	mailFiltersCreateCmd.Flags().String("to", "", "Match recipient")
+	mailFiltersCreateCmd.Flags().String("subject", "", "Match subject")
+	mailFiltersCreateCmd.Flags().StringP("query", "q", "", "Match a Gmail search query")
+	mailFiltersCreateCmd.Flags().String("negated-query", "", "Exclude messages matching a Gmail search query")
+	mailFiltersCreateCmd.Flags().Bool("has-attachment", false, "Match messages with attachments")
+	mailFiltersCreateCmd.Flags().String("larger", "", "Match messages larger than a size (e.g. 10M)")
+	mailFiltersCreateCmd.Flags().String("smaller", "", "Match messages smaller than a size (e.g. 500K)")
+	mailFiltersCreateCmd.Flags().StringSlice("add-label", nil, "Label to apply (created if needed, repeatable)")
+	mailFiltersCreateCmd.Flags().StringSlice("remove-label", nil, "Label to remove (repeatable)")
+	mailFiltersCreateCmd.Flags().Bool("archive", false, "Skip the inbox")
+	mailFiltersCreateCmd.Flags().Bool("mark-read", false, "Mark as read")
+	mailFiltersCreateCmd.Flags().Bool("star", false, "Star the message")
+	mailFiltersCreateCmd.Flags().Bool("important", false, "Always mark as important")
+	mailFiltersCreateCmd.Flags().Bool("never-important", false, "Never mark as important")
+	mailFiltersCreateCmd.Flags().Bool("trash", false, "Delete the message")
+	mailFiltersCreateCmd.Flags().Bool("never-spam", false, "Never send to spam")
+	mailFiltersCreateCmd.Flags().String("forward", "", "Forward to this (verified) address")
+
+	addAccountFlag(mailFiltersDeleteCmd)
+
+	addAccountFlag(mailFiltersExportCmd)
+	mailFiltersExportCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+	mailFiltersExportCmd.Flags().String("format", "", "Output format: yaml or json (default: from file extension, else yaml)")
+
+	addAccountFlag(mailFiltersImportCmd)
+	mailFiltersImportCmd.Flags().Bool("all", false, "Import into all accounts")
+	mailFiltersImportCmd.Flags().Bool("diff", false, "Show changes without applying them")
+	mailFiltersImportCmd.Flags().Bool("prune", false, "Delete filters that are not in the file")
+	mailFiltersImportCmd.Flags().String("format", "", "Input format: yaml or json (default: from file extension, else yaml)")
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/calendar"
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var mailFollowupsCmd = &cobra.Command{
+	Use:   "followups",
+	Short: "Check sent emails awaiting replies",
+	Long: `Check emails sent with 'mail send-now --remind-if-no-reply' for replies.
+
+Each tracked thread is checked for a newer message from someone else.
+Auto-replies such as out-of-office notices and bounces do not count. Answered emails are reported once and then no longer tracked, and their
+calendar reminders are removed. Unanswered emails past their deadline are
+shown as overdue; with --reinbox their threads are also moved back to the
+inbox as unread, once, so they show up with the rest of your mail.
+
+Run it from cron with --reinbox to be reminded in Gmail itself.
+
+Examples:
+  gcli mail followups
+  gcli mail followups --reinbox`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		reinbox, _ := cmd.Flags().GetBool("reinbox")
+
+		followups, err := gmail.LoadFollowups()
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		clients := make(map[string]*gmail.Client)
+		now := time.Now()
+
+		var remaining []gmail.FollowupData
+		var results []output.Followup
+		for _, f := range followups {
+			if accountName != "" && f.Account != accountName {
+				remaining = append(remaining, f)
+				continue
+			}
+
+			client, ok := clients[f.Account]
+			if !ok {
+				client, err = newGmailClient(ctx, cfg, f.Account)
+				if err != nil {
+					output.PrintError("[%s] %v", f.Account, err)
+					remaining = append(remaining, f)
+					continue
+				}
+				clients[f.Account] = client
+			}
+
+			replied, err := client.ThreadHasReply(ctx, f.ThreadID, f.SentAt)
+			if err != nil {
+				output.PrintError("[%s] %s: %v", f.Account, f.Subject, err)
+				remaining = append(remaining, f)
+				continue
+			}
+
+			status := "waiting"
+			switch {
+			case replied:
+				status = "replied"
+				if f.EventID != "" {
+					if err := deleteFollowupReminder(ctx, cfg, f); err != nil {
+						output.PrintWarning("[%s] %s: %v", f.Account, f.Subject, err)
+					}
+				}
+			case now.After(f.Deadline):
+				status = "overdue"
+				if reinbox && !f.Reinboxed {
+					if err := client.ModifyThread(ctx, f.ThreadID, []string{"INBOX", "UNREAD"}, nil); err != nil {
+						output.PrintError("[%s] %s: %v", f.Account, f.Subject, err)
+					} else {
+						f.Reinboxed = true
+					}
+				}
+			}
+
+			if !replied {
+				remaining = append(remaining, f)
+			}
+			results = append(results, output.Followup{
+				ID:        f.ID,
+				Account:   f.Account,
+				To:        f.To,
+				Subject:   f.Subject,
+				SentAt:    f.SentAt,
+				Deadline:  f.Deadline,
+				Status:    status,
+				Reinboxed: f.Reinboxed,
+			})
+		}
+
+		if err := gmail.SaveFollowups(remaining); err != nil {
+			return err
+		}
+
+		output.PrintFollowups(results)
+		return nil
+	},
+}
+
+var mailFollowupsCancelCmd = &cobra.Command{
+	Use:   "cancel <id>...",
+	Short: "Stop waiting for replies to emails",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		followups, err := gmail.LoadFollowups()
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		cancel := make(map[string]bool, len(args))
+		for _, id := range args {
+			cancel[id] = true
+		}
+
+		var remaining []gmail.FollowupData
+		for _, f := range followups {
+			if !cancel[f.ID] {
+				remaining = append(remaining, f)
+				continue
+			}
+			delete(cancel, f.ID)
+
+			if f.EventID != "" {
+				if err := deleteFollowupReminder(ctx, cfg, f); err != nil {
+					output.PrintWarning("[%s] %s: %v", f.Account, f.Subject, err)
+				}
+			}
+			output.PrintSuccess("No longer waiting for a reply to: %s", f.Subject)
+		}
+		for id := range cancel {
+			output.PrintError("follow-up '%s' not found", id)
+		}
+
+		return gmail.SaveFollowups(remaining)
+	},
+}
+
+// trackFollowup records a sent email to check for replies by the deadline,
+// optionally adding a calendar reminder at the deadline
+func trackFollowup(ctx context.Context, client *gmail.Client, acc config.AccountConfig, msgID string, email gmail.DraftEmail, deadline time.Time, reminder bool) error {
+	threadID, err := client.GetThreadID(ctx, msgID)
+	if err != nil {
+		return err
+	}
+
+	followup := gmail.FollowupData{
+		Account:   client.GetAccountName(),
+		MessageID: msgID,
+		ThreadID:  threadID,
+		To:        email.To,
+		Subject:   email.Subject,
+		SentAt:    time.Now(),
+		Deadline:  deadline,
+	}
+
+	if reminder {
+		calClient, err := calendar.NewClient(ctx, followup.Account, acc)
+		if err != nil {
+			return err
+		}
+		followup.EventID, err = calClient.CreateEvent(ctx, calendar.EventInput{
+			Summary:     "Follow up: " + email.Subject,
+			Description: fmt.Sprintf("No reply yet from %s to the email sent %s.", strings.Join(email.To, ", "), followup.SentAt.Format("2006-01-02 15:04")),
+			Start:       deadline,
+			End:         deadline.Add(15 * time.Minute),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return gmail.AddFollowup(followup)
+}
+
+// deleteFollowupReminder removes a follow-up's calendar reminder
+func deleteFollowupReminder(ctx context.Context, cfg *config.Config, f gmail.FollowupData) error {
+	_, acc, err := cfg.GetAccount(f.Account)
+	if err != nil {
+		return err
+	}
+
+	client, err := calendar.NewClient(ctx, f.Account, acc)
+	if err != nil {
+		return err
+	}
+
+	return client.DeleteEvent(ctx, f.EventID)
+}
+
+func init() {
+	mailCmd.AddCommand(mailFollowupsCmd)
+	mailFollowupsCmd.AddCommand(mailFollowupsCancelCmd)
+
+	addAccountFlag(mailFollowupsCmd)
+	mailFollowupsCmd.Flags().Bool("reinbox", false, "Move overdue threads back to the inbox as unread")
+}
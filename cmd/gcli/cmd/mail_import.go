@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/mailbox"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var mailImportCmd = &cobra.Command{
+	Use:   "import <file|dir>...",
+	Short: "Import .eml files or mbox archives into Gmail",
+	Long: `Import messages from .eml files or mbox archives into a Gmail account.
+
+Messages are added with their original dates and are not run through spam
+filtering. Each imported message gets the target label (created if needed);
+use --inbox to also place messages in the inbox. Read and starred state is
+taken from mbox Status/X-Status headers and Google Takeout X-Gmail-Labels.
+
+Messages whose Message-ID already exists in the account, or that appear more
+than once in the input, are skipped.
+
+Directories are searched recursively for .eml and .mbox files. Other files
+are treated as mbox if they start with a "From " line, and as a single
+message otherwise.
+
+Examples:
+  gcli mail import old-mail.mbox --label "Archive/2019"
+  gcli mail import ./exported-eml -a work --label Imported --inbox`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		labelName, _ := cmd.Flags().GetString("label")
+		inbox, _ := cmd.Flags().GetBool("inbox")
+
+		files, err := collectImportFiles(args)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no .eml or .mbox files found")
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		name, _, err := cfg.GetAccount(accountName)
+		if err != nil {
+			return err
+		}
+
+		client, err := newGmailClient(ctx, cfg, name)
+		if err != nil {
+			return err
+		}
+
+		var labelIDs []string
+		if labelName != "" {
+			labelID, err := client.EnsureLabel(ctx, labelName)
+			if err != nil {
+				return err
+			}
+			labelIDs = append(labelIDs, labelID)
+		}
+		if inbox {
+			labelIDs = append(labelIDs, "INBOX")
+		}
+
+		imp := &mailImporter{
+			client:   client,
+			labelIDs: labelIDs,
+			seen:     make(map[string]bool),
+		}
+
+		for _, path := range files {
+			if err := imp.ImportFile(ctx, path); err != nil {
+				output.PrintError("%s: %v", path, err)
+			}
+		}
+
+		if output.JSONOutput {
+			output.PrintJSON(imp.results)
+			return nil
+		}
+
+		var imported, skipped, failed int
+		for _, r := range imp.results {
+			switch r.Status {
+			case "imported":
+				imported++
+			case "skipped":
+				skipped++
+			default:
+				failed++
+			}
+		}
+		fmt.Printf("\nSummary: %d imported, %d skipped as duplicates, %d failed\n", imported, skipped, failed)
+		return nil
+	},
+}
+
+// mailImporter imports messages into one account, skipping duplicates
+type mailImporter struct {
+	client   *gmail.Client
+	labelIDs []string
+	seen     map[string]bool
+	results  []output.ImportResult
+}
+
+// ImportFile imports every message in an .eml or mbox file
+func (m *mailImporter) ImportFile(ctx context.Context, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	if !isMbox(path, r) {
+		raw, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		m.importMessage(ctx, path, raw)
+		return nil
+	}
+
+	reader := mailbox.NewMboxReader(r)
+	for n := 1; ; n++ {
+		msg, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		m.importMessage(ctx, fmt.Sprintf("%s#%d", path, n), msg.Raw)
+	}
+}
+
+// importMessage imports a single message and records the result
+func (m *mailImporter) importMessage(ctx context.Context, source string, raw []byte) {
+	result := output.ImportResult{Source: source}
+	defer func() {
+		m.results = append(m.results, result)
+		if !output.JSONOutput {
+			printImportResult(result)
+		}
+	}()
+
+	header, err := mailbox.ParseHeader(raw)
+	if err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+		return
+	}
+	result.MessageID = header.MessageID
+	result.Subject = header.Subject
+
+	if header.MessageID != "" {
+		if m.seen[header.MessageID] {
+			result.Status = "skipped"
+			return
+		}
+		exists, err := m.client.HasMessageID(ctx, header.MessageID)
+		if err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+			return
+		}
+		if exists {
+			m.seen[header.MessageID] = true
+			result.Status = "skipped"
+			return
+		}
+	}
+
+	labelIDs := append([]string(nil), m.labelIDs...)
+	if header.Unread {
+		labelIDs = append(labelIDs, "UNREAD")
+	}
+	if header.Starred {
+		labelIDs = append(labelIDs, "STARRED")
+	}
+
+	id, err := m.client.ImportMessage(ctx, raw, labelIDs)
+	if err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+		return
+	}
+
+	if header.MessageID != "" {
+		m.seen[header.MessageID] = true
+	}
+	result.Status = "imported"
+	result.ID = id
+}
+
+// printImportResult prints a one-line progress report for a message
+func printImportResult(r output.ImportResult) {
+	subject := r.Subject
+	if subject == "" {
+		subject = "(no subject)"
+	}
+
+	switch r.Status {
+	case "imported":
+		output.PrintSuccess("%s: %s (ID: %s)", r.Source, subject, r.ID)
+	case "skipped":
+		output.PrintInfo("%s: %s (already in mailbox)", r.Source, subject)
+	default:
+		output.PrintError("%s: %s", r.Source, r.Error)
+	}
+}
+
+// collectImportFiles expands directories into the .eml and .mbox files
+// they contain
+func collectImportFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		var found []string
+		err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			ext := strings.ToLower(filepath.Ext(p))
+			if !d.IsDir() && (ext == ".eml" || ext == ".mbox") {
+				found = append(found, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// isMbox reports whether a file holds an mbox archive rather than a single
+// message
+func isMbox(path string, r *bufio.Reader) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".eml":
+		return false
+	case ".mbox":
+		return true
+	}
+	prefix, _ := r.Peek(5)
+	return string(prefix) == "From "
+}
+
+func init() {
+	mailCmd.AddCommand(mailImportCmd)
+
+	addAccountFlag(mailImportCmd)
+	mailImportCmd.Flags().StringP("label", "l", "Imported", "Label to apply to imported messages (empty for none)")
+	mailImportCmd.Flags().Bool("inbox", false, "Also place imported messages in the inbox")
+}
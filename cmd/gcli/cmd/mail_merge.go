@@ -0,0 +1,380 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/markdown"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/alexandraswan/gcli/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+var mailMergeCmd = &cobra.Command{
+	Use:   "merge",
+	Short: "Send personalized emails from a template and a CSV file",
+	Long: `Render a template once per row of a CSV file and send the results.
+
+The CSV file's header row names the template variables; each following row
+holds one recipient's values. Variables given with --var apply to every row
+unless a column of the same name overrides them. Recipients come from the
+template's To header when it has one, otherwise from the "email" column.
+
+Messages are sent --delay apart to stay within Gmail's sending limits. Use
+--draft to create drafts for review instead of sending, and --dry-run to
+preview the rendered messages without contacting Gmail (signatures are not
+included in the preview).
+
+With --results a copy of the CSV file is written with status, id and error
+columns added, so failed rows can be picked out and retried.
+
+Examples:
+  gcli mail merge --template welcome --csv new-hires.csv --var team=Platform --dry-run
+  gcli mail merge --template report --csv clients.csv --draft
+  gcli mail merge --template welcome --csv new-hires.csv --delay 5s --results sent.csv`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountName, _ := cmd.Flags().GetString("account")
+		templateName, _ := cmd.Flags().GetString("template")
+		csvPath, _ := cmd.Flags().GetString("csv")
+		vars, _ := cmd.Flags().GetStringArray("var")
+		theme, _ := cmd.Flags().GetString("theme")
+		from, _ := cmd.Flags().GetString("from")
+		noSignature, _ := cmd.Flags().GetBool("no-signature")
+		asDraft, _ := cmd.Flags().GetBool("draft")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		delay, _ := cmd.Flags().GetDuration("delay")
+		resultsPath, _ := cmd.Flags().GetString("results")
+
+		if templateName == "" {
+			return fmt.Errorf("template is required (--template)")
+		}
+		if csvPath == "" {
+			return fmt.Errorf("CSV file is required (--csv)")
+		}
+
+		tmpl, err := templates.Load(templateName)
+		if err != nil {
+			return err
+		}
+		if theme != "" && tmpl.Format != templates.FormatMarkdown {
+			return fmt.Errorf("--theme requires a markdown template")
+		}
+
+		common, err := parseVars(vars)
+		if err != nil {
+			return err
+		}
+
+		header, rows, err := readMergeCSV(csvPath)
+		if err != nil {
+			return err
+		}
+		if _, ok := tmpl.Headers["to"]; !ok && columnIndex(header, "email") < 0 {
+			return fmt.Errorf("%s has no \"email\" column and the template has no To header", csvPath)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		merger := &mailMerger{
+			template: tmpl,
+			header:   header,
+			common:   common,
+			theme:    theme,
+			draft:    asDraft,
+			dryRun:   dryRun,
+		}
+
+		if !dryRun {
+			cfg, err := config.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			name, _, err := cfg.GetAccount(accountName)
+			if err != nil {
+				return err
+			}
+
+			merger.client, err = newGmailClient(ctx, cfg, name)
+			if err != nil {
+				return err
+			}
+
+			if from != "" || !noSignature {
+				alias, err := merger.client.ResolveSendAs(ctx, from)
+				if err != nil {
+					return err
+				}
+				merger.alias = &alias
+				merger.signature = !noSignature
+			}
+		}
+
+		for i, row := range rows {
+			if ctx.Err() != nil {
+				break
+			}
+			if i > 0 && !dryRun && delay > 0 {
+				select {
+				case <-ctx.Done():
+				case <-time.After(delay):
+				}
+				if ctx.Err() != nil {
+					break
+				}
+			}
+			merger.mergeRow(ctx, i+1, row)
+		}
+
+		if resultsPath != "" {
+			if err := writeMergeResults(resultsPath, header, rows, merger.results); err != nil {
+				return err
+			}
+		}
+
+		if output.JSONOutput {
+			output.PrintJSON(merger.results)
+			return nil
+		}
+
+		var done, failed int
+		for _, r := range merger.results {
+			if r.Status == "failed" {
+				failed++
+			} else {
+				done++
+			}
+		}
+		verb := "sent"
+		switch {
+		case dryRun:
+			verb = "previewed"
+		case asDraft:
+			verb = "drafted"
+		}
+		fmt.Printf("\nSummary: %d %s, %d failed", done, verb, failed)
+		if skipped := len(rows) - len(merger.results); skipped > 0 {
+			fmt.Printf(", %d not processed (interrupted)", skipped)
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+// mailMerger renders and sends one message per CSV row
+type mailMerger struct {
+	client    *gmail.Client
+	template  *templates.Template
+	header    []string
+	common    map[string]string
+	theme     string
+	alias     *output.SendAs
+	signature bool
+	draft     bool
+	dryRun    bool
+	results   []output.MergeResult
+}
+
+// mergeRow renders, then sends, drafts or previews a row's message and
+// records the result
+func (m *mailMerger) mergeRow(ctx context.Context, n int, row []string) {
+	result := output.MergeResult{Row: n}
+	defer func() {
+		m.results = append(m.results, result)
+		if !output.JSONOutput {
+			printMergeResult(result)
+		}
+	}()
+
+	email, err := m.render(row)
+	result.To = email.To
+	result.Subject = email.Subject
+	if err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+		return
+	}
+
+	if m.dryRun {
+		result.Status = "preview"
+		result.Body = email.Body
+		return
+	}
+
+	if m.alias != nil {
+		email.SetSender(*m.alias, m.signature)
+	}
+
+	if m.draft {
+		result.ID, err = m.client.CreateDraft(ctx, email)
+		result.Status = "drafted"
+	} else {
+		result.ID, err = m.client.SendEmail(ctx, email)
+		result.Status = "sent"
+	}
+	if err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+	}
+}
+
+// render builds the email for a row
+func (m *mailMerger) render(row []string) (gmail.DraftEmail, error) {
+	vars := make(map[string]string, len(m.common)+len(m.header))
+	for k, v := range m.common {
+		vars[k] = v
+	}
+	for i, col := range m.header {
+		if i < len(row) {
+			vars[col] = row[i]
+		}
+	}
+
+	msg, err := m.template.Render(vars)
+	if err != nil {
+		return gmail.DraftEmail{}, err
+	}
+
+	to := msg.To
+	if len(to) == 0 {
+		if i := columnIndex(m.header, "email"); i >= 0 && i < len(row) && strings.TrimSpace(row[i]) != "" {
+			to = []string{strings.TrimSpace(row[i])}
+		}
+	}
+
+	email := gmail.DraftEmail{
+		To:      to,
+		CC:      msg.CC,
+		BCC:     msg.BCC,
+		Subject: msg.Subject,
+		Body:    msg.Body,
+		IsHTML:  msg.Format == templates.FormatHTML,
+	}
+	if len(email.To) == 0 {
+		return email, fmt.Errorf("no recipient")
+	}
+	if email.Subject == "" {
+		return email, fmt.Errorf("template rendered an empty subject")
+	}
+
+	if msg.Format == templates.FormatMarkdown {
+		if err := renderMarkdownBody(&email, m.theme, filepath.Dir(m.template.Path)); err != nil {
+			return email, err
+		}
+	}
+	return email, nil
+}
+
+// printMergeResult prints the outcome of one row
+func printMergeResult(r output.MergeResult) {
+	to := strings.Join(r.To, ", ")
+	switch r.Status {
+	case "sent":
+		output.PrintSuccess("Row %d: sent to %s (Message ID: %s)", r.Row, to, r.ID)
+	case "drafted":
+		output.PrintSuccess("Row %d: draft for %s created (ID: %s)", r.Row, to, r.ID)
+	case "preview":
+		fmt.Printf("── Row %d ──\n", r.Row)
+		fmt.Printf("To:      %s\n", to)
+		fmt.Printf("Subject: %s\n\n", r.Subject)
+		fmt.Printf("%s\n\n", strings.TrimRight(r.Body, "\n"))
+	default:
+		output.PrintError("Row %d: %s", r.Row, r.Error)
+	}
+}
+
+// readMergeCSV reads a CSV file with a header row, trimming column names
+func readMergeCSV(path string) ([]string, [][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open CSV file: %w", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%s is empty", path)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV file: %w", err)
+	}
+	for i, col := range header {
+		// Spreadsheet exports often start with a byte order mark
+		header[i] = strings.TrimSpace(strings.TrimPrefix(col, "\ufeff"))
+	}
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV file: %w", err)
+	}
+	return header, rows, nil
+}
+
+// columnIndex finds a column by case-insensitive name, or returns -1
+func columnIndex(header []string, name string) int {
+	for i, col := range header {
+		if strings.EqualFold(col, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeMergeResults writes the input rows with status, id and error columns
+func writeMergeResults(path string, header []string, rows [][]string, results []output.MergeResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create results file: %w", err)
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write(append(append([]string{}, header...), "status", "id", "error"))
+	for i, row := range rows {
+		record := make([]string, len(header), len(header)+3)
+		copy(record, row)
+		if i < len(results) {
+			r := results[i]
+			record = append(record, r.Status, r.ID, r.Error)
+		} else {
+			record = append(record, "skipped", "", "")
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write results file: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	mailCmd.AddCommand(mailMergeCmd)
+
+	addAccountFlag(mailMergeCmd)
+	mailMergeCmd.Flags().String("template", "", "Template to render (see 'mail templates list')")
+	mailMergeCmd.Flags().String("csv", "", "CSV file with a header row and one row per recipient")
+	mailMergeCmd.Flags().StringArray("var", nil, "Variable for every row as key=value (repeatable)")
+	mailMergeCmd.Flags().String("theme", "", fmt.Sprintf("Inline CSS theme for markdown templates (%s)", strings.Join(markdown.ThemeNames(), ", ")))
+	mailMergeCmd.Flags().String("from", "", "Send from this verified alias (see 'mail aliases list')")
+	mailMergeCmd.Flags().Bool("no-signature", false, "Do not append the alias's signature")
+	mailMergeCmd.Flags().Bool("draft", false, "Create drafts instead of sending")
+	mailMergeCmd.Flags().Bool("dry-run", false, "Preview the rendered messages without sending")
+	mailMergeCmd.Flags().Duration("delay", time.Second, "Time to wait between messages")
+	mailMergeCmd.Flags().String("results", "", "Write a CSV file with the outcome of each row")
+}
@@ -0,0 +1,539 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/alexandraswan/gcli/internal/rules"
+	"github.com/alexandraswan/gcli/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+var mailRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Process emails with local rules",
+	Long: `Apply rules that Gmail filters cannot express to new emails.
+
+Rules live in rules.yaml in the config directory (~/.config/google-cli), or
+the file given with --file. Each rule searches with a Gmail query (default
+in:inbox), then checks the messages found against its match conditions.
+Conditions are case-insensitive regular expressions that must all match:
+from, to (also checks Cc), subject, body, headers (name: pattern; an empty
+pattern matches a missing header) and attachment (any attachment's filename
+or content type).
+
+Matched messages get the rule's actions: label (created if missing),
+archive, mark_read, forward (with the original attachments),
+reply_template (a template from 'mail templates', rendered with from,
+from_name, from_email, subject and date; automated emails are never
+answered), save_attachments (a directory; only attachments matching the
+attachment condition when there is one) and exec (a shell command given the
+message as JSON on stdin, like 'mail watch --exec').
+
+Rules are applied in order and a message can match several; set stop to
+skip the remaining rules once a rule matches.
+
+  rules:
+    - name: invoices
+      query: "from:billing@vendor.com has:attachment"
+      match:
+        subject: "invoice|receipt"
+        attachment: '\.pdf$'
+      actions:
+        save_attachments: ~/Documents/invoices
+        forward: [accounting@example.com]
+        label: [Finance/Invoices]
+        archive: true
+      stop: true
+
+    - name: urgent
+      match:
+        headers:
+          X-Priority: "^1"
+      actions:
+        exec: notify-send "Urgent email"`,
+}
+
+var mailRulesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List rules and check the rules file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+
+		ruleSet, err := rules.Load(file)
+		if err != nil {
+			return err
+		}
+		if file == "" {
+			if file, err = rules.GetRulesPath(); err != nil {
+				return err
+			}
+		}
+
+		var infos []output.RuleInfo
+		for _, r := range ruleSet {
+			infos = append(infos, output.RuleInfo{
+				Name:       r.Name,
+				Query:      r.Query,
+				Conditions: r.Match.Describe(),
+				Actions:    r.Actions.Describe(),
+				Stop:       r.Stop,
+			})
+		}
+
+		output.PrintRules(infos, file)
+		return nil
+	},
+}
+
+var mailRulesRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Apply rules to new emails",
+	Long: `Apply rules to emails received in the last --days days.
+
+Each email is processed once: emails rules have been run on are recorded in
+rules-state.json in the config directory and skipped on later runs, whether
+or not a rule matched them. Each email is recorded before its actions run,
+so an interrupted run never repeats them, and failed actions are reported
+rather than retried. Run it from cron to process mail as it arrives.
+With --rule, only the chosen rules are recorded as run, so later runs still
+apply the other rules to those emails.
+
+--dry-run shows which rules would match each email and why, without
+applying actions or recording anything.
+
+Examples:
+  gcli mail rules run --dry-run
+  gcli mail rules run --all
+  gcli mail rules run --rule invoices --days 30`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+		file, _ := cmd.Flags().GetString("file")
+		only, _ := cmd.Flags().GetStringSlice("rule")
+		days, _ := cmd.Flags().GetInt("days")
+		limit, _ := cmd.Flags().GetInt64("limit")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		maxDays := int(rules.Retention / (24 * time.Hour))
+		if days < 1 || days > maxDays {
+			return fmt.Errorf("--days must be between 1 and %d", maxDays)
+		}
+
+		ruleSet, err := rules.Load(file)
+		if err != nil {
+			return err
+		}
+		if ruleSet, err = selectRules(ruleSet, only); err != nil {
+			return err
+		}
+
+		// Check reply templates up front rather than failing on each email
+		for _, r := range ruleSet {
+			if r.Actions.ReplyTemplate != "" {
+				if _, err := templates.Load(r.Actions.ReplyTemplate); err != nil {
+					return fmt.Errorf("rule '%s': %w", r.Name, err)
+				}
+			}
+		}
+
+		state, err := rules.LoadState()
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			runner := &ruleRunner{
+				client:  client,
+				rules:   ruleSet,
+				state:   state,
+				dryRun:  dryRun,
+				perRule: len(only) > 0,
+			}
+			if err := runner.run(ctx, days, limit); err != nil {
+				output.PrintError("[%s] %v", name, err)
+			}
+		}
+
+		return nil
+	},
+}
+
+// selectRules keeps the named rules, or all rules when no names are given
+func selectRules(ruleSet []*rules.Rule, names []string) ([]*rules.Rule, error) {
+	if len(names) == 0 {
+		return ruleSet, nil
+	}
+
+	var selected []*rules.Rule
+	for _, name := range names {
+		found := false
+		for _, r := range ruleSet {
+			if r.Name == name {
+				selected = append(selected, r)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("rule '%s' not found", name)
+		}
+	}
+	return selected, nil
+}
+
+// ruleRunner applies rules to one account's new emails
+type ruleRunner struct {
+	client *gmail.Client
+	rules  []*rules.Rule
+	state  *rules.State
+	dryRun bool
+	// perRule records each rule run on a message rather than the message as
+	// done, for runs limited to some rules
+	perRule bool
+
+	// sender is the default alias replies are sent from, looked up on the
+	// first reply
+	sender *output.SendAs
+}
+
+// run finds unprocessed emails for each rule's query and applies the rules
+// that match them
+func (r *ruleRunner) run(ctx context.Context, days int, limit int64) error {
+	account := r.client.GetAccountName()
+
+	// found records which rules' queries returned each message, since a
+	// rule only applies to messages its query finds
+	found := make(map[string]map[string]bool)
+	var ids []string
+	for _, rule := range r.rules {
+		query := fmt.Sprintf("(%s) newer_than:%dd", rule.Query, days)
+		refs, err := r.client.ListMessageIDs(ctx, query, limit)
+		if err != nil {
+			return fmt.Errorf("rule '%s': %w", rule.Name, err)
+		}
+		for _, ref := range refs {
+			if r.state.IsProcessed(account, rule.Name, ref.ID) {
+				continue
+			}
+			if found[ref.ID] == nil {
+				found[ref.ID] = make(map[string]bool)
+				ids = append(ids, ref.ID)
+			}
+			found[ref.ID][rule.Name] = true
+		}
+	}
+
+	matched := 0
+	for _, id := range ids {
+		msg, err := r.client.GetMessageContent(ctx, id)
+		if err != nil {
+			// Left unprocessed so the next run retries it
+			output.PrintError("[%s] %s: %v", account, id, err)
+			continue
+		}
+
+		// The message is recorded before any action runs, so an interrupted
+		// run never repeats actions such as forwarding. Failed actions are
+		// reported rather than retried for the same reason.
+		if !r.dryRun {
+			if err := r.markProcessed(account, id, found[id]); err != nil {
+				return err
+			}
+		}
+
+		m := ruleMessage(msg)
+		hit := false
+		for _, rule := range r.rules {
+			if !found[id][rule.Name] {
+				continue
+			}
+			ok, reasons, attachments := rule.Evaluate(m)
+			if !ok {
+				continue
+			}
+			hit = true
+
+			if r.dryRun {
+				output.PrintInfo("[%s] Rule '%s' matches \"%s\" from %s", account, rule.Name, msg.Detail.Subject, msg.Detail.From.String())
+				for _, reason := range reasons {
+					fmt.Printf("      %s\n", reason)
+				}
+				fmt.Printf("      → %s\n", strings.Join(rule.Actions.Describe(), ", "))
+			} else if err := r.apply(ctx, rule, msg, attachments); err != nil {
+				output.PrintError("[%s] Rule '%s' on \"%s\": %v", account, rule.Name, msg.Detail.Subject, err)
+			} else {
+				output.PrintSuccess("[%s] Rule '%s' applied to \"%s\": %s", account, rule.Name, msg.Detail.Subject, strings.Join(rule.Actions.Describe(), ", "))
+			}
+
+			if rule.Stop {
+				break
+			}
+		}
+		if hit {
+			matched++
+		}
+	}
+
+	if r.dryRun {
+		output.PrintInfo("[%s] %d new email(s) checked, %d would match (dry run)", account, len(ids), matched)
+	} else if len(ids) > 0 {
+		output.PrintInfo("[%s] %d new email(s) checked, %d matched", account, len(ids), matched)
+	}
+	return nil
+}
+
+// markProcessed records that the rules found a message have been run on it
+// and saves the state
+func (r *ruleRunner) markProcessed(account, id string, found map[string]bool) error {
+	if r.perRule {
+		for name := range found {
+			r.state.MarkRuleProcessed(account, name, id)
+		}
+	} else {
+		r.state.MarkProcessed(account, id)
+	}
+	return rules.SaveState(r.state)
+}
+
+// apply runs a rule's actions on a message, continuing past failed actions
+// and returning their errors together
+func (r *ruleRunner) apply(ctx context.Context, rule *rules.Rule, msg *gmail.MessageContent, matched []rules.Attachment) error {
+	account := r.client.GetAccountName()
+	actions := rule.Actions
+	var errs []error
+
+	// Attachments are downloaded at most once per message
+	downloaded := make(map[gmail.AttachmentRef][]byte)
+	download := func(ref gmail.AttachmentRef) ([]byte, error) {
+		if data, ok := downloaded[ref]; ok {
+			return data, nil
+		}
+		data, err := r.client.GetAttachment(ctx, msg.Detail.ID, ref)
+		if err == nil {
+			downloaded[ref] = data
+		}
+		return data, err
+	}
+
+	if actions.SaveAttachments != "" {
+		for _, ref := range msg.Attachments {
+			if !containsAttachment(matched, ref) {
+				continue
+			}
+			data, err := download(ref)
+			if err == nil {
+				err = saveAttachment(actions.SaveAttachments, ref.Filename, data)
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(actions.Forward) > 0 {
+		email := gmail.ForwardEmail(msg, actions.Forward)
+		var err error
+		for _, ref := range msg.Attachments {
+			var data []byte
+			if data, err = download(ref); err != nil {
+				break
+			}
+			email.Attachments = append(email.Attachments, gmail.Attachment{
+				Filename:    ref.Filename,
+				ContentType: ref.MimeType,
+				Data:        data,
+			})
+		}
+		if err == nil {
+			_, err = r.client.SendEmail(ctx, email)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("forward: %w", err))
+		}
+	}
+
+	if actions.ReplyTemplate != "" {
+		if msg.IsAutomated() {
+			output.PrintWarning("[%s] Rule '%s': not replying to automated email \"%s\"", account, rule.Name, msg.Detail.Subject)
+		} else if err := r.reply(ctx, actions.ReplyTemplate, msg); err != nil {
+			errs = append(errs, fmt.Errorf("reply: %w", err))
+		}
+	}
+
+	if actions.Exec != "" {
+		if err := runMailHook(ctx, actions.Exec, account, msg.Detail); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	var remove []string
+	if actions.Archive {
+		remove = append(remove, "INBOX")
+	}
+	if actions.MarkRead {
+		remove = append(remove, "UNREAD")
+	}
+	if len(actions.Label) > 0 || len(remove) > 0 {
+		if err := r.client.LabelMessage(ctx, msg.Detail.ID, actions.Label, remove); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// reply answers a message with a rendered template, in the same thread and
+// from the account's default address with its signature
+func (r *ruleRunner) reply(ctx context.Context, templateName string, msg *gmail.MessageContent) error {
+	tmpl, err := templates.Load(templateName)
+	if err != nil {
+		return err
+	}
+
+	from := msg.Detail.From
+	rendered, err := tmpl.Render(map[string]string{
+		"from":       from.String(),
+		"from_name":  from.Name,
+		"from_email": from.Email,
+		"subject":    msg.Detail.Subject,
+		"date":       msg.Detail.Date.Format("2006-01-02"),
+	})
+	if err != nil {
+		return err
+	}
+
+	email := gmail.ReplyEmail(msg)
+	if rendered.Subject != "" {
+		email.Subject = rendered.Subject
+	}
+	if len(rendered.To) > 0 {
+		email.To = rendered.To
+	}
+	email.CC = rendered.CC
+	email.BCC = rendered.BCC
+	email.Body = rendered.Body
+	email.IsHTML = rendered.Format == templates.FormatHTML
+	if rendered.Format == templates.FormatMarkdown {
+		if err := renderMarkdownBody(&email, "", filepath.Dir(tmpl.Path)); err != nil {
+			return err
+		}
+	}
+
+	if r.sender == nil {
+		alias, err := r.client.ResolveSendAs(ctx, "")
+		if err != nil {
+			return err
+		}
+		r.sender = &alias
+	}
+	email.SetSender(*r.sender, true)
+
+	_, err = r.client.SendEmail(ctx, email)
+	return err
+}
+
+// ruleMessage converts a message to the form rules match against
+func ruleMessage(msg *gmail.MessageContent) rules.Message {
+	d := msg.Detail
+	m := rules.Message{
+		From:    d.From.String(),
+		Subject: d.Subject,
+		Body:    d.Body,
+		Headers: msg.Headers,
+	}
+	for _, a := range append(append([]output.Address{}, d.To...), d.CC...) {
+		m.To = append(m.To, a.String())
+	}
+	for _, a := range msg.Attachments {
+		m.Attachments = append(m.Attachments, rules.Attachment{Filename: a.Filename, MimeType: a.MimeType})
+	}
+	return m
+}
+
+// containsAttachment reports whether an attachment is among those a rule
+// matched
+func containsAttachment(matched []rules.Attachment, ref gmail.AttachmentRef) bool {
+	for _, a := range matched {
+		if a.Filename == ref.Filename && a.MimeType == ref.MimeType {
+			return true
+		}
+	}
+	return false
+}
+
+// saveAttachment writes an attachment into dir, adding a number to the name
+// rather than overwriting an existing file
+func saveAttachment(dir, filename string, data []byte) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	name := filepath.Base(filepath.Clean("/" + filename))
+	if name == "/" || name == "." {
+		name = "attachment"
+	}
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+
+	path := filepath.Join(dir, name)
+	for n := 1; ; n++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, os.ErrExist) {
+			path = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, n, ext))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save %s: %w", filename, err)
+		}
+		_, err = f.Write(data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save %s: %w", filename, err)
+		}
+		return nil
+	}
+}
+
+func init() {
+	mailCmd.AddCommand(mailRulesCmd)
+	mailRulesCmd.AddCommand(mailRulesListCmd)
+	mailRulesCmd.AddCommand(mailRulesRunCmd)
+
+	mailRulesListCmd.Flags().String("file", "", "Rules file (default: rules.yaml in the config directory)")
+
+	addAccountFlag(mailRulesRunCmd)
+	mailRulesRunCmd.Flags().Bool("all", false, "Process all accounts")
+	mailRulesRunCmd.Flags().String("file", "", "Rules file (default: rules.yaml in the config directory)")
+	mailRulesRunCmd.Flags().StringSlice("rule", nil, "Only apply these rules")
+	mailRulesRunCmd.Flags().Int("days", 7, "Process emails received in this many days")
+	mailRulesRunCmd.Flags().Int64P("limit", "n", 500, "Maximum number of emails per rule per account")
+	mailRulesRunCmd.Flags().Bool("dry-run", false, "Show which rules match without applying them")
+}
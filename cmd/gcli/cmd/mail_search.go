@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var mailSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search emails",
+	Long: `Search emails with Gmail search syntax, or offline with --local.
+
+With --local the search runs against the full-text index of the local cache
+(see 'gcli mail sync'), covering subject, body, addresses and attachment
+names. Results from all selected accounts are ranked together, best match
+first. Local query syntax:
+  budget report              Both words (AND)
+  "quarterly report"         Exact phrase
+  budget OR forecast         Either word
+  budget -draft              Exclude a word (also: NOT draft)
+  (budget OR forecast) q3    Grouping
+  q3 -(draft OR old)         Exclude a group
+  budg*                      Prefix match
+  from:alice to:bob          Field match: subject, from, to, cc, body, filename
+
+Messages whose bodies have not been downloaded are only matched on their
+headers; use 'gcli mail sync --bodies' to index everything.
+
+Examples:
+  gcli mail search "from:alice has:attachment"
+  gcli mail search --local "invoice -paid" --all
+  gcli mail search --local 'subject:"offsite plan"' --after 2024-01-01`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+		local, _ := cmd.Flags().GetBool("local")
+		limit, _ := cmd.Flags().GetInt64("limit")
+		afterStr, _ := cmd.Flags().GetString("after")
+		beforeStr, _ := cmd.Flags().GetString("before")
+		query := strings.Join(args, " ")
+
+		var opts gmail.SearchOptions
+		opts.Limit = limit
+		if afterStr != "" {
+			after, err := parseDate(afterStr)
+			if err != nil {
+				return err
+			}
+			opts.After = after
+		}
+		if beforeStr != "" {
+			before, err := parseDate(beforeStr)
+			if err != nil {
+				return err
+			}
+			// --before is inclusive of the given day
+			opts.Before = before.AddDate(0, 0, 1)
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		if local {
+			cache, err := openCache(true)
+			if err != nil {
+				return err
+			}
+			defer cache.Close()
+
+			opts.Accounts = accounts
+			emails, err := cache.Search(query, opts)
+			if err != nil {
+				return err
+			}
+			output.PrintEmailList(emails)
+			return nil
+		}
+
+		// Gmail's after: includes the given day and before: excludes it
+		if !opts.After.IsZero() {
+			query += " after:" + opts.After.Format("2006/01/02")
+		}
+		if !opts.Before.IsZero() {
+			query += " before:" + opts.Before.Format("2006/01/02")
+		}
+
+		var allEmails []output.EmailSummary
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		errChan := make(chan error, len(accounts))
+
+		for _, accName := range accounts {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+
+				client, err := newGmailClient(ctx, cfg, name)
+				if err != nil {
+					errChan <- fmt.Errorf("[%s] %w", name, err)
+					return
+				}
+
+				emails, err := client.ListMessages(ctx, query, limit)
+				if err != nil {
+					errChan <- fmt.Errorf("[%s] %w", name, err)
+					return
+				}
+
+				mu.Lock()
+				allEmails = append(allEmails, emails...)
+				mu.Unlock()
+			}(accName)
+		}
+
+		wg.Wait()
+		close(errChan)
+
+		for err := range errChan {
+			output.PrintError("%v", err)
+		}
+
+		sort.SliceStable(allEmails, func(i, j int) bool {
+			return allEmails[i].Date.After(allEmails[j].Date)
+		})
+		output.PrintEmailList(allEmails)
+		return nil
+	},
+}
+
+func init() {
+	mailCmd.AddCommand(mailSearchCmd)
+
+	addAccountFlag(mailSearchCmd)
+	mailSearchCmd.Flags().Bool("all", false, "Search all accounts")
+	mailSearchCmd.Flags().Bool("local", false, "Search the local cache instead of Gmail")
+	mailSearchCmd.Flags().Int64P("limit", "n", 25, "Maximum number of results")
+	mailSearchCmd.Flags().String("after", "", "Only emails on or after this date (YYYY-MM-DD)")
+	mailSearchCmd.Flags().String("before", "", "Only emails on or before this date (YYYY-MM-DD)")
+}
@@ -0,0 +1,750 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// settingsChange is a planned change from 'mail settings apply'
+type settingsChange struct {
+	Account string `json:"account"`
+	Setting string `json:"setting"`
+	From    string `json:"from,omitempty"`
+	To      string `json:"to"`
+	Error   string `json:"error,omitempty"`
+
+	apply func(ctx context.Context, client *gmail.Client) error
+	// sharing is set for forwarding changes, which need a client from
+	// newGmailSharingClient
+	sharing bool
+}
+
+var mailSettingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Manage forwarding, IMAP, POP and language settings",
+	Long: `View and change an account's auto-forwarding, IMAP, POP and display
+language settings.
+
+Use export and apply to copy a settings profile to other accounts.
+Adding forwarding addresses and turning on auto-forwarding is only allowed
+for Workspace accounts through a service account with domain-wide
+delegation (see 'gcli auth add --help').`,
+}
+
+var mailSettingsShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show mail settings",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		var all []output.MailSettings
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			settings, err := client.GetSettings(ctx)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+			all = append(all, settings)
+		}
+
+		output.PrintMailSettings(all)
+		return nil
+	},
+}
+
+var mailSettingsForwardingCmd = &cobra.Command{
+	Use:   "forwarding",
+	Short: "Manage auto-forwarding",
+	Long: `Manage the addresses mail can be forwarded to and forwarding of all
+incoming mail.
+
+An address must be added and verified before mail can be forwarded to it.
+Gmail sends the address a confirmation email unless it is in the same
+Workspace domain.
+
+Examples:
+  gcli mail settings forwarding add archive@example.com
+  gcli mail settings forwarding on archive@example.com --disposition archive
+  gcli mail settings forwarding off`,
+}
+
+var mailSettingsForwardingAddCmd = &cobra.Command{
+	Use:   "add <email>",
+	Short: "Add a forwarding address",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		client, name, err := settingsClient(ctx, cmd, true)
+		if err != nil {
+			return err
+		}
+
+		address, err := client.AddForwardingAddress(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		if output.JSONOutput {
+			output.PrintJSON(address)
+			return nil
+		}
+		if address.VerificationStatus == "accepted" {
+			output.PrintSuccess("[%s] Added forwarding address %s", name, address.Email)
+			return nil
+		}
+		output.PrintSuccess("[%s] Added forwarding address %s; it must confirm the verification email before use", name, address.Email)
+		return nil
+	},
+}
+
+var mailSettingsForwardingRemoveCmd = &cobra.Command{
+	Use:   "remove <email>",
+	Short: "Remove a forwarding address",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		client, name, err := settingsClient(ctx, cmd, true)
+		if err != nil {
+			return err
+		}
+
+		if err := client.RemoveForwardingAddress(ctx, args[0]); err != nil {
+			return err
+		}
+
+		output.PrintSuccess("[%s] Removed forwarding address %s", name, args[0])
+		return nil
+	},
+}
+
+var mailSettingsForwardingOnCmd = &cobra.Command{
+	Use:   "on <email>",
+	Short: "Forward all incoming mail to a verified address",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		disposition, _ := cmd.Flags().GetString("disposition")
+
+		enabled := true
+		fwd := output.AutoForwarding{Enabled: &enabled, Email: args[0], Disposition: disposition}
+		if err := gmail.ValidateSettings(output.MailSettings{AutoForwarding: &fwd}); err != nil {
+			return err
+		}
+
+		client, name, err := settingsClient(ctx, cmd, true)
+		if err != nil {
+			return err
+		}
+
+		if err := client.SetAutoForwarding(ctx, fwd); err != nil {
+			return err
+		}
+
+		output.PrintSuccess("[%s] Forwarding mail %s", name, fwd)
+		return nil
+	},
+}
+
+var mailSettingsForwardingOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Stop forwarding incoming mail",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		client, name, err := settingsClient(ctx, cmd, true)
+		if err != nil {
+			return err
+		}
+
+		if err := client.SetAutoForwarding(ctx, output.AutoForwarding{}); err != nil {
+			return err
+		}
+
+		output.PrintSuccess("[%s] Auto-forwarding turned off", name)
+		return nil
+	},
+}
+
+var mailSettingsIMAPCmd = &cobra.Command{
+	Use:   "imap",
+	Short: "Change IMAP settings",
+	Long: `Change IMAP settings. Only the given flags are changed.
+
+Examples:
+  gcli mail settings imap --enable --expunge-behavior trash
+  gcli mail settings imap --disable -a work`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		enable, _ := cmd.Flags().GetBool("enable")
+		disable, _ := cmd.Flags().GetBool("disable")
+
+		if enable && disable {
+			return fmt.Errorf("--enable and --disable cannot be used together")
+		}
+		if !cmd.Flags().Changed("enable") && !cmd.Flags().Changed("disable") &&
+			!cmd.Flags().Changed("auto-expunge") && !cmd.Flags().Changed("expunge-behavior") &&
+			!cmd.Flags().Changed("max-folder-size") {
+			return fmt.Errorf("nothing to change (use --enable, --disable, --auto-expunge, --expunge-behavior, or --max-folder-size)")
+		}
+
+		client, name, err := settingsClient(ctx, cmd, false)
+		if err != nil {
+			return err
+		}
+
+		current, err := client.GetSettings(ctx)
+		if err != nil {
+			return err
+		}
+
+		imap := *current.IMAP
+		if enable || disable {
+			imap.Enabled = &enable
+		}
+		if cmd.Flags().Changed("auto-expunge") {
+			autoExpunge, _ := cmd.Flags().GetBool("auto-expunge")
+			imap.AutoExpunge = &autoExpunge
+		}
+		if cmd.Flags().Changed("expunge-behavior") {
+			imap.ExpungeBehavior, _ = cmd.Flags().GetString("expunge-behavior")
+		}
+		if cmd.Flags().Changed("max-folder-size") {
+			size, _ := cmd.Flags().GetInt64("max-folder-size")
+			imap.MaxFolderSize = &size
+		}
+		if err := gmail.ValidateSettings(output.MailSettings{IMAP: &imap}); err != nil {
+			return err
+		}
+
+		if err := client.SetIMAP(ctx, imap); err != nil {
+			return err
+		}
+
+		output.PrintSuccess("[%s] IMAP: %s", name, imap)
+		return nil
+	},
+}
+
+var mailSettingsPOPCmd = &cobra.Command{
+	Use:   "pop",
+	Short: "Change POP settings",
+	Long: `Change POP settings. Only the given flags are changed.
+
+--access-window selects which messages can be downloaded: disabled,
+fromNowOn, or allMail.
+
+Examples:
+  gcli mail settings pop --access-window fromNowOn --disposition archive
+  gcli mail settings pop --access-window disabled`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		if !cmd.Flags().Changed("access-window") && !cmd.Flags().Changed("disposition") {
+			return fmt.Errorf("nothing to change (use --access-window or --disposition)")
+		}
+
+		client, name, err := settingsClient(ctx, cmd, false)
+		if err != nil {
+			return err
+		}
+
+		current, err := client.GetSettings(ctx)
+		if err != nil {
+			return err
+		}
+
+		pop := *current.POP
+		if cmd.Flags().Changed("access-window") {
+			pop.AccessWindow, _ = cmd.Flags().GetString("access-window")
+		}
+		if cmd.Flags().Changed("disposition") {
+			pop.Disposition, _ = cmd.Flags().GetString("disposition")
+		}
+		if err := gmail.ValidateSettings(output.MailSettings{POP: &pop}); err != nil {
+			return err
+		}
+
+		if err := client.SetPOP(ctx, pop); err != nil {
+			return err
+		}
+
+		output.PrintSuccess("[%s] POP: %s", name, pop)
+		return nil
+	},
+}
+
+var mailSettingsLanguageCmd = &cobra.Command{
+	Use:   "language <code>",
+	Short: "Change the display language",
+	Long: `Change the language of the Gmail interface, as an RFC 3066 language tag.
+
+Gmail may pick a close variant it supports; the language it chose is shown.
+
+Example:
+  gcli mail settings language en-GB`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		client, name, err := settingsClient(ctx, cmd, false)
+		if err != nil {
+			return err
+		}
+
+		if err := client.SetLanguage(ctx, output.LanguageSettings{DisplayLanguage: args[0]}); err != nil {
+			return err
+		}
+
+		output.PrintSuccess("[%s] Display language set to %s", name, args[0])
+		return nil
+	},
+}
+
+var mailSettingsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export settings to YAML or JSON",
+	Long: `Export an account's settings as a profile, to a file or stdout if no
+file is given.
+
+Remove any sections that should not be copied before applying the profile
+to other accounts. The format is taken from --format, or from the file
+extension (.json for JSON, anything else for YAML).
+
+Examples:
+  gcli mail settings export -o settings.yaml
+  gcli mail settings export -a work --format json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		outPath, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+
+		client, name, err := settingsClient(ctx, cmd, false)
+		if err != nil {
+			return err
+		}
+
+		settings, err := client.GetSettings(ctx)
+		if err != nil {
+			return err
+		}
+		settings.Account = ""
+		for i := range settings.ForwardingAddresses {
+			settings.ForwardingAddresses[i].VerificationStatus = ""
+		}
+
+		data, err := encodeSettingsFile(settings, filterFileFormat(format, outPath))
+		if err != nil {
+			return err
+		}
+
+		if outPath == "" {
+			os.Stdout.Write(data)
+			return nil
+		}
+		if err := os.WriteFile(outPath, data, 0644); err != nil {
+			return fmt.Errorf("failed to write settings: %w", err)
+		}
+		output.PrintSuccess("Exported settings of %s to %s", name, outPath)
+		return nil
+	},
+}
+
+var mailSettingsApplyCmd = &cobra.Command{
+	Use:   "apply <file>",
+	Short: "Apply a settings profile from YAML or JSON",
+	Long: `Change an account's settings to match a profile, as written by export.
+
+Only the sections in the profile are changed, and within a section only the
+values it sets, so "imap: {expunge_behavior: trash}" leaves IMAP access as it
+is. Forwarding addresses in the
+profile are added if missing, but addresses are never removed. Use --diff
+to show the changes without applying them.
+
+Examples:
+  gcli mail settings apply settings.yaml --all --diff
+  gcli mail settings apply settings.yaml --all`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+		diffOnly, _ := cmd.Flags().GetBool("diff")
+		format, _ := cmd.Flags().GetString("format")
+
+		var r io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open settings: %w", err)
+			}
+			defer f.Close()
+			r = f
+		}
+
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("failed to read settings: %w", err)
+		}
+
+		desired, err := decodeSettingsFile(data, filterFileFormat(format, args[0]))
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		changes := []settingsChange{}
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			current, err := client.GetSettings(ctx)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			planned := planSettings(name, current, desired)
+			if !output.JSONOutput {
+				if len(planned) == 0 {
+					output.PrintInfo("[%s] Settings are up to date", name)
+				} else {
+					fmt.Printf("[%s]\n", name)
+				}
+			}
+
+			// Forwarding changes need the sharing scope, which is only
+			// requested when the profile changes forwarding
+			var sharingClient *gmail.Client
+			for _, c := range planned {
+				if !diffOnly {
+					var err error
+					target := client
+					if c.sharing {
+						if sharingClient == nil {
+							sharingClient, err = newGmailSharingClient(ctx, cfg, name)
+						}
+						target = sharingClient
+					}
+					if err == nil {
+						err = c.apply(ctx, target)
+					}
+					if err != nil {
+						c.Error = err.Error()
+					}
+				}
+				changes = append(changes, c)
+
+				if !output.JSONOutput {
+					printSettingsChange(c)
+				}
+			}
+		}
+
+		if output.JSONOutput {
+			output.PrintJSON(changes)
+		}
+		return nil
+	},
+}
+
+// settingsClient creates a client for the account selected by --account.
+// Changing forwarding needs a client created with sharing set.
+func settingsClient(ctx context.Context, cmd *cobra.Command, sharing bool) (*gmail.Client, string, error) {
+	accountName, _ := cmd.Flags().GetString("account")
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	name, _, err := cfg.GetAccount(accountName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	newClient := newGmailClient
+	if sharing {
+		newClient = newGmailSharingClient
+	}
+	client, err := newClient(ctx, cfg, name)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, name, nil
+}
+
+// planSettings lists the changes needed to bring an account's settings in
+// line with a profile. Values the profile leaves out keep their current
+// value.
+func planSettings(account string, current, desired output.MailSettings) []settingsChange {
+	var changes []settingsChange
+
+	// Addresses are added first so auto-forwarding can use them
+	for _, want := range desired.ForwardingAddresses {
+		found := false
+		for _, have := range current.ForwardingAddresses {
+			if strings.EqualFold(have.Email, want.Email) {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		email := want.Email
+		changes = append(changes, settingsChange{
+			Account: account,
+			Setting: "forwarding address",
+			To:      email,
+			apply: func(ctx context.Context, client *gmail.Client) error {
+				_, err := client.AddForwardingAddress(ctx, email)
+				return err
+			},
+			sharing: true,
+		})
+	}
+
+	if want := desired.AutoForwarding; want != nil && current.AutoForwarding != nil {
+		have := *current.AutoForwarding
+		fwd := have
+		if want.Enabled != nil {
+			fwd.Enabled = want.Enabled
+		}
+		if want.Email != "" {
+			fwd.Email = want.Email
+		}
+		if want.Disposition != "" {
+			fwd.Disposition = want.Disposition
+		}
+		// Address and disposition do not matter while forwarding is off
+		changed := fwd.IsEnabled() != have.IsEnabled() || fwd.Email != have.Email || fwd.Disposition != have.Disposition
+		if changed && (fwd.IsEnabled() || have.IsEnabled()) {
+			changes = append(changes, settingsChange{
+				Account: account,
+				Setting: "auto-forwarding",
+				From:    have.String(),
+				To:      fwd.String(),
+				apply: func(ctx context.Context, client *gmail.Client) error {
+					return client.SetAutoForwarding(ctx, fwd)
+				},
+				sharing: true,
+			})
+		}
+	}
+
+	if want := desired.IMAP; want != nil && current.IMAP != nil {
+		have := *current.IMAP
+		imap := have
+		if want.Enabled != nil {
+			imap.Enabled = want.Enabled
+		}
+		if want.AutoExpunge != nil {
+			imap.AutoExpunge = want.AutoExpunge
+		}
+		if want.ExpungeBehavior != "" {
+			imap.ExpungeBehavior = want.ExpungeBehavior
+		}
+		if want.MaxFolderSize != nil {
+			imap.MaxFolderSize = want.MaxFolderSize
+		}
+		if imap.IsEnabled() != have.IsEnabled() || imap.IsAutoExpunge() != have.IsAutoExpunge() ||
+			imap.ExpungeBehavior != have.ExpungeBehavior || imap.FolderLimit() != have.FolderLimit() {
+			changes = append(changes, settingsChange{
+				Account: account,
+				Setting: "IMAP",
+				From:    have.String(),
+				To:      imap.String(),
+				apply: func(ctx context.Context, client *gmail.Client) error {
+					return client.SetIMAP(ctx, imap)
+				},
+			})
+		}
+	}
+
+	if want := desired.POP; want != nil && current.POP != nil {
+		have := *current.POP
+		pop := *want
+		if pop.AccessWindow == "" {
+			pop.AccessWindow = have.AccessWindow
+		}
+		if pop.Disposition == "" {
+			pop.Disposition = have.Disposition
+		}
+		if pop != have {
+			changes = append(changes, settingsChange{
+				Account: account,
+				Setting: "POP",
+				From:    have.String(),
+				To:      pop.String(),
+				apply: func(ctx context.Context, client *gmail.Client) error {
+					return client.SetPOP(ctx, pop)
+				},
+			})
+		}
+	}
+
+	if want := desired.Language; want != nil && current.Language != nil && *want != *current.Language {
+		lang := *want
+		changes = append(changes, settingsChange{
+			Account: account,
+			Setting: "language",
+			From:    current.Language.String(),
+			To:      lang.String(),
+			apply: func(ctx context.Context, client *gmail.Client) error {
+				return client.SetLanguage(ctx, lang)
+			},
+		})
+	}
+
+	return changes
+}
+
+// printSettingsChange prints a planned or applied settings change in diff
+// style
+func printSettingsChange(c settingsChange) {
+	line := fmt.Sprintf("  + %s: %s", c.Setting, c.To)
+	if c.From != "" {
+		line = fmt.Sprintf("  ~ %s: %s → %s", c.Setting, c.From, c.To)
+	}
+	if c.Error != "" {
+		line += "  (failed: " + c.Error + ")"
+	}
+	fmt.Println(line)
+}
+
+// encodeSettingsFile encodes a settings profile as YAML or JSON
+func encodeSettingsFile(settings output.MailSettings, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(settings, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode settings: %w", err)
+		}
+		return append(data, '\n'), nil
+	case "yaml", "yml":
+		data, err := yaml.Marshal(settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode settings: %w", err)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("unknown format '%s' (use yaml or json)", format)
+}
+
+// decodeSettingsFile decodes and validates a settings profile from YAML or
+// JSON
+func decodeSettingsFile(data []byte, format string) (output.MailSettings, error) {
+	var settings output.MailSettings
+	switch format {
+	case "json":
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return settings, fmt.Errorf("failed to parse settings: %w", err)
+		}
+	case "yaml", "yml":
+		if err := yaml.Unmarshal(data, &settings); err != nil {
+			return settings, fmt.Errorf("failed to parse settings: %w", err)
+		}
+	default:
+		return settings, fmt.Errorf("unknown format '%s' (use yaml or json)", format)
+	}
+
+	settings.Account = ""
+	for i := range settings.ForwardingAddresses {
+		settings.ForwardingAddresses[i].VerificationStatus = ""
+	}
+	if err := gmail.ValidateSettings(settings); err != nil {
+		return settings, err
+	}
+	return settings, nil
+}
+
+func init() {
+	mailCmd.AddCommand(mailSettingsCmd)
+	mailSettingsCmd.AddCommand(mailSettingsShowCmd)
+	mailSettingsCmd.AddCommand(mailSettingsForwardingCmd)
+	mailSettingsForwardingCmd.AddCommand(mailSettingsForwardingAddCmd)
+	mailSettingsForwardingCmd.AddCommand(mailSettingsForwardingRemoveCmd)
+	mailSettingsForwardingCmd.AddCommand(mailSettingsForwardingOnCmd)
+	mailSettingsForwardingCmd.AddCommand(mailSettingsForwardingOffCmd)
+	mailSettingsCmd.AddCommand(mailSettingsIMAPCmd)
+	mailSettingsCmd.AddCommand(mailSettingsPOPCmd)
+	mailSettingsCmd.AddCommand(mailSettingsLanguageCmd)
+	mailSettingsCmd.AddCommand(mailSettingsExportCmd)
+	mailSettingsCmd.AddCommand(mailSettingsApplyCmd)
+
+	addAccountFlag(mailSettingsShowCmd)
+	mailSettingsShowCmd.Flags().Bool("all", false, "Show settings of all accounts")
+
+	addAccountFlag(mailSettingsForwardingAddCmd)
+	addAccountFlag(mailSettingsForwardingRemoveCmd)
+	addAccountFlag(mailSettingsForwardingOnCmd)
+	mailSettingsForwardingOnCmd.Flags().String("disposition", "leaveInInbox", "What happens to forwarded mail: leaveInInbox, archive, trash, or markRead")
+	addAccountFlag(mailSettingsForwardingOffCmd)
+
+	addAccountFlag(mailSettingsIMAPCmd)
+	mailSettingsIMAPCmd.Flags().Bool("enable", false, "Turn IMAP on")
+	mailSettingsIMAPCmd.Flags().Bool("disable", false, "Turn IMAP off")
+	mailSettingsIMAPCmd.Flags().Bool("auto-expunge", false, "Expunge messages as soon as they are deleted in IMAP")
+	mailSettingsIMAPCmd.Flags().String("expunge-behavior", "", "What happens to expunged mail: archive, trash, or deleteForever")
+	mailSettingsIMAPCmd.Flags().Int64("max-folder-size", 0, "Maximum messages per folder: 1000, 2000, 5000, 10000, or 0 for no limit")
+
+	addAccountFlag(mailSettingsPOPCmd)
+	mailSettingsPOPCmd.Flags().String("access-window", "", "Messages POP can download: disabled, fromNowOn, or allMail")
+	mailSettingsPOPCmd.Flags().String("disposition", "", "What happens to downloaded mail: leaveInInbox, archive, trash, or markRead")
+
+	addAccountFlag(mailSettingsLanguageCmd)
+
+	addAccountFlag(mailSettingsExportCmd)
+	mailSettingsExportCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+	mailSettingsExportCmd.Flags().String("format", "", "File format: yaml or json (default: from file extension)")
+
+	addAccountFlag(mailSettingsApplyCmd)
+	mailSettingsApplyCmd.Flags().Bool("all", false, "Apply the profile to all accounts")
+	mailSettingsApplyCmd.Flags().Bool("diff", false, "Show the changes without applying them")
+	mailSettingsApplyCmd.Flags().String("format", "", "File format: yaml or json (default: from file extension)")
+}
@@ -0,0 +1,351 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var mailSnoozeCmd = &cobra.Command{
+	Use:   "snooze <message-id>...",
+	Short: "Snooze emails until later",
+	Long: `Archive the threads of the given emails until a later time.
+
+Snoozed threads are labelled gcli/snoozed and recorded locally. Run
+'gcli mail snooze process' regularly (from cron, or with --every to keep it
+running) to move threads whose time has come back to the inbox as unread.
+
+--until accepts a date and time (2024-12-25 09:00), a date (8am that day),
+a duration from now (30m, 2h, 3d, 1w), or one of today, tonight, tomorrow,
+weekend, next week and a weekday name, optionally followed by a time
+(tomorrow 9am, friday 14:30). Without a time, mornings are 8am and tonight
+is 8pm; after tonight, an hour without am or pm is in the evening.
+
+Examples:
+  gcli mail snooze 18c2f1a2b3c4d5e6 --until "tomorrow 9am"
+  gcli mail snooze 18c2f1a2b3c4d5e6 --until 3d
+  gcli mail snooze process --every 5m`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		untilStr, _ := cmd.Flags().GetString("until")
+
+		if untilStr == "" {
+			return fmt.Errorf("wake-up time is required (--until)")
+		}
+		until, err := parseWhen(untilStr, time.Now())
+		if err != nil {
+			return err
+		}
+		if !until.After(time.Now()) {
+			return fmt.Errorf("--until must be in the future")
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		name, _, err := cfg.GetAccount(accountName)
+		if err != nil {
+			return err
+		}
+
+		client, err := newGmailClient(ctx, cfg, name)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range args {
+			thread, err := client.SnoozeMessage(ctx, id)
+			if err != nil {
+				output.PrintError("%s: %v", id, err)
+				continue
+			}
+			thread.Until = until
+			if err := gmail.AddSnoozedThread(thread); err != nil {
+				return fmt.Errorf("failed to record snooze: %w", err)
+			}
+			output.PrintSuccess("Snoozed \"%s\" until %s", thread.Subject, until.Format("Mon 2006-01-02 15:04"))
+		}
+		return nil
+	},
+}
+
+var mailSnoozeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List snoozed emails",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountName, _ := cmd.Flags().GetString("account")
+
+		threads, err := gmail.GetSnoozedThreadsByAccount(accountName)
+		if err != nil {
+			return err
+		}
+
+		output.PrintSnoozedThreads(threads)
+		return nil
+	},
+}
+
+var mailSnoozeProcessCmd = &cobra.Command{
+	Use:   "process",
+	Short: "Move snoozed emails that are due back to the inbox",
+	Long: `Move snoozed threads whose wake-up time has passed back to the inbox
+and mark them unread.
+
+Run it from cron, or pass --every to keep running and check at that
+interval until interrupted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountName, _ := cmd.Flags().GetString("account")
+		every, _ := cmd.Flags().GetDuration("every")
+
+		if every == 0 {
+			return processSnoozed(context.Background(), accountName)
+		}
+		if every < time.Minute {
+			return fmt.Errorf("--every must be at least 1m")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			if err := processSnoozed(ctx, accountName); err != nil && ctx.Err() == nil {
+				output.PrintError("%v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+var mailSnoozeCancelCmd = &cobra.Command{
+	Use:   "cancel <id>...",
+	Short: "Move snoozed emails back to the inbox now",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		threads, err := gmail.LoadSnoozedThreads()
+		if err != nil {
+			return err
+		}
+		byID := make(map[string]gmail.SnoozedThreadData, len(threads))
+		for _, t := range threads {
+			byID[t.ID] = t
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		for _, id := range args {
+			t, ok := byID[id]
+			if !ok {
+				output.PrintError("snoozed email '%s' not found", id)
+				continue
+			}
+			if err := wakeSnoozed(ctx, cfg, t); err != nil {
+				output.PrintError("[%s] %s: %v", t.Account, t.Subject, err)
+				continue
+			}
+			output.PrintSuccess("[%s] Back in inbox: %s", t.Account, t.Subject)
+		}
+		return nil
+	},
+}
+
+// processSnoozed wakes all due snoozed threads
+func processSnoozed(ctx context.Context, accountName string) error {
+	due, err := gmail.GetDueSnoozedThreads(accountName)
+	if err != nil {
+		return err
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	for _, t := range due {
+		if err := wakeSnoozed(ctx, cfg, t); err != nil {
+			output.PrintError("[%s] %s: %v", t.Account, t.Subject, err)
+			continue
+		}
+		output.PrintSuccess("[%s] Back in inbox: %s", t.Account, t.Subject)
+	}
+	return nil
+}
+
+// wakeSnoozed moves a snoozed thread back to the inbox and forgets it
+func wakeSnoozed(ctx context.Context, cfg *config.Config, t gmail.SnoozedThreadData) error {
+	client, err := newGmailClient(ctx, cfg, t.Account)
+	if err != nil {
+		return err
+	}
+	if err := client.WakeThread(ctx, t.ThreadID); err != nil {
+		return err
+	}
+	return gmail.RemoveSnoozedThread(t.ID)
+}
+
+var (
+	// relativeDuration matches durations such as 30m, 2h, 3d and 1w
+	relativeDuration = regexp.MustCompile(`^(\d+)\s*(m|min|mins|minutes?|h|hours?|d|days?|w|weeks?)$`)
+	// clockTime matches times such as 9am, 9:30 pm and 14:00
+	clockTime = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+)
+
+// parseWhen parses a point in time given as an absolute date and time, a
+// duration from now, or a day word optionally followed by a time of day
+func parseWhen(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	// Absolute times are parsed before lowercasing, which would break the
+	// "T" and "Z" of RFC 3339
+	if t, err := parseDateTime(s); err == nil {
+		return t, nil
+	}
+	if t, err := parseDate(s); err == nil {
+		return time.Date(t.Year(), t.Month(), t.Day(), 8, 0, 0, 0, t.Location()), nil
+	}
+	s = strings.ToLower(s)
+
+	if m := relativeDuration.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2][0] {
+		case 'm':
+			return now.Add(time.Duration(n) * time.Minute), nil
+		case 'h':
+			return now.Add(time.Duration(n) * time.Hour), nil
+		case 'd':
+			return now.AddDate(0, 0, n), nil
+		default:
+			return now.AddDate(0, 0, 7*n), nil
+		}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	day, clock, found := "", "", false
+	for _, prefix := range []string{"next week", "today", "tonight", "tomorrow", "weekend", "this weekend"} {
+		if strings.HasPrefix(s, prefix) {
+			day, clock, found = prefix, strings.TrimSpace(strings.TrimPrefix(s, prefix)), true
+			break
+		}
+	}
+	if !found {
+		day, clock, _ = strings.Cut(s, " ")
+		clock = strings.TrimSpace(clock)
+	}
+	clock = strings.TrimSpace(strings.TrimPrefix(clock, "at "))
+
+	var date time.Time
+	hour, minute := 8, 0
+	switch day {
+	case "today":
+		date = today
+	case "tonight":
+		date = today
+		hour = 20
+	case "tomorrow":
+		date = today.AddDate(0, 0, 1)
+	case "weekend", "this weekend":
+		days := (int(time.Saturday) - int(now.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		date = today.AddDate(0, 0, days)
+	case "next week":
+		days := (int(time.Monday) - int(now.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		date = today.AddDate(0, 0, days)
+	default:
+		weekday, ok := parseWeekday(day)
+		if !ok {
+			return time.Time{}, fmt.Errorf("could not parse time: %s (use e.g. \"tomorrow 9am\", \"3d\" or \"2024-12-25 09:00\")", s)
+		}
+		days := (int(weekday) - int(now.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		date = today.AddDate(0, 0, days)
+	}
+
+	if clock != "" {
+		m := clockTime.FindStringSubmatch(clock)
+		if m == nil {
+			return time.Time{}, fmt.Errorf("could not parse time of day: %s", clock)
+		}
+		hour, _ = strconv.Atoi(m[1])
+		if m[2] != "" {
+			minute, _ = strconv.Atoi(m[2])
+		}
+		if m[3] != "" && (hour < 1 || hour > 12) {
+			return time.Time{}, fmt.Errorf("invalid time of day: %s", clock)
+		}
+		switch {
+		case m[3] == "am" && hour == 12:
+			hour = 0
+		case m[3] == "pm" && hour < 12:
+			hour += 12
+		case m[3] == "" && day == "tonight" && hour >= 1 && hour < 12:
+			// "tonight 9" means 9pm
+			hour += 12
+		}
+		if hour > 23 || minute > 59 {
+			return time.Time{}, fmt.Errorf("invalid time of day: %s", clock)
+		}
+	}
+
+	return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, date.Location()), nil
+}
+
+// parseWeekday parses a full or abbreviated weekday name
+func parseWeekday(s string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || (len(s) >= 3 && strings.HasPrefix(name, s)) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+func init() {
+	mailCmd.AddCommand(mailSnoozeCmd)
+	mailSnoozeCmd.AddCommand(mailSnoozeListCmd)
+	mailSnoozeCmd.AddCommand(mailSnoozeProcessCmd)
+	mailSnoozeCmd.AddCommand(mailSnoozeCancelCmd)
+
+	addAccountFlag(mailSnoozeCmd)
+	mailSnoozeCmd.Flags().String("until", "", "When to bring the emails back (e.g. \"tomorrow 9am\", 3d, 2024-12-25)")
+
+	addAccountFlag(mailSnoozeListCmd)
+	addAccountFlag(mailSnoozeProcessCmd)
+	mailSnoozeProcessCmd.Flags().Duration("every", 0, "Keep running and check at this interval")
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWhen(t *testing.T) {
+	origLocal := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = origLocal })
+
+	// A Wednesday afternoon
+	now := time.Date(2024, 12, 18, 15, 30, 0, 0, time.UTC)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr string
+	}{
+		{in: "2024-12-25 09:00", want: at(12, 25, 9, 0)},
+		{in: "2024-12-25T17:45", want: at(12, 25, 17, 45)},
+		{in: "2024-12-25T17:45:00Z", want: at(12, 25, 17, 45)},
+		{in: "2024-12-25", want: at(12, 25, 8, 0)},
+
+		{in: "30m", want: now.Add(30 * time.Minute)},
+		{in: "90 minutes", want: now.Add(90 * time.Minute)},
+		{in: "2h", want: now.Add(2 * time.Hour)},
+		{in: "3d", want: now.AddDate(0, 0, 3)},
+		{in: "1 week", want: now.AddDate(0, 0, 7)},
+
+		{in: "today 17:00", want: at(12, 18, 17, 0)},
+		{in: "tonight", want: at(12, 18, 20, 0)},
+		{in: "tonight 9", want: at(12, 18, 21, 0)},
+		{in: "tonight 9:30", want: at(12, 18, 21, 30)},
+		{in: "tonight 11pm", want: at(12, 18, 23, 0)},
+		{in: "tonight 22:15", want: at(12, 18, 22, 15)},
+		{in: "tomorrow", want: at(12, 19, 8, 0)},
+		{in: "Tomorrow 9am", want: at(12, 19, 9, 0)},
+		{in: "tomorrow at 9", want: at(12, 19, 9, 0)},
+		{in: "tomorrow 12am", want: at(12, 19, 0, 0)},
+		{in: "tomorrow 12pm", want: at(12, 19, 12, 0)},
+		{in: "tomorrow 1:15 pm", want: at(12, 19, 13, 15)},
+		{in: "weekend", want: at(12, 21, 8, 0)},
+		{in: "this weekend 10am", want: at(12, 21, 10, 0)},
+		{in: "next week", want: at(12, 23, 8, 0)},
+
+		{in: "friday", want: at(12, 20, 8, 0)},
+		{in: "fri 14:30", want: at(12, 20, 14, 30)},
+		{in: "monday", want: at(12, 23, 8, 0)},
+		// The same weekday as today means next week
+		{in: "wednesday", want: at(12, 25, 8, 0)},
+		{in: "tue 9am", want: at(12, 24, 9, 0)},
+
+		{in: "someday", wantErr: "could not parse time"},
+		{in: "fr", wantErr: "could not parse time"},
+		{in: "tomorrow noonish", wantErr: "could not parse time of day"},
+		{in: "tomorrow 13pm", wantErr: "invalid time of day"},
+		{in: "tomorrow 0am", wantErr: "invalid time of day"},
+		{in: "tomorrow 24:00", wantErr: "invalid time of day"},
+		{in: "tomorrow 9:60", wantErr: "invalid time of day"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseWhen(tt.in, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseWhen(%q) = %v, %v, want error containing %q", tt.in, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseWhen(%q) error = %v", tt.in, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseWhen(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var mailSpamCmd = &cobra.Command{
+	Use:   "spam <message-id>...",
+	Short: "Report emails as spam",
+	Long: `Move emails to spam, which also reports them to Gmail's spam filter.
+
+Use 'mail inspect' first to check an email's authentication results and
+links when triaging a suspected phishing attempt.
+
+Examples:
+  gcli mail spam 18c2f1a2b3c4d5e6
+  gcli mail spam 18c2f1a2b3c4d5e6 18c2f1a2b3c4d5e7 -a work`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return modifySpam(cmd, args, true)
+	},
+}
+
+var mailNotSpamCmd = &cobra.Command{
+	Use:   "not-spam <message-id>...",
+	Short: "Move emails out of spam",
+	Long: `Move emails from spam back to the inbox, which also tells Gmail's spam
+filter they are wanted.
+
+Examples:
+  gcli mail read -q "in:spam" -n 20
+  gcli mail not-spam 18c2f1a2b3c4d5e6`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return modifySpam(cmd, args, false)
+	},
+}
+
+var mailInspectCmd = &cobra.Command{
+	Use:   "inspect <message-id>",
+	Short: "Check an email for spoofing and phishing",
+	Long: `Show the evidence needed to triage a suspicious email:
+
+  - SPF, DKIM and DMARC results from the Authentication-Results headers.
+    The first header is added by Gmail; later ones may come from the sender.
+  - The delivery path from the Received headers, sender first, with the
+    delay between hops.
+  - Links whose text shows a different address than they point to, or that
+    use IP addresses, look-alike (punycode) hosts, user names or unusual
+    schemes.
+  - Replies or bounces that go to a different domain than the sender's.
+
+Examples:
+  gcli mail inspect 18c2f1a2b3c4d5e6
+  gcli mail inspect 18c2f1a2b3c4d5e6 --json | jq .authentication`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		name, _, err := cfg.GetAccount(accountName)
+		if err != nil {
+			return err
+		}
+
+		client, err := newGmailClient(ctx, cfg, name)
+		if err != nil {
+			return err
+		}
+
+		inspection, err := client.InspectMessage(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		output.PrintMessageInspection(inspection)
+		return nil
+	},
+}
+
+// modifySpam moves messages into or out of spam
+func modifySpam(cmd *cobra.Command, ids []string, spam bool) error {
+	ctx := context.Background()
+	accountName, _ := cmd.Flags().GetString("account")
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	name, _, err := cfg.GetAccount(accountName)
+	if err != nil {
+		return err
+	}
+
+	client, err := newGmailClient(ctx, cfg, name)
+	if err != nil {
+		return err
+	}
+
+	if spam {
+		if err := client.MarkSpam(ctx, ids); err != nil {
+			return err
+		}
+		output.PrintSuccess("Reported %d email(s) as spam", len(ids))
+		return nil
+	}
+
+	if err := client.MarkNotSpam(ctx, ids); err != nil {
+		return err
+	}
+	output.PrintSuccess("Moved %d email(s) back to the inbox", len(ids))
+	return nil
+}
+
+func init() {
+	mailCmd.AddCommand(mailSpamCmd)
+	mailCmd.AddCommand(mailNotSpamCmd)
+	mailCmd.AddCommand(mailInspectCmd)
+
+	addAccountFlag(mailSpamCmd)
+	addAccountFlag(mailNotSpamCmd)
+	addAccountFlag(mailInspectCmd)
+}
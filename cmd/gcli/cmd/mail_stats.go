@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var mailStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show mailbox statistics",
+	Long: `Show where email volume comes from over a date range.
+
+Counts received emails by sender, domain, day of week and hour, unread
+emails by age, and emails by label and thread length. Reply latency is the
+time from a received email to your next reply in the same thread.
+
+Only message metadata is fetched, so large ranges stay within quota; use
+--limit to cap the number of emails examined per account. The range
+defaults to the last 30 days.
+
+Examples:
+  gcli mail stats
+  gcli mail stats --all --after 2024-01-01 --before 2024-03-31 --top 20
+  gcli mail stats -q "-category:promotions" --csv > stats.csv`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+		query, _ := cmd.Flags().GetString("query")
+		afterStr, _ := cmd.Flags().GetString("after")
+		beforeStr, _ := cmd.Flags().GetString("before")
+		limit, _ := cmd.Flags().GetInt64("limit")
+		top, _ := cmd.Flags().GetInt("top")
+		asCSV, _ := cmd.Flags().GetBool("csv")
+
+		today := time.Now()
+		today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local)
+
+		// before is exclusive internally; --before includes the given day
+		before := today.AddDate(0, 0, 1)
+		if beforeStr != "" {
+			t, err := parseDate(beforeStr)
+			if err != nil {
+				return err
+			}
+			before = t.AddDate(0, 0, 1)
+		}
+		after := before.AddDate(0, 0, -30)
+		if afterStr != "" {
+			t, err := parseDate(afterStr)
+			if err != nil {
+				return err
+			}
+			after = t
+		}
+		if !before.After(after) {
+			return fmt.Errorf("--before must not be earlier than --after")
+		}
+
+		// Gmail's after: includes the given day and before: excludes it
+		fullQuery := fmt.Sprintf("after:%s before:%s", after.Format("2006/01/02"), before.Format("2006/01/02"))
+		if query != "" {
+			fullQuery = query + " " + fullQuery
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		var msgs []gmail.StatMessage
+		var included []string
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			found, failed, err := client.ListStatMessages(ctx, fullQuery, limit)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+			if failed > 0 {
+				output.PrintWarning("[%s] %d emails could not be read and were left out", name, failed)
+			}
+			if limit > 0 && int64(len(found)) >= limit {
+				output.PrintWarning("[%s] Only the latest %d emails were examined; raise --limit for complete statistics", name, limit)
+			}
+			msgs = append(msgs, found...)
+			included = append(included, name)
+		}
+
+		stats := gmail.ComputeStats(msgs, top, time.Now())
+		stats.Accounts = included
+		stats.After = after
+		stats.Before = before
+
+		if asCSV {
+			return output.PrintMailStatsCSV(stats)
+		}
+		output.PrintMailStats(stats)
+		return nil
+	},
+}
+
+func init() {
+	mailCmd.AddCommand(mailStatsCmd)
+
+	addAccountFlag(mailStatsCmd)
+	mailStatsCmd.Flags().Bool("all", false, "Include all accounts")
+	mailStatsCmd.Flags().StringP("query", "q", "", "Only count emails matching this Gmail search query")
+	mailStatsCmd.Flags().String("after", "", "Start of the range (YYYY-MM-DD, default: 30 days before --before)")
+	mailStatsCmd.Flags().String("before", "", "End of the range, inclusive (YYYY-MM-DD, default: today)")
+	mailStatsCmd.Flags().Int64P("limit", "n", 5000, "Maximum number of emails to examine per account")
+	mailStatsCmd.Flags().Int("top", 10, "Number of senders, domains and labels to show")
+	mailStatsCmd.Flags().Bool("csv", false, "Output as CSV")
+}
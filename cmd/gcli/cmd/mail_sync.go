@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var mailSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync emails into the local cache",
+	Long: `Download emails into a local cache so they can be read offline.
+
+The first sync lists the whole mailbox (or the newest --limit messages).
+Later syncs use the Gmail History API to fetch only what changed. Once an
+account has been synced, 'mail read' and 'mail get' serve from the cache and
+accept --offline. If the sync position has expired (after about a week),
+'mail read' lists emails online until the account is synced again.
+
+By default only headers are cached and bodies are downloaded the first time
+a message is opened with 'mail get'. Use --bodies to download them up front.
+
+Examples:
+  gcli mail sync                      # Sync default account
+  gcli mail sync --all --bodies       # Sync everything for offline use
+  gcli mail sync -n 1000              # First sync only the newest 1000
+  gcli mail sync --full               # Rebuild from scratch`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+		limit, _ := cmd.Flags().GetInt64("limit")
+		bodies, _ := cmd.Flags().GetBool("bodies")
+		full, _ := cmd.Flags().GetBool("full")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		cache, err := gmail.OpenCache()
+		if err != nil {
+			return err
+		}
+		defer cache.Close()
+
+		opts := gmail.SyncOptions{
+			Limit:  limit,
+			Bodies: bodies,
+			Full:   full,
+		}
+
+		var results []gmail.SyncResult
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			result, err := client.Sync(ctx, cache, opts)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+			results = append(results, result)
+
+			if !output.JSONOutput {
+				kind := "Incremental sync"
+				if result.Full {
+					kind = "Full sync"
+				}
+				output.PrintSuccess("[%s] %s: %d added, %d updated, %d deleted",
+					name, kind, result.Added, result.Updated, result.Deleted)
+			}
+		}
+
+		if output.JSONOutput {
+			output.PrintJSON(results)
+		}
+		return nil
+	},
+}
+
+// openCache opens the local message cache if it has been created by
+// 'mail sync'. It returns nil when there is no cache, unless offline access
+// was requested.
+func openCache(offline bool) (*gmail.Cache, error) {
+	path, err := gmail.GetCachePath()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		if offline {
+			return nil, fmt.Errorf("no local cache found. Run 'gcli mail sync' first")
+		}
+		return nil, nil
+	}
+
+	return gmail.OpenCache()
+}
+
+// readAccountMessages lists messages for one account, using the cache when
+// the account has been synced
+func readAccountMessages(ctx context.Context, cfg *config.Config, cache *gmail.Cache, name, query string, limit int64, offline bool) ([]output.EmailSummary, error) {
+	var state *gmail.SyncState
+	if cache != nil {
+		var err error
+		if state, err = cache.SyncState(name); err != nil {
+			return nil, err
+		}
+	}
+
+	if offline {
+		if state == nil {
+			return nil, fmt.Errorf("account has not been synced. Run 'gcli mail sync -a %s' first", name)
+		}
+		return cache.ListMessages(name, limit)
+	}
+
+	client, err := newGmailClient(ctx, cfg, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if state == nil {
+		return client.ListMessages(ctx, query, limit)
+	}
+
+	// A full resync can take a long time, so when the history position has
+	// expired the mailbox is listed online until 'mail sync' is run again
+	_, err = client.Sync(ctx, cache, gmail.SyncOptions{Incremental: true})
+	if errors.Is(err, gmail.ErrHistoryExpired) {
+		if !output.JSONOutput {
+			output.PrintWarning("[%s] The local cache is out of date; run 'gcli mail sync -a %s' to refresh it", name, name)
+		}
+		return client.ListMessages(ctx, query, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return client.ListMessagesCached(ctx, cache, query, limit)
+}
+
+// getAccountMessage gets a message for one account, using the cache when
+// the account has been synced
+func getAccountMessage(ctx context.Context, cfg *config.Config, cache *gmail.Cache, name, id string, format gmail.BodyFormat, offline bool) (output.EmailDetail, error) {
+	var state *gmail.SyncState
+	if cache != nil {
+		var err error
+		if state, err = cache.SyncState(name); err != nil {
+			return output.EmailDetail{}, err
+		}
+	}
+
+	if offline {
+		detail, err := cache.GetMessage(name, id, format)
+		if errors.Is(err, gmail.ErrNotCached) {
+			return output.EmailDetail{}, fmt.Errorf("message %s is not in the local cache. Run 'gcli mail sync --bodies' or fetch it online first", id)
+		}
+		return detail, err
+	}
+
+	client, err := newGmailClient(ctx, cfg, name)
+	if err != nil {
+		return output.EmailDetail{}, err
+	}
+
+	if state == nil {
+		return client.GetMessage(ctx, id, format)
+	}
+	return client.GetMessageCached(ctx, cache, id, format)
+}
+
+func init() {
+	mailCmd.AddCommand(mailSyncCmd)
+
+	addAccountFlag(mailSyncCmd)
+	mailSyncCmd.Flags().Bool("all", false, "Sync all accounts")
+	mailSyncCmd.Flags().Int64P("limit", "n", 0, "Maximum number of messages for a full sync (0 for no limit)")
+	mailSyncCmd.Flags().Bool("bodies", false, "Download message bodies for offline reading")
+	mailSyncCmd.Flags().Bool("full", false, "Resync everything instead of applying changes since the last sync")
+}
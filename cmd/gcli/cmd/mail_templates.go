@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/alexandraswan/gcli/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+var mailTemplatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Manage email templates",
+	Long: `Email templates live in the templates directory under the config
+directory (~/.config/google-cli/templates). The file extension sets the body
+format: .txt for plain text, .html for HTML and .md for markdown.
+
+A template starts with optional Subject, To, Cc and Bcc header lines,
+followed by a blank line and the body:
+
+  Subject: Welcome to {{.team}}, {{.name}}!
+
+  Hi {{.name}},
+
+  Your account is ready.
+
+Headers and body use Go text/template syntax. Variables are set with --var
+on draft, send-now and schedule, or from CSV columns with 'mail merge'.
+Referencing a variable that is not set is an error. Values are HTML-escaped
+in the body of .html templates.`,
+}
+
+var mailTemplatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List email templates",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := templates.GetTemplatesDir()
+		if err != nil {
+			return err
+		}
+
+		list, err := templates.List()
+		if err != nil {
+			return err
+		}
+
+		var infos []output.TemplateInfo
+		for _, t := range list {
+			infos = append(infos, output.TemplateInfo{
+				Name:    t.Name,
+				Format:  t.Format,
+				Subject: t.Headers["subject"],
+				Path:    t.Path,
+			})
+		}
+
+		output.PrintTemplates(infos, dir)
+		return nil
+	},
+}
+
+func init() {
+	mailCmd.AddCommand(mailTemplatesCmd)
+	mailTemplatesCmd.AddCommand(mailTemplatesListCmd)
+}
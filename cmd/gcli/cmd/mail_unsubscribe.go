@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var mailUnsubscribeCmd = &cobra.Command{
+	Use:   "unsubscribe",
+	Short: "Unsubscribe from mailing lists",
+	Long: `Find mailing lists among emails matching a query and unsubscribe from them.
+
+Senders whose emails carry a List-Unsubscribe header are grouped and shown
+in a numbered table for review; pick the ones to unsubscribe from at the
+prompt, or pass --yes to unsubscribe from all of them (narrowed down with
+--sender). Use --list to only show the table.
+
+Lists supporting RFC 8058 one-click unsubscribe are unsubscribed from with
+an HTTPS POST. Otherwise an email is sent to the list's unsubscribe address.
+Lists that only link to a web page are reported with the link to visit.
+
+With --archive the sender's emails are moved out of the inbox, and with
+--filter a filter is created that skips the inbox for future emails from
+the sender.
+
+Examples:
+  gcli mail unsubscribe --list
+  gcli mail unsubscribe -q "category:promotions newer_than:90d" --archive
+  gcli mail unsubscribe --sender news@example.com --yes --filter`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+		query, _ := cmd.Flags().GetString("query")
+		limit, _ := cmd.Flags().GetInt64("limit")
+		senders, _ := cmd.Flags().GetStringSlice("sender")
+		listOnly, _ := cmd.Flags().GetBool("list")
+		yes, _ := cmd.Flags().GetBool("yes")
+		archive, _ := cmd.Flags().GetBool("archive")
+		filter, _ := cmd.Flags().GetBool("filter")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		clients := make(map[string]*gmail.Client)
+		var subs []output.Subscription
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			found, failed, err := client.ListSubscriptions(ctx, query, limit)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+			if failed > 0 {
+				output.PrintWarning("[%s] %d emails could not be read and were left out", name, failed)
+			}
+			clients[name] = client
+			subs = append(subs, matchSenders(found, senders)...)
+		}
+
+		if listOnly || len(subs) == 0 || (output.JSONOutput && !yes) {
+			output.PrintSubscriptions(subs)
+			return nil
+		}
+
+		selected := subs
+		if !yes {
+			output.PrintSubscriptions(subs)
+			fmt.Print("\nUnsubscribe from which senders? (e.g. 1,3,5-7 or 'all'; Enter to cancel): ")
+			line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			indexes, err := parseSelection(strings.TrimSpace(line), len(subs))
+			if err != nil {
+				return err
+			}
+			if len(indexes) == 0 {
+				output.PrintInfo("Cancelled")
+				return nil
+			}
+			selected = nil
+			for _, i := range indexes {
+				selected = append(selected, subs[i])
+			}
+			fmt.Println()
+		}
+
+		var results []output.UnsubscribeResult
+		for _, sub := range selected {
+			result := unsubscribeSender(ctx, clients[sub.Account], sub, archive, filter)
+			results = append(results, result)
+			if !output.JSONOutput {
+				printUnsubscribeResult(result)
+			}
+		}
+
+		if output.JSONOutput {
+			output.PrintJSON(results)
+		}
+		return nil
+	},
+}
+
+// unsubscribeSender unsubscribes from one sender, then archives and filters
+// its emails as requested
+func unsubscribeSender(ctx context.Context, client *gmail.Client, sub output.Subscription, archive, filter bool) output.UnsubscribeResult {
+	result := output.UnsubscribeResult{
+		Account: sub.Account,
+		Sender:  sub.Sender.Email,
+		Method:  sub.Method,
+		Status:  "unsubscribed",
+	}
+
+	if sub.Method == gmail.UnsubscribeWeb {
+		result.Status = "manual"
+		result.URL = sub.URL
+	} else if err := client.Unsubscribe(ctx, sub); err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+		return result
+	}
+
+	// An empty address would match every sender
+	if sub.Sender.Email == "" {
+		return result
+	}
+
+	if archive {
+		refs, err := client.ListMessageIDs(ctx, fmt.Sprintf("from:%s in:inbox", sub.Sender.Email), 0)
+		if err == nil {
+			ids := make([]string, len(refs))
+			for i, ref := range refs {
+				ids[i] = ref.ID
+			}
+			err = client.ModifyMessages(ctx, ids, nil, []string{"INBOX"})
+			if err == nil {
+				result.Archived = len(ids)
+			}
+		}
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+
+	if filter {
+		id, err := client.CreateFilter(ctx, output.Filter{
+			Criteria: output.FilterCriteria{From: sub.Sender.Email},
+			Action:   output.FilterAction{RemoveLabels: []string{"INBOX"}},
+		})
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.FilterID = id
+	}
+
+	return result
+}
+
+// printUnsubscribeResult prints the outcome for one sender
+func printUnsubscribeResult(r output.UnsubscribeResult) {
+	switch r.Status {
+	case "unsubscribed":
+		output.PrintSuccess("[%s] %s: unsubscribed (%s)", r.Account, r.Sender, r.Method)
+	case "manual":
+		output.PrintWarning("[%s] %s: visit %s to unsubscribe", r.Account, r.Sender, r.URL)
+	default:
+		output.PrintError("[%s] %s: %s", r.Account, r.Sender, r.Error)
+		return
+	}
+	if r.Archived > 0 {
+		output.PrintInfo("[%s] %s: archived %d email(s)", r.Account, r.Sender, r.Archived)
+	}
+	if r.FilterID != "" {
+		output.PrintInfo("[%s] %s: filter created (ID: %s)", r.Account, r.Sender, r.FilterID)
+	}
+	if r.Error != "" {
+		output.PrintError("[%s] %s: %s", r.Account, r.Sender, r.Error)
+	}
+}
+
+// matchSenders keeps the subscriptions from the given addresses or
+// @domains. All subscriptions are kept when no senders are given.
+func matchSenders(subs []output.Subscription, senders []string) []output.Subscription {
+	if len(senders) == 0 {
+		return subs
+	}
+
+	var matched []output.Subscription
+	for _, sub := range subs {
+		email := strings.ToLower(sub.Sender.Email)
+		for _, s := range senders {
+			s = strings.ToLower(strings.TrimSpace(s))
+			if email == s || (strings.HasPrefix(s, "@") && strings.HasSuffix(email, s)) {
+				matched = append(matched, sub)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// parseSelection parses a list of 1-based numbers and ranges such as
+// "1,3,5-7", or "all", into 0-based indexes
+func parseSelection(s string, n int) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if strings.EqualFold(s, "all") {
+		indexes := make([]int, n)
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes, nil
+	}
+
+	seen := make(map[int]bool)
+	var indexes []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection '%s'", part)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+				return nil, fmt.Errorf("invalid selection '%s'", part)
+			}
+		}
+		if first < 1 || last > n || first > last {
+			return nil, fmt.Errorf("selection '%s' is out of range (1-%d)", part, n)
+		}
+		for i := first; i <= last; i++ {
+			if !seen[i] {
+				seen[i] = true
+				indexes = append(indexes, i-1)
+			}
+		}
+	}
+	return indexes, nil
+}
+
+func init() {
+	mailCmd.AddCommand(mailUnsubscribeCmd)
+
+	addAccountFlag(mailUnsubscribeCmd)
+	mailUnsubscribeCmd.Flags().Bool("all", false, "Search all accounts")
+	mailUnsubscribeCmd.Flags().StringP("query", "q", "newer_than:30d", "Gmail search query for emails to scan")
+	mailUnsubscribeCmd.Flags().Int64P("limit", "n", 200, "Maximum number of emails to scan per account")
+	mailUnsubscribeCmd.Flags().StringSlice("sender", nil, "Only senders with these addresses or @domains")
+	mailUnsubscribeCmd.Flags().Bool("list", false, "Only list mailing lists, do not unsubscribe")
+	mailUnsubscribeCmd.Flags().BoolP("yes", "y", false, "Unsubscribe from all listed senders without prompting")
+	mailUnsubscribeCmd.Flags().Bool("archive", false, "Archive the sender's emails in the inbox")
+	mailUnsubscribeCmd.Flags().Bool("filter", false, "Create a filter that skips the inbox for the sender")
+}
@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/calendar"
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/markdown"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var mailVacationCmd = &cobra.Command{
+	Use:   "vacation",
+	Short: "Manage the vacation auto-reply",
+	Long:  `Show, enable or disable Gmail's vacation responder (out-of-office auto-reply).`,
+}
+
+var mailVacationShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show vacation auto-reply settings",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		var all []output.VacationSettings
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			settings, err := client.GetVacation(ctx)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+			all = append(all, settings)
+		}
+
+		output.PrintVacationSettings(all)
+		return nil
+	},
+}
+
+var mailVacationOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Turn on the vacation auto-reply",
+	Long: `Turn on the vacation auto-reply.
+
+Start and end accept the same formats as 'mail schedule --at', or a plain
+date (YYYY-MM-DD); an end date includes the whole day. Without --start the
+reply begins immediately, and without --end it stays on until turned off.
+
+With --calendar an out-of-office event covering the same period is added to
+the account's calendar, declining new invitations with the reply subject.
+This needs --start and --end.
+
+Examples:
+  gcli mail vacation on -s "Out of office" -b "Back on Monday." --end 2024-12-31
+  gcli mail vacation on -s "Away" --body-file away.md --markdown \
+    --start 2024-12-20 --end 2024-12-31 --contacts-only --calendar`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+		startStr, _ := cmd.Flags().GetString("start")
+		endStr, _ := cmd.Flags().GetString("end")
+		contactsOnly, _ := cmd.Flags().GetBool("contacts-only")
+		domainOnly, _ := cmd.Flags().GetBool("domain-only")
+		addEvent, _ := cmd.Flags().GetBool("calendar")
+
+		settings, err := vacationFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		settings.Enabled = true
+		settings.RestrictToContacts = contactsOnly
+		settings.RestrictToDomain = domainOnly
+
+		if startStr != "" {
+			if settings.Start, err = parseVacationTime(startStr, false); err != nil {
+				return err
+			}
+		}
+		if endStr != "" {
+			if settings.End, err = parseVacationTime(endStr, true); err != nil {
+				return err
+			}
+		}
+		if !settings.Start.IsZero() && !settings.End.IsZero() && !settings.End.After(settings.Start) {
+			return fmt.Errorf("--end must be after --start")
+		}
+		if addEvent && (settings.Start.IsZero() || settings.End.IsZero()) {
+			return fmt.Errorf("--calendar requires --start and --end")
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			if err := client.SetVacation(ctx, settings); err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+			output.PrintSuccess("[%s] Vacation auto-reply turned on", name)
+
+			if addEvent {
+				eventID, err := createOutOfOfficeEvent(ctx, cfg, name, settings)
+				if err != nil {
+					output.PrintError("[%s] %v", name, err)
+					continue
+				}
+				output.PrintSuccess("[%s] Out-of-office event created (ID: %s)", name, eventID)
+			}
+		}
+		return nil
+	},
+}
+
+var mailVacationOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Turn off the vacation auto-reply",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+
+			// Keep the message so it can be turned back on from the Gmail UI
+			settings, err := client.GetVacation(ctx)
+			if err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+			settings.Enabled = false
+
+			if err := client.SetVacation(ctx, settings); err != nil {
+				output.PrintError("[%s] %v", name, err)
+				continue
+			}
+			output.PrintSuccess("[%s] Vacation auto-reply turned off", name)
+		}
+		return nil
+	},
+}
+
+// vacationFromFlags reads the reply subject and body from flags
+func vacationFromFlags(cmd *cobra.Command) (output.VacationSettings, error) {
+	subject, _ := cmd.Flags().GetString("subject")
+	body, _ := cmd.Flags().GetString("body")
+	bodyFile, _ := cmd.Flags().GetString("body-file")
+	html, _ := cmd.Flags().GetBool("html")
+	isMarkdown, _ := cmd.Flags().GetBool("markdown")
+	theme, _ := cmd.Flags().GetString("theme")
+
+	if body != "" && bodyFile != "" {
+		return output.VacationSettings{}, fmt.Errorf("--body and --body-file cannot be used together")
+	}
+	if html && isMarkdown {
+		return output.VacationSettings{}, fmt.Errorf("--html and --markdown cannot be used together")
+	}
+	if theme != "" && !isMarkdown {
+		return output.VacationSettings{}, fmt.Errorf("--theme requires --markdown")
+	}
+
+	baseDir := "."
+	if bodyFile != "" {
+		data, err := readBodyFile(bodyFile)
+		if err != nil {
+			return output.VacationSettings{}, err
+		}
+		body = data
+		if bodyFile != "-" {
+			baseDir = filepath.Dir(bodyFile)
+		}
+	}
+
+	if body == "" {
+		return output.VacationSettings{}, fmt.Errorf("body is required (--body or --body-file)")
+	}
+
+	settings := output.VacationSettings{Subject: subject}
+	switch {
+	case isMarkdown:
+		rendered, err := markdown.Render(body, markdown.Options{
+			Theme:   theme,
+			BaseDir: baseDir,
+		})
+		if err != nil {
+			return output.VacationSettings{}, err
+		}
+		if len(rendered.Images) > 0 {
+			return output.VacationSettings{}, fmt.Errorf("local images are not supported in auto-replies; use image URLs instead")
+		}
+		settings.HTMLBody = rendered.HTML
+	case html:
+		settings.HTMLBody = body
+	default:
+		settings.Body = body
+	}
+
+	return settings, nil
+}
+
+// parseVacationTime parses a start or end time. A plain date as the end
+// time covers that whole day.
+func parseVacationTime(s string, end bool) (time.Time, error) {
+	if t, err := parseDateTime(s); err == nil {
+		return t, nil
+	}
+	t, err := parseDate(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// createOutOfOfficeEvent adds an out-of-office event for the vacation period
+func createOutOfOfficeEvent(ctx context.Context, cfg *config.Config, name string, settings output.VacationSettings) (string, error) {
+	_, acc, err := cfg.GetAccount(name)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := calendar.NewClient(ctx, name, acc)
+	if err != nil {
+		return "", err
+	}
+
+	summary := "Out of office"
+	if settings.Subject != "" {
+		summary = settings.Subject
+	}
+
+	return client.CreateEvent(ctx, calendar.EventInput{
+		Summary:        summary,
+		Start:          settings.Start,
+		End:            settings.End,
+		OutOfOffice:    true,
+		DeclineMessage: summary,
+	})
+}
+
+func init() {
+	mailCmd.AddCommand(mailVacationCmd)
+	mailVacationCmd.AddCommand(mailVacationShowCmd)
+	mailVacationCmd.AddCommand(mailVacationOnCmd)
+	mailVacationCmd.AddCommand(mailVacationOffCmd)
+
+	for _, c := range []*cobra.Command{mailVacationShowCmd, mailVacationOnCmd, mailVacationOffCmd} {
+		addAccountFlag(c)
+		c.Flags().Bool("all", false, "Apply to all accounts")
+	}
+
+	mailVacationOnCmd.Flags().StringP("subject", "s", "", "Auto-reply subject")
+	mailVacationOnCmd.Flags().StringP("body", "b", "", "Auto-reply body")
+	mailVacationOnCmd.Flags().String("body-file", "", "Read the body from a file ('-' for stdin)")
+	mailVacationOnCmd.Flags().Bool("html", false, "Body is HTML")
+	mailVacationOnCmd.Flags().Bool("markdown", false, "Render the body from markdown")
+	mailVacationOnCmd.Flags().String("theme", "", fmt.Sprintf("Inline CSS theme for markdown (%s)", strings.Join(markdown.ThemeNames(), ", ")))
+	mailVacationOnCmd.Flags().String("start", "", "When to start replying (default: now)")
+	mailVacationOnCmd.Flags().String("end", "", "When to stop replying (default: until turned off)")
+	mailVacationOnCmd.Flags().Bool("contacts-only", false, "Only reply to people in your contacts")
+	mailVacationOnCmd.Flags().Bool("domain-only", false, "Only reply to people in your domain (Workspace accounts)")
+	mailVacationOnCmd.Flags().Bool("calendar", false, "Also add an out-of-office event to your calendar")
+}
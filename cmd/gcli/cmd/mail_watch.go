@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
+	"github.com/alexandraswan/gcli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var mailWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch for new emails",
+	Long: `Print new emails as they arrive, until interrupted.
+
+By default the Gmail History API is polled every --interval. With --topic,
+Gmail publishes changes to a Cloud Pub/Sub topic instead and gcli listens on
+--listen for the subscription's push requests, checking for new mail as soon
+as a notification arrives; polling continues every --interval as a fallback.
+The push subscription must point at a public HTTPS URL that forwards to this
+address; add ?token=<secret> to that URL and pass the same --push-token to
+reject requests from anyone else.
+
+With --query only messages matching the Gmail search query are reported.
+
+With --exec the given shell command runs once per new message with the
+message as JSON on stdin and GCLI_ACCOUNT and GCLI_MESSAGE_ID set in its
+environment. Hook failures are reported but do not stop the watch.
+
+Examples:
+  gcli mail watch --all
+  gcli mail watch -q "from:alerts@example.com" --exec 'notify-send "New alert"'
+  gcli mail watch --topic projects/my-project/topics/gmail --listen :8080`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountName, _ := cmd.Flags().GetString("account")
+		allAccounts, _ := cmd.Flags().GetBool("all")
+		query, _ := cmd.Flags().GetString("query")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		hook, _ := cmd.Flags().GetString("exec")
+		topic, _ := cmd.Flags().GetString("topic")
+		listen, _ := cmd.Flags().GetString("listen")
+		pushToken, _ := cmd.Flags().GetString("push-token")
+
+		if interval < 5*time.Second {
+			return fmt.Errorf("--interval must be at least 5s")
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		accounts, err := resolveAccounts(cfg, accountName, allAccounts)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		var watchers []*mailWatcher
+		for _, name := range accounts {
+			client, err := newGmailClient(ctx, cfg, name)
+			if err != nil {
+				return fmt.Errorf("[%s] %w", name, err)
+			}
+
+			email, historyID, err := client.GetProfile(ctx)
+			if err != nil {
+				return fmt.Errorf("[%s] %w", name, err)
+			}
+
+			watchers = append(watchers, &mailWatcher{
+				client:    client,
+				name:      name,
+				email:     email,
+				historyID: historyID,
+				query:     query,
+				hook:      hook,
+				trigger:   make(chan struct{}, 1),
+			})
+		}
+
+		if topic != "" {
+			for _, w := range watchers {
+				if err := w.startPush(ctx, topic); err != nil {
+					return fmt.Errorf("[%s] %w", w.name, err)
+				}
+				defer w.client.StopWatch(context.Background())
+			}
+
+			server := &http.Server{
+				Addr:    listen,
+				Handler: pushHandler(watchers, pushToken),
+			}
+			go func() {
+				<-ctx.Done()
+				server.Shutdown(context.Background())
+			}()
+			go func() {
+				if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					output.PrintError("push listener: %v", err)
+					stop()
+				}
+			}()
+		}
+
+		if !output.JSONOutput {
+			output.PrintInfo("Watching %d account(s) for new mail. Press Ctrl+C to stop.", len(watchers))
+		}
+
+		var wg sync.WaitGroup
+		for _, w := range watchers {
+			wg.Add(1)
+			go func(w *mailWatcher) {
+				defer wg.Done()
+				w.run(ctx, interval, topic)
+			}(w)
+		}
+		wg.Wait()
+		return nil
+	},
+}
+
+// printMu serializes output from concurrent account watchers
+var printMu sync.Mutex
+
+// mailWatcher follows new mail for one account
+type mailWatcher struct {
+	client    *gmail.Client
+	name      string
+	email     string
+	historyID uint64
+	query     string
+	hook      string
+	// trigger requests an immediate check, used by push notifications
+	trigger chan struct{}
+}
+
+// run checks for new mail on every tick or push notification until ctx is
+// cancelled. Watches registered with Pub/Sub expire after 7 days, so they
+// are renewed daily.
+func (w *mailWatcher) run(ctx context.Context, interval time.Duration, topic string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	renew := time.NewTicker(24 * time.Hour)
+	defer renew.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-renew.C:
+			if topic != "" {
+				if _, _, err := w.client.Watch(ctx, topic); err != nil {
+					output.PrintError("[%s] %v", w.name, err)
+				}
+			}
+			continue
+		case <-ticker.C:
+		case <-w.trigger:
+		}
+
+		if err := w.check(ctx); err != nil && ctx.Err() == nil {
+			output.PrintError("[%s] %v", w.name, err)
+		}
+	}
+}
+
+// startPush registers the account with Pub/Sub
+func (w *mailWatcher) startPush(ctx context.Context, topic string) error {
+	historyID, expires, err := w.client.Watch(ctx, topic)
+	if err != nil {
+		return err
+	}
+	if historyID < w.historyID {
+		w.historyID = historyID
+	}
+	if !output.JSONOutput {
+		output.PrintInfo("[%s] Push notifications enabled until %s", w.name, expires.Local().Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// check reports messages that arrived since the last check
+func (w *mailWatcher) check(ctx context.Context) error {
+	ids, historyID, err := w.client.NewMessagesSince(ctx, w.historyID)
+	if errors.Is(err, gmail.ErrHistoryExpired) {
+		// Messages in the gap cannot be recovered; resume from now
+		_, historyID, err = w.client.GetProfile(ctx)
+		if err == nil {
+			w.historyID = historyID
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	w.historyID = historyID
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	for _, id := range ids {
+		if w.query != "" {
+			match, err := w.client.MatchesQuery(ctx, id, w.query)
+			if err != nil {
+				output.PrintError("[%s] %s: %v", w.name, id, err)
+				continue
+			}
+			if !match {
+				continue
+			}
+		}
+
+		if err := w.report(ctx, id); err != nil {
+			output.PrintError("[%s] %s: %v", w.name, id, err)
+		}
+	}
+	return nil
+}
+
+// report prints a new message and runs the hook for it
+func (w *mailWatcher) report(ctx context.Context, id string) error {
+	summary, err := w.client.GetMessageSummary(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	printMu.Lock()
+	if output.JSONOutput {
+		// One object per line so the stream can be consumed incrementally
+		data, _ := json.Marshal(summary)
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("%s  [%s] %s — %s\n",
+			summary.Date.Local().Format("2006-01-02 15:04"), w.name, summary.From, summary.Subject)
+	}
+	printMu.Unlock()
+
+	if w.hook == "" {
+		return nil
+	}
+
+	detail, err := w.client.GetMessage(ctx, id, gmail.BodyText)
+	if err != nil {
+		return err
+	}
+	return runMailHook(ctx, w.hook, w.name, detail)
+}
+
+// runMailHook runs a shell command with a message as JSON on stdin
+func runMailHook(ctx context.Context, hook, account string, detail output.EmailDetail) error {
+	data, err := json.Marshal(detail)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.CommandContext(ctx, "cmd", "/C", hook)
+	} else {
+		c = exec.CommandContext(ctx, "sh", "-c", hook)
+	}
+	c.Stdin = bytes.NewReader(data)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Env = append(os.Environ(),
+		"GCLI_ACCOUNT="+account,
+		"GCLI_MESSAGE_ID="+detail.ID,
+	)
+
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("hook failed: %w", err)
+	}
+	return nil
+}
+
+// pushHandler handles Pub/Sub push requests by triggering a check of the
+// account the notification is for
+func pushHandler(watchers []*mailWatcher, token string) http.Handler {
+	byEmail := make(map[string]*mailWatcher, len(watchers))
+	for _, w := range watchers {
+		byEmail[w.email] = w
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if token != "" && r.URL.Query().Get("token") != token {
+			http.Error(rw, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		var push struct {
+			Message struct {
+				Data string `json:"data"`
+			} `json:"message"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&push); err != nil {
+			http.Error(rw, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		data, err := base64.StdEncoding.DecodeString(push.Message.Data)
+		if err != nil {
+			http.Error(rw, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		var notification struct {
+			EmailAddress string `json:"emailAddress"`
+		}
+		if err := json.Unmarshal(data, &notification); err != nil {
+			http.Error(rw, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		// Acknowledge notifications for unknown addresses too, otherwise
+		// Pub/Sub keeps redelivering them
+		if w, ok := byEmail[notification.EmailAddress]; ok {
+			select {
+			case w.trigger <- struct{}{}:
+			default:
+				// A check is already pending
+			}
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func init() {
+	mailCmd.AddCommand(mailWatchCmd)
+
+	addAccountFlag(mailWatchCmd)
+	mailWatchCmd.Flags().Bool("all", false, "Watch all accounts")
+	mailWatchCmd.Flags().StringP("query", "q", "", "Only report emails matching this Gmail search query")
+	mailWatchCmd.Flags().Duration("interval", 30*time.Second, "How often to check for new mail")
+	mailWatchCmd.Flags().String("exec", "", "Shell command to run for each new email (message JSON on stdin)")
+	mailWatchCmd.Flags().String("topic", "", "Cloud Pub/Sub topic for push notifications (projects/<project>/topics/<topic>)")
+	mailWatchCmd.Flags().String("listen", ":8080", "Address to receive Pub/Sub push requests on (with --topic)")
+	mailWatchCmd.Flags().String("push-token", "", "Secret expected in the push URL's token query parameter")
+}
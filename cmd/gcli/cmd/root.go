@@ -1,6 +1,12 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/gmail"
 	"github.com/alexandraswan/gcli/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -37,3 +43,46 @@ func Execute() error {
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
 }
+
+// addAccountFlag adds the --account flag shared by most commands
+func addAccountFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("account", "a", "", "Account to use (default: default account)")
+}
+
+// resolveAccounts returns the account names selected by --account/--all
+func resolveAccounts(cfg *config.Config, accountName string, allAccounts bool) ([]string, error) {
+	if !cfg.HasAccounts() {
+		return nil, fmt.Errorf("no accounts configured. Run 'gcli auth add <name>' first")
+	}
+
+	if allAccounts {
+		accounts := cfg.GetAllAccounts()
+		sort.Strings(accounts)
+		return accounts, nil
+	}
+
+	name, _, err := cfg.GetAccount(accountName)
+	if err != nil {
+		return nil, err
+	}
+	return []string{name}, nil
+}
+
+// newGmailClient creates a Gmail client for a configured account
+func newGmailClient(ctx context.Context, cfg *config.Config, name string) (*gmail.Client, error) {
+	_, acc, err := cfg.GetAccount(name)
+	if err != nil {
+		return nil, err
+	}
+	return gmail.NewClient(ctx, name, acc)
+}
+
+// newGmailSharingClient creates a Gmail client that can also manage
+// delegates and forwarding for a configured account
+func newGmailSharingClient(ctx context.Context, cfg *config.Config, name string) (*gmail.Client, error) {
+	_, acc, err := cfg.GetAccount(name)
+	if err != nil {
+		return nil, err
+	}
+	return gmail.NewSharingClient(ctx, name, acc)
+}
@@ -6,26 +6,47 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/alexandraswan/gcli/internal/config"
 	"github.com/pkg/browser"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/jwt"
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/people/v1"
 )
 
-// Scopes required for Gmail and Calendar access
-var Scopes = []string{
+// GmailScopes are required for Gmail access
+var GmailScopes = []string{
 	gmail.GmailReadonlyScope,
 	gmail.GmailComposeScope,
 	gmail.GmailSendScope,
 	gmail.GmailModifyScope,
+	gmail.GmailSettingsBasicScope,
+}
+
+// GmailSharingScopes are required for managing delegates and forwarding,
+// which Gmail only allows service accounts with domain-wide delegation to do
+var GmailSharingScopes = slices.Concat(GmailScopes, []string{gmail.GmailSettingsSharingScope})
+
+// CalendarScopes are required for Calendar access
+var CalendarScopes = []string{
 	calendar.CalendarReadonlyScope,
 	calendar.CalendarEventsScope,
 }
 
+// ContactsScopes are required for Contacts access
+var ContactsScopes = []string{
+	people.ContactsScope,
+}
+
+// Scopes are requested when signing in, so one token covers Gmail,
+// Calendar and Contacts
+var Scopes = slices.Concat(GmailScopes, CalendarScopes, ContactsScopes)
+
 // GetOAuthConfig creates an OAuth2 config for the given account
 func GetOAuthConfig(account config.AccountConfig) *oauth2.Config {
 	return &oauth2.Config{
@@ -93,8 +114,14 @@ func TokenExists(accountName string) bool {
 	return err == nil
 }
 
-// GetClient returns an authenticated HTTP client for the specified account
-func GetClient(ctx context.Context, accountName string, account config.AccountConfig) (*http.Client, error) {
+// GetClient returns an authenticated HTTP client for the specified account.
+// Service accounts request only the given scopes, so their domain-wide
+// delegation needs to cover just the services that are used.
+func GetClient(ctx context.Context, accountName string, account config.AccountConfig, scopes []string) (*http.Client, error) {
+	if account.IsServiceAccount() {
+		return serviceAccountClient(ctx, account, scopes)
+	}
+
 	oauthConfig := GetOAuthConfig(account)
 
 	token, err := LoadToken(accountName)
@@ -122,6 +149,36 @@ func GetClient(ctx context.Context, accountName string, account config.AccountCo
 	return oauthConfig.Client(ctx, newToken), nil
 }
 
+// serviceAccountClient returns an HTTP client that acts as the account's
+// subject using a service account with domain-wide delegation
+func serviceAccountClient(ctx context.Context, account config.AccountConfig, scopes []string) (*http.Client, error) {
+	jwtConfig, err := LoadServiceAccount(account, scopes)
+	if err != nil {
+		return nil, err
+	}
+	return jwtConfig.Client(ctx), nil
+}
+
+// LoadServiceAccount reads the account's service account key file for
+// requesting the given scopes
+func LoadServiceAccount(account config.AccountConfig, scopes []string) (*jwt.Config, error) {
+	if account.Subject == "" {
+		return nil, fmt.Errorf("service account accounts need a subject (the user to act as)")
+	}
+
+	data, err := os.ReadFile(account.ServiceAccountKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account key: %w", err)
+	}
+
+	jwtConfig, err := google.JWTConfigFromJSON(data, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse service account key: %w", err)
+	}
+	jwtConfig.Subject = account.Subject
+	return jwtConfig, nil
+}
+
 // AuthenticateAccount performs the OAuth flow for a new account
 func AuthenticateAccount(accountName string, account config.AccountConfig) error {
 	oauthConfig := GetOAuthConfig(account)
@@ -3,6 +3,7 @@ package calendar
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/alexandraswan/gcli/internal/auth"
@@ -21,7 +22,7 @@ type Client struct {
 
 // NewClient creates a new Calendar client for the specified account
 func NewClient(ctx context.Context, accountName string, account config.AccountConfig) (*Client, error) {
-	httpClient, err := auth.GetClient(ctx, accountName, account)
+	httpClient, err := auth.GetClient(ctx, accountName, account, auth.CalendarScopes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get authenticated client: %w", err)
 	}
@@ -93,6 +94,10 @@ type EventInput struct {
 	End         time.Time
 	AllDay      bool
 	Attendees   []string
+	// OutOfOffice creates an out-of-office event that declines new
+	// invitations during it, with DeclineMessage as the reply
+	OutOfOffice    bool
+	DeclineMessage string
 }
 
 // CreateEvent creates a new calendar event
@@ -129,6 +134,14 @@ func (c *Client) CreateEvent(ctx context.Context, input EventInput) (string, err
 		}
 	}
 
+	if input.OutOfOffice {
+		event.EventType = "outOfOffice"
+		event.OutOfOfficeProperties = &calendar.EventOutOfOfficeProperties{
+			AutoDeclineMode: "declineOnlyNewConflictingInvitations",
+			DeclineMessage:  input.DeclineMessage,
+		}
+	}
+
 	resp, err := c.service.Events.Insert(c.calendarID, event).Context(ctx).Do()
 	if err != nil {
 		return "", fmt.Errorf("failed to create event: %w", err)
@@ -345,3 +358,31 @@ type CalendarInfo struct {
 	Description string `json:"description,omitempty"`
 	Primary     bool   `json:"primary"`
 }
+
+// FindConflicts returns pairs of timed events that overlap, across all the
+// given calendars. All-day and cancelled events are skipped, as are copies
+// of the same meeting on several calendars (same summary and times).
+func FindConflicts(events []output.CalendarEventSummary) []output.EventConflict {
+	var timed []output.CalendarEventSummary
+	for _, e := range events {
+		if !e.AllDay && e.Status != "cancelled" && e.End.After(e.Start) {
+			timed = append(timed, e)
+		}
+	}
+	sort.SliceStable(timed, func(i, j int) bool { return timed[i].Start.Before(timed[j].Start) })
+
+	var conflicts []output.EventConflict
+	for i, a := range timed {
+		for _, b := range timed[i+1:] {
+			if !b.Start.Before(a.End) {
+				break
+			}
+			if a.Summary == b.Summary && a.Start.Equal(b.Start) && a.End.Equal(b.End) {
+				// The same meeting on more than one calendar
+				continue
+			}
+			conflicts = append(conflicts, output.EventConflict{First: a, Second: b})
+		}
+	}
+	return conflicts
+}
@@ -18,6 +18,17 @@ type AccountConfig struct {
 	ClientID     string `json:"client_id"`
 	ClientSecret string `json:"client_secret"`
 	CalendarID   string `json:"calendar_id,omitempty"`
+	// ServiceAccountKey is the path to a service account key file with
+	// domain-wide delegation. When set, the account acts as Subject
+	// instead of using an OAuth token.
+	ServiceAccountKey string `json:"service_account_key,omitempty"`
+	Subject           string `json:"subject,omitempty"`
+}
+
+// IsServiceAccount reports whether the account authenticates with a
+// service account key
+func (a AccountConfig) IsServiceAccount() bool {
+	return a.ServiceAccountKey != ""
 }
 
 // Config holds the overall configuration
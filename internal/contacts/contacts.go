@@ -0,0 +1,337 @@
+package contacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/auth"
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/output"
+	"google.golang.org/api/option"
+	"google.golang.org/api/people/v1"
+)
+
+// personFields are the contact fields read and written by gcli
+const personFields = "names,emailAddresses,phoneNumbers,addresses,urls,organizations,birthdays,biographies"
+
+// Client wraps the People API client
+type Client struct {
+	service     *people.Service
+	accountName string
+}
+
+// ContactInput contains the fields for creating or updating a contact. On
+// update, empty fields and nil lists are left unchanged.
+type ContactInput struct {
+	// Name is a full name; GivenName and FamilyName take precedence
+	Name         string
+	GivenName    string
+	FamilyName   string
+	Emails       []output.ContactField
+	Phones       []output.ContactField
+	Addresses    []output.ContactAddress
+	URLs         []output.ContactField
+	Organization string
+	Title        string
+	Birthday     string
+	Notes        string
+}
+
+// InputFromContact returns the fields of a contact for creating a copy of
+// it
+func InputFromContact(c output.Contact) ContactInput {
+	return ContactInput{
+		Name:         c.Name,
+		GivenName:    c.GivenName,
+		FamilyName:   c.FamilyName,
+		Emails:       c.Emails,
+		Phones:       c.Phones,
+		Addresses:    c.Addresses,
+		URLs:         c.URLs,
+		Organization: c.Organization,
+		Title:        c.Title,
+		Birthday:     c.Birthday,
+		Notes:        c.Notes,
+	}
+}
+
+// NewClient creates a new People client for the specified account
+func NewClient(ctx context.Context, accountName string, account config.AccountConfig) (*Client, error) {
+	httpClient, err := auth.GetClient(ctx, accountName, account, auth.ContactsScopes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authenticated client: %w", err)
+	}
+
+	service, err := people.NewService(ctx, option.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create People service: %w", err)
+	}
+
+	return &Client{
+		service:     service,
+		accountName: accountName,
+	}, nil
+}
+
+// ListContacts lists the account's contacts, sorted by first name. A limit
+// of 0 lists all of them.
+func (c *Client) ListContacts(ctx context.Context, limit int) ([]output.Contact, error) {
+	var contacts []output.Contact
+	err := c.service.People.Connections.List("people/me").
+		PersonFields(personFields).
+		SortOrder("FIRST_NAME_ASCENDING").
+		PageSize(1000).
+		Pages(ctx, func(resp *people.ListConnectionsResponse) error {
+			for _, p := range resp.Connections {
+				if limit > 0 && len(contacts) >= limit {
+					return errLimitReached
+				}
+				contacts = append(contacts, c.toContact(p))
+			}
+			return nil
+		})
+	if err != nil && !errors.Is(err, errLimitReached) {
+		return nil, fmt.Errorf("failed to list contacts: %w", err)
+	}
+	return contacts, nil
+}
+
+// errLimitReached stops paging once enough contacts have been listed
+var errLimitReached = errors.New("limit reached")
+
+// SearchContacts finds contacts whose names, email addresses, phone numbers
+// or organizations start with the query
+func (c *Client) SearchContacts(ctx context.Context, query string) ([]output.Contact, error) {
+	// The search cache is only updated by a request with an empty query
+	// (see the People API documentation)
+	if _, err := c.service.People.SearchContacts().Query("").ReadMask(personFields).Context(ctx).Do(); err != nil {
+		return nil, fmt.Errorf("failed to search contacts: %w", err)
+	}
+
+	resp, err := c.service.People.SearchContacts().
+		Query(query).
+		ReadMask(personFields).
+		PageSize(30).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search contacts: %w", err)
+	}
+
+	var contacts []output.Contact
+	for _, r := range resp.Results {
+		contacts = append(contacts, c.toContact(r.Person))
+	}
+	return contacts, nil
+}
+
+// GetContact gets a contact by ID
+func (c *Client) GetContact(ctx context.Context, id string) (output.Contact, error) {
+	p, err := c.service.People.Get(resourceName(id)).PersonFields(personFields).Context(ctx).Do()
+	if err != nil {
+		return output.Contact{}, fmt.Errorf("failed to get contact: %w", err)
+	}
+	return c.toContact(p), nil
+}
+
+// CreateContact creates a contact
+func (c *Client) CreateContact(ctx context.Context, input ContactInput) (output.Contact, error) {
+	p := &people.Person{}
+	if err := applyInput(p, input); err != nil {
+		return output.Contact{}, err
+	}
+
+	created, err := c.service.People.CreateContact(p).PersonFields(personFields).Context(ctx).Do()
+	if err != nil {
+		return output.Contact{}, fmt.Errorf("failed to create contact: %w", err)
+	}
+	return c.toContact(created), nil
+}
+
+// UpdateContact changes the given fields of a contact
+func (c *Client) UpdateContact(ctx context.Context, id string, input ContactInput) (output.Contact, error) {
+	p, err := c.service.People.Get(resourceName(id)).PersonFields(personFields).Context(ctx).Do()
+	if err != nil {
+		return output.Contact{}, fmt.Errorf("failed to get contact: %w", err)
+	}
+	if err := applyInput(p, input); err != nil {
+		return output.Contact{}, err
+	}
+
+	updated, err := c.service.People.UpdateContact(p.ResourceName, p).
+		UpdatePersonFields(personFields).
+		PersonFields(personFields).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return output.Contact{}, fmt.Errorf("failed to update contact: %w", err)
+	}
+	return c.toContact(updated), nil
+}
+
+// DeleteContact deletes a contact
+func (c *Client) DeleteContact(ctx context.Context, id string) error {
+	if _, err := c.service.People.DeleteContact(resourceName(id)).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to delete contact: %w", err)
+	}
+	return nil
+}
+
+// resourceName turns a contact ID into a People API resource name
+func resourceName(id string) string {
+	if strings.HasPrefix(id, "people/") {
+		return id
+	}
+	return "people/" + id
+}
+
+// toContact converts a People API person to a contact for display
+func (c *Client) toContact(p *people.Person) output.Contact {
+	contact := output.Contact{
+		Account: c.accountName,
+		ID:      strings.TrimPrefix(p.ResourceName, "people/"),
+	}
+	if len(p.Names) > 0 {
+		contact.Name = p.Names[0].DisplayName
+		contact.GivenName = p.Names[0].GivenName
+		contact.FamilyName = p.Names[0].FamilyName
+	}
+	for _, e := range p.EmailAddresses {
+		contact.Emails = append(contact.Emails, output.ContactField{Value: e.Value, Type: e.Type})
+	}
+	for _, ph := range p.PhoneNumbers {
+		contact.Phones = append(contact.Phones, output.ContactField{Value: ph.Value, Type: ph.Type})
+	}
+	for _, a := range p.Addresses {
+		contact.Addresses = append(contact.Addresses, output.ContactAddress{
+			Type:       a.Type,
+			Street:     a.StreetAddress,
+			City:       a.City,
+			Region:     a.Region,
+			PostalCode: a.PostalCode,
+			Country:    a.Country,
+			Formatted:  a.FormattedValue,
+		})
+	}
+	for _, u := range p.Urls {
+		contact.URLs = append(contact.URLs, output.ContactField{Value: u.Value, Type: u.Type})
+	}
+	if len(p.Organizations) > 0 {
+		contact.Organization = p.Organizations[0].Name
+		contact.Title = p.Organizations[0].Title
+	}
+	if len(p.Birthdays) > 0 {
+		contact.Birthday = formatBirthday(p.Birthdays[0])
+	}
+	if len(p.Biographies) > 0 {
+		contact.Notes = p.Biographies[0].Value
+	}
+	return contact
+}
+
+// formatBirthday formats a birthday as YYYY-MM-DD, or --MM-DD without a
+// year
+func formatBirthday(b *people.Birthday) string {
+	if b.Date == nil {
+		return b.Text
+	}
+	if b.Date.Year == 0 {
+		return fmt.Sprintf("--%02d-%02d", b.Date.Month, b.Date.Day)
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", b.Date.Year, b.Date.Month, b.Date.Day)
+}
+
+// parseBirthday parses a birthday written by formatBirthday
+func parseBirthday(s string) (*people.Date, error) {
+	var year, month, day int64
+	if _, err := fmt.Sscanf(s, "--%02d-%02d", &month, &day); err != nil {
+		if _, err := fmt.Sscanf(s, "%04d-%02d-%02d", &year, &month, &day); err != nil {
+			return nil, fmt.Errorf("invalid birthday '%s' (use YYYY-MM-DD or --MM-DD)", s)
+		}
+	}
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return nil, fmt.Errorf("invalid birthday '%s' (use YYYY-MM-DD or --MM-DD)", s)
+	}
+	return &people.Date{Year: year, Month: month, Day: day}, nil
+}
+
+// applyInput sets the non-empty fields of input on a person
+func applyInput(p *people.Person, input ContactInput) error {
+	switch {
+	case input.GivenName != "" || input.FamilyName != "":
+		p.Names = []*people.Name{{GivenName: input.GivenName, FamilyName: input.FamilyName}}
+	case input.Name != "":
+		// Gmail splits a free-form name into its parts
+		p.Names = []*people.Name{{UnstructuredName: input.Name}}
+	}
+
+	if input.Emails != nil {
+		p.EmailAddresses = nil
+		for _, e := range input.Emails {
+			p.EmailAddresses = append(p.EmailAddresses, &people.EmailAddress{Value: e.Value, Type: e.Type})
+		}
+	}
+	if input.Phones != nil {
+		p.PhoneNumbers = nil
+		for _, ph := range input.Phones {
+			p.PhoneNumbers = append(p.PhoneNumbers, &people.PhoneNumber{Value: ph.Value, Type: ph.Type})
+		}
+	}
+
+	if input.Addresses != nil {
+		p.Addresses = nil
+		for _, a := range input.Addresses {
+			p.Addresses = append(p.Addresses, &people.Address{
+				Type:           a.Type,
+				StreetAddress:  a.Street,
+				City:           a.City,
+				Region:         a.Region,
+				PostalCode:     a.PostalCode,
+				Country:        a.Country,
+				FormattedValue: a.Formatted,
+			})
+		}
+	}
+	if input.URLs != nil {
+		p.Urls = nil
+		for _, u := range input.URLs {
+			p.Urls = append(p.Urls, &people.Url{Value: u.Value, Type: u.Type})
+		}
+	}
+
+	if input.Organization != "" || input.Title != "" {
+		org := &people.Organization{}
+		if len(p.Organizations) > 0 {
+			org = p.Organizations[0]
+		}
+		if input.Organization != "" {
+			org.Name = input.Organization
+		}
+		if input.Title != "" {
+			org.Title = input.Title
+		}
+		if len(p.Organizations) == 0 {
+			p.Organizations = append(p.Organizations, org)
+		}
+	}
+
+	if input.Notes != "" {
+		if len(p.Biographies) > 0 {
+			p.Biographies[0].Value = input.Notes
+			p.Biographies[0].ContentType = "TEXT_PLAIN"
+		} else {
+			p.Biographies = append(p.Biographies, &people.Biography{Value: input.Notes, ContentType: "TEXT_PLAIN"})
+		}
+	}
+
+	if input.Birthday != "" {
+		date, err := parseBirthday(input.Birthday)
+		if err != nil {
+			return err
+		}
+		p.Birthdays = []*people.Birthday{{Date: date}}
+	}
+	return nil
+}
@@ -0,0 +1,369 @@
+package contacts
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alexandraswan/gcli/internal/output"
+)
+
+// sampleContacts covers every field the vCard and CSV formats carry
+var sampleContacts = []output.Contact{
+	{
+		Name:       "Jane Doe",
+		GivenName:  "Jane",
+		FamilyName: "Doe",
+		Emails: []output.ContactField{
+			{Value: "jane@example.com", Type: "work"},
+			{Value: "jane@home.example"},
+		},
+		Phones: []output.ContactField{
+			{Value: "+1 555 123 4567", Type: "mobile"},
+			{Value: "+1 555 765 4321", Type: "home"},
+		},
+		Addresses: []output.ContactAddress{
+			{Type: "work", Street: "1 Main St", City: "Springfield", Region: "IL", PostalCode: "62701", Country: "US"},
+		},
+		URLs:         []output.ContactField{{Value: "https://jane.example/?a=1;b=2", Type: "blog"}},
+		Organization: "Example, Inc",
+		Title:        "CTO; Founder",
+		Birthday:     "1980-05-17",
+		Notes:        "Likes tea\nnot coffee \\ ever",
+	},
+	{
+		Name:      "Jürgen Müller",
+		GivenName: "Jürgen",
+		Emails:    []output.ContactField{{Value: "juergen@example.de"}},
+		Birthday:  "--12-24",
+		Notes:     strings.Repeat("Grüße aus Berlin, ", 20) + "Jürgen",
+	},
+}
+
+func TestVCardRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteVCards(&buf, sampleContacts); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line %d is %d octets long, want at most 75: %q", i+1, len(line), line)
+		}
+	}
+
+	got, err := ReadVCards(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, sampleContacts) {
+		t.Errorf("ReadVCards(WriteVCards()) =\n%+v\nwant\n%+v", got, sampleContacts)
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	list := append([]output.Contact{}, sampleContacts...)
+	list[0].Addresses = append(list[0].Addresses, output.ContactAddress{Type: "home", Formatted: "Flat 2\n10 High St\nLondon"})
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, list); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, list) {
+		t.Errorf("ReadCSV(WriteCSV()) =\n%+v\nwant\n%+v", got, list)
+	}
+}
+
+func TestReadFixtures(t *testing.T) {
+	tests := []struct {
+		file string
+		want []output.Contact
+	}{
+		{
+			file: "google.csv",
+			want: []output.Contact{
+				{
+					GivenName:  "Jane",
+					FamilyName: "Doe",
+					Emails: []output.ContactField{
+						{Value: "jane@example.com", Type: "work"},
+						{Value: "jane.doe@example.com", Type: "work"},
+						{Value: "jane@home.example", Type: "home"},
+					},
+					Phones: []output.ContactField{{Value: "+1 555 123 4567", Type: "mobile"}},
+					Addresses: []output.ContactAddress{{
+						Type:       "work",
+						Street:     "1 Main St",
+						City:       "Springfield",
+						Region:     "IL",
+						PostalCode: "62701",
+						Country:    "US",
+						Formatted:  "1 Main St\nSpringfield, IL 62701",
+					}},
+					URLs:         []output.ContactField{{Value: "https://jane.example", Type: "blog"}},
+					Organization: "Example Inc",
+					Title:        "CTO",
+					Birthday:     "--05-17",
+					Notes:        "Likes tea, not coffee",
+				},
+				{
+					GivenName: "Bob",
+					Phones:    []output.ContactField{{Value: "555-987-6543", Type: "home"}},
+					Birthday:  "1975-01-02",
+				},
+			},
+		},
+		{
+			file: "outlook.csv",
+			want: []output.Contact{{
+				GivenName:  "Maria",
+				FamilyName: "Garcia",
+				Emails: []output.ContactField{
+					{Value: "maria@contoso.com"},
+					{Value: "maria.garcia@example.org"},
+				},
+				Phones: []output.ContactField{
+					{Value: "+1 425 555 0100", Type: "work"},
+					{Value: "+1 425 555 0199", Type: "mobile"},
+				},
+				URLs:         []output.ContactField{{Value: "https://contoso.com"}},
+				Organization: "Contoso Ltd",
+				Title:        "Controller",
+				Notes:        "Quarterly reviews",
+			}},
+		},
+		{
+			file: "vcard21.vcf",
+			want: []output.Contact{
+				{
+					Name:       "Jürgen Müller",
+					GivenName:  "Jürgen",
+					FamilyName: "Müller",
+					Emails:     []output.ContactField{{Value: "juergen@example.de"}},
+					Phones: []output.ContactField{
+						{Value: "+49 171 1234567", Type: "mobile"},
+						{Value: "+49 30 1234567", Type: "work"},
+					},
+					Addresses: []output.ContactAddress{
+						{Type: "home", Street: "Hauptstraße 1", City: "Berlin", PostalCode: "10115", Country: "Germany"},
+					},
+					Organization: "Beispiel GmbH",
+					Birthday:     "1980-05-17",
+					Notes:        "Met at the Berlin offsite\r\nPrefers email",
+				},
+				{
+					Name:       "Jane Doe",
+					GivenName:  "Jane",
+					FamilyName: "Doe",
+					Emails:     []output.ContactField{{Value: "jane@example.com", Type: "work"}},
+					URLs:       []output.ContactField{{Value: "https://example.com/jane"}},
+					Title:      "Head of Sales, EMEA",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			var got []output.Contact
+			if filepath.Ext(tt.file) == ".vcf" {
+				got, err = ReadVCards(f)
+			} else {
+				got, err = ReadCSV(f)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("contacts =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadVCardsErrors(t *testing.T) {
+	for _, data := range []string{
+		"FN:Jane Doe\r\n",
+		"BEGIN:VCARD\r\nFN:Jane Doe\r\n",
+	} {
+		if _, err := ReadVCards(strings.NewReader(data)); err == nil {
+			t.Errorf("ReadVCards(%q) succeeded, want error", data)
+		}
+	}
+}
+
+func TestPhoneKey(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+	}{
+		{"+1 (555) 123-4567", "5551234567"},
+		{"555.123.4567", "5551234567"},
+		{"+44 20 7946 0958", "2079460958"},
+		{"1234567", "1234567"},
+		{"112", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := phoneKey(tt.phone); got != tt.want {
+			t.Errorf("phoneKey(%q) = %q, want %q", tt.phone, got, tt.want)
+		}
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	jane := output.Contact{
+		ID:     "people/1",
+		Name:   "Jane Doe",
+		Emails: []output.ContactField{{Value: "Jane@Example.com"}},
+		Phones: []output.ContactField{{Value: "+1 (555) 123-4567"}},
+	}
+	bob := output.Contact{
+		ID:     "people/2",
+		Name:   "Bob",
+		Phones: []output.ContactField{{Value: "911"}},
+	}
+	m := NewMatcher([]output.Contact{jane, bob})
+
+	tests := []struct {
+		name    string
+		contact output.Contact
+		want    string
+	}{
+		{"email ignores case and spaces", output.Contact{Emails: []output.ContactField{{Value: " jane@example.COM "}}}, "people/1"},
+		{"phone ignores formatting and country code", output.Contact{Phones: []output.ContactField{{Value: "555-123-4567"}}}, "people/1"},
+		{"short numbers do not match", output.Contact{Phones: []output.ContactField{{Value: "911"}}}, ""},
+		{"name alone does not match", output.Contact{Name: "Jane Doe"}, ""},
+		{"different email", output.Contact{Emails: []output.ContactField{{Value: "jane@other.example"}}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := m.Find(tt.contact)
+			if ok != (tt.want != "") || got.ID != tt.want {
+				t.Errorf("Find() = %q, %v, want %q", got.ID, ok, tt.want)
+			}
+		})
+	}
+
+	t.Run("added contacts replace earlier versions", func(t *testing.T) {
+		updated := jane
+		updated.Name = "Jane Smith"
+		updated.Emails = append(updated.Emails, output.ContactField{Value: "jane.smith@example.com"})
+		m.Add(updated)
+
+		got, ok := m.Find(output.Contact{Emails: []output.ContactField{{Value: "jane.smith@example.com"}}})
+		if !ok || got.Name != "Jane Smith" {
+			t.Errorf("Find() by new email = %q, %v, want Jane Smith", got.Name, ok)
+		}
+		got, ok = m.Find(output.Contact{Emails: []output.ContactField{{Value: "jane@example.com"}}})
+		if !ok || got.Name != "Jane Smith" {
+			t.Errorf("Find() by old email = %q, %v, want Jane Smith", got.Name, ok)
+		}
+	})
+}
+
+func TestMerge(t *testing.T) {
+	existing := output.Contact{
+		ID:           "people/1",
+		Name:         "Jane Doe",
+		Emails:       []output.ContactField{{Value: "jane@example.com", Type: "work"}},
+		Phones:       []output.ContactField{{Value: "+1 555 123 4567", Type: "mobile"}, {Value: "911"}},
+		Addresses:    []output.ContactAddress{{Type: "work", Street: "1 Main St", City: "Springfield"}},
+		Organization: "Example Inc",
+	}
+
+	tests := []struct {
+		name     string
+		existing output.Contact
+		incoming output.Contact
+		added    []string
+		want     output.Contact
+		input    ContactInput
+	}{
+		{
+			name:     "nothing new",
+			existing: existing,
+			incoming: output.Contact{
+				Name:         "J. Doe",
+				Emails:       []output.ContactField{{Value: "JANE@example.com", Type: "home"}},
+				Phones:       []output.ContactField{{Value: "(555) 123-4567"}, {Value: "911"}},
+				Addresses:    []output.ContactAddress{{Type: "home", Street: "1  Main St", City: "springfield"}},
+				Organization: "Other Corp",
+			},
+			want: existing,
+		},
+		{
+			name:     "empty fields are filled but set ones are kept",
+			existing: existing,
+			incoming: output.Contact{Organization: "Other Corp", Title: "CTO", Birthday: "--05-17", Notes: "Met at a conference"},
+			added:    []string{"title", "birthday", "notes"},
+			want: func() output.Contact {
+				c := existing
+				c.Title, c.Birthday, c.Notes = "CTO", "--05-17", "Met at a conference"
+				return c
+			}(),
+			input: ContactInput{Title: "CTO", Birthday: "--05-17", Notes: "Met at a conference"},
+		},
+		{
+			name:     "name only when the existing contact has none",
+			existing: output.Contact{ID: "people/2", Emails: []output.ContactField{{Value: "bob@example.com"}}},
+			incoming: output.Contact{GivenName: "Bob", FamilyName: "Smith"},
+			added:    []string{"name"},
+			want:     output.Contact{ID: "people/2", GivenName: "Bob", FamilyName: "Smith", Emails: []output.ContactField{{Value: "bob@example.com"}}},
+			input:    ContactInput{GivenName: "Bob", FamilyName: "Smith"},
+		},
+		{
+			name:     "new emails, phones, addresses and websites are appended",
+			existing: existing,
+			incoming: output.Contact{
+				Emails:    []output.ContactField{{Value: "jane@example.com"}, {Value: "jane@home.example"}, {Value: "j@home.example"}},
+				Phones:    []output.ContactField{{Value: "112"}},
+				Addresses: []output.ContactAddress{{Street: "2 Side St", City: "Springfield"}},
+				URLs:      []output.ContactField{{Value: "https://jane.example"}},
+			},
+			added: []string{"2 emails", "1 phone", "1 website", "1 address"},
+			want: func() output.Contact {
+				c := existing
+				c.Emails = []output.ContactField{{Value: "jane@example.com", Type: "work"}, {Value: "jane@home.example"}, {Value: "j@home.example"}}
+				c.Phones = []output.ContactField{{Value: "+1 555 123 4567", Type: "mobile"}, {Value: "911"}, {Value: "112"}}
+				c.Addresses = []output.ContactAddress{{Type: "work", Street: "1 Main St", City: "Springfield"}, {Street: "2 Side St", City: "Springfield"}}
+				c.URLs = []output.ContactField{{Value: "https://jane.example"}}
+				return c
+			}(),
+			input: ContactInput{
+				Emails:    []output.ContactField{{Value: "jane@example.com", Type: "work"}, {Value: "jane@home.example"}, {Value: "j@home.example"}},
+				Phones:    []output.ContactField{{Value: "+1 555 123 4567", Type: "mobile"}, {Value: "911"}, {Value: "112"}},
+				Addresses: []output.ContactAddress{{Type: "work", Street: "1 Main St", City: "Springfield"}, {Street: "2 Side St", City: "Springfield"}},
+				URLs:      []output.ContactField{{Value: "https://jane.example"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, input, added := Merge(tt.existing, tt.incoming)
+			if !reflect.DeepEqual(added, tt.added) {
+				t.Errorf("added = %v, want %v", added, tt.added)
+			}
+			if !reflect.DeepEqual(merged, tt.want) {
+				t.Errorf("merged =\n%+v\nwant\n%+v", merged, tt.want)
+			}
+			if !reflect.DeepEqual(input, tt.input) {
+				t.Errorf("input =\n%+v\nwant\n%+v", input, tt.input)
+			}
+		})
+	}
+}
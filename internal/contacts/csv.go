@@ -0,0 +1,293 @@
+package contacts
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/output"
+)
+
+// WriteCSV writes contacts as CSV with the column names of Google Contacts
+// exports, so the file can also be imported in the Google Contacts web app
+func WriteCSV(w io.Writer, list []output.Contact) error {
+	var emails, phones, addresses, urls int
+	for _, c := range list {
+		emails = max(emails, len(c.Emails))
+		phones = max(phones, len(c.Phones))
+		addresses = max(addresses, len(c.Addresses))
+		urls = max(urls, len(c.URLs))
+	}
+
+	header := []string{"Name", "Given Name", "Family Name", "Organization 1 - Name", "Organization 1 - Title", "Birthday", "Notes"}
+	for i := 1; i <= emails; i++ {
+		header = append(header, fmt.Sprintf("E-mail %d - Type", i), fmt.Sprintf("E-mail %d - Value", i))
+	}
+	for i := 1; i <= phones; i++ {
+		header = append(header, fmt.Sprintf("Phone %d - Type", i), fmt.Sprintf("Phone %d - Value", i))
+	}
+	for i := 1; i <= addresses; i++ {
+		for _, part := range []string{"Type", "Street", "City", "Region", "Postal Code", "Country", "Formatted"} {
+			header = append(header, fmt.Sprintf("Address %d - %s", i, part))
+		}
+	}
+	for i := 1; i <= urls; i++ {
+		header = append(header, fmt.Sprintf("Website %d - Type", i), fmt.Sprintf("Website %d - Value", i))
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for _, c := range list {
+		row := []string{c.Name, c.GivenName, c.FamilyName, c.Organization, c.Title, c.Birthday, c.Notes}
+		row = appendFields(row, c.Emails, emails)
+		row = appendFields(row, c.Phones, phones)
+		for i := range addresses {
+			var a output.ContactAddress
+			if i < len(c.Addresses) {
+				a = c.Addresses[i]
+			}
+			row = append(row, a.Type, a.Street, a.City, a.Region, a.PostalCode, a.Country, a.Formatted)
+		}
+		row = appendFields(row, c.URLs, urls)
+		cw.Write(row)
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// appendFields adds type and value columns for n fields to a CSV row
+func appendFields(row []string, fields []output.ContactField, n int) []string {
+	for i := range n {
+		var f output.ContactField
+		if i < len(fields) {
+			f = fields[i]
+		}
+		row = append(row, f.Type, f.Value)
+	}
+	return row
+}
+
+// csvColumn matches numbered columns such as "E-mail 1 - Value" (Google)
+// and "E-mail 2 Address" (Outlook)
+var csvColumn = regexp.MustCompile(`^(e-?mail|phone|address|website)\s*(\d+)\s*-?\s*(.*)$`)
+
+// csvAliases maps other column names used by Google, Outlook and Apple
+// exports to their Google equivalent, in lower case
+var csvAliases = map[string]string{
+	"full name":          "name",
+	"display name":       "name",
+	"first name":         "given name",
+	"last name":          "family name",
+	"surname":            "family name",
+	"company":            "organization 1 - name",
+	"organization":       "organization 1 - name",
+	"organization name":  "organization 1 - name",
+	"organization title": "organization 1 - title",
+	"job title":          "organization 1 - title",
+	"title":              "organization 1 - title",
+	"note":               "notes",
+	"email":              "e-mail 1 - value",
+	"e-mail":             "e-mail 1 - value",
+	"email address":      "e-mail 1 - value",
+	"e-mail address":     "e-mail 1 - value",
+	"phone":              "phone 1 - value",
+	"phone number":       "phone 1 - value",
+	"mobile phone":       "phone mobile",
+	"home phone":         "phone home",
+	"business phone":     "phone work",
+	"web page":           "website 1 - value",
+	"website":            "website 1 - value",
+}
+
+// ReadCSV reads contacts from a CSV file with a header row. Column names
+// of Google Contacts, Outlook and most other exports are recognized; other
+// columns are ignored.
+func ReadCSV(r io.Reader) ([]output.Contact, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		if alias, ok := csvAliases[h]; ok {
+			h = alias
+		}
+		header[i] = h
+	}
+
+	var list []output.Contact
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV line %d: %w", line, err)
+		}
+
+		c := csvContact(header, row)
+		if c.Name == "" && c.GivenName == "" && c.FamilyName == "" && len(c.Emails) == 0 && len(c.Phones) == 0 {
+			continue
+		}
+		list = append(list, c)
+	}
+	return list, nil
+}
+
+// csvContact maps a CSV row onto a contact
+func csvContact(header, row []string) output.Contact {
+	var c output.Contact
+	emails := make(map[int]*output.ContactField)
+	phones := make(map[int]*output.ContactField)
+	urls := make(map[int]*output.ContactField)
+	addresses := make(map[int]*output.ContactAddress)
+	type column struct {
+		kind string
+		n    int
+	}
+	var order []column
+
+	field := func(m map[int]*output.ContactField, kind string, n int) *output.ContactField {
+		if m[n] == nil {
+			m[n] = &output.ContactField{}
+			order = append(order, column{kind, n})
+		}
+		return m[n]
+	}
+
+	for i, h := range header {
+		if i >= len(row) {
+			break
+		}
+		v := strings.TrimSpace(row[i])
+		if v == "" {
+			continue
+		}
+
+		switch h {
+		case "name":
+			c.Name = v
+		case "given name":
+			c.GivenName = v
+		case "family name":
+			c.FamilyName = v
+		case "organization 1 - name":
+			c.Organization = v
+		case "organization 1 - title":
+			c.Title = v
+		case "birthday":
+			c.Birthday = normalizeBirthday(v)
+		case "notes":
+			c.Notes = v
+		case "phone mobile", "phone home", "phone work":
+			// Outlook has one column per phone type; number them after
+			// Google's numbered columns
+			f := field(phones, "phone", 100+i)
+			f.Type = strings.TrimPrefix(h, "phone ")
+			f.Value = v
+		default:
+			m := csvColumn.FindStringSubmatch(h)
+			if m == nil {
+				continue
+			}
+			n, _ := strconv.Atoi(m[2])
+			part := m[3]
+			switch m[1] {
+			case "e-mail", "email":
+				setCSVField(field(emails, "email", n), part, v)
+			case "phone":
+				setCSVField(field(phones, "phone", n), part, v)
+			case "website":
+				setCSVField(field(urls, "website", n), part, v)
+			case "address":
+				if addresses[n] == nil {
+					addresses[n] = &output.ContactAddress{}
+					order = append(order, column{"address", n})
+				}
+				setCSVAddress(addresses[n], part, v)
+			}
+		}
+	}
+
+	// Keep the fields in column order
+	for _, col := range order {
+		n := col.n
+		switch col.kind {
+		case "email":
+			c.Emails = appendCSVFields(c.Emails, emails[n])
+		case "phone":
+			c.Phones = appendCSVFields(c.Phones, phones[n])
+		case "website":
+			c.URLs = appendCSVFields(c.URLs, urls[n])
+		case "address":
+			if a := addresses[n]; *a != (output.ContactAddress{Type: a.Type}) {
+				c.Addresses = append(c.Addresses, *a)
+			}
+		}
+	}
+	return c
+}
+
+// setCSVField sets the type or value of a numbered field from its column
+func setCSVField(f *output.ContactField, part, v string) {
+	switch part {
+	case "type", "label":
+		f.Type = csvFieldType(v)
+	case "value", "address", "":
+		f.Value = v
+	}
+}
+
+// setCSVAddress sets part of a numbered address from its column
+func setCSVAddress(a *output.ContactAddress, part, v string) {
+	switch part {
+	case "type", "label":
+		a.Type = csvFieldType(v)
+	case "street":
+		a.Street = v
+	case "city":
+		a.City = v
+	case "region":
+		a.Region = v
+	case "postal code":
+		a.PostalCode = v
+	case "country":
+		a.Country = v
+	case "formatted":
+		a.Formatted = v
+	}
+}
+
+// csvFieldType cleans up a type from a Google export, where it can look
+// like "* Work" (primary) or "Home ::: Mobile" (several labels). Outlook's
+// email types (SMTP, EX) are how mail is delivered, not a type, and are
+// dropped.
+func csvFieldType(v string) string {
+	v, _, _ = strings.Cut(v, ":::")
+	v = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(v), "*")))
+	if v == "smtp" || v == "ex" {
+		return ""
+	}
+	return v
+}
+
+// appendCSVFields adds a field to a list, splitting values Google exports
+// joined with " ::: "
+func appendCSVFields(list []output.ContactField, f *output.ContactField) []output.ContactField {
+	for _, v := range strings.Split(f.Value, ":::") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, output.ContactField{Value: v, Type: f.Type})
+		}
+	}
+	return list
+}
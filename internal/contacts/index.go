@@ -0,0 +1,188 @@
+package contacts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/output"
+)
+
+const indexFileName = "contacts-index.json"
+
+// IndexMaxAge is how long an account's cached contacts are used before
+// they are fetched again
+const IndexMaxAge = 24 * time.Hour
+
+// Index caches each account's contact names and addresses so recipients
+// can be given by name
+type Index struct {
+	Accounts map[string]*AccountIndex `json:"accounts"`
+}
+
+// AccountIndex holds the cached contacts of one account
+type AccountIndex struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	Entries   []Entry   `json:"entries"`
+}
+
+// Entry is one email address of a contact
+type Entry struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email"`
+}
+
+// String formats the entry as a mail address, such as "Jane Doe <jane@example.com>"
+func (e Entry) String() string {
+	if e.Name == "" {
+		return e.Email
+	}
+	name := e.Name
+	// Names with punctuation must be quoted to parse as one address
+	if strings.ContainsAny(name, `()<>[]:;@\,."`) {
+		name = strconv.Quote(name)
+	}
+	return fmt.Sprintf("%s <%s>", name, e.Email)
+}
+
+// getIndexPath returns the path to the contacts index file
+func getIndexPath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, indexFileName), nil
+}
+
+// LoadIndex loads the contacts index
+func LoadIndex() (*Index, error) {
+	path, err := getIndexPath()
+	if err != nil {
+		return nil, err
+	}
+
+	index := &Index{Accounts: make(map[string]*AccountIndex)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+		return nil, fmt.Errorf("failed to read contacts index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("failed to parse contacts index: %w", err)
+	}
+	if index.Accounts == nil {
+		index.Accounts = make(map[string]*AccountIndex)
+	}
+
+	return index, nil
+}
+
+// SaveIndex saves the contacts index
+func SaveIndex(index *Index) error {
+	if err := config.EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	path, err := getIndexPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal contacts index: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write contacts index: %w", err)
+	}
+
+	return nil
+}
+
+// Fresh reports whether the account's contacts were cached less than
+// IndexMaxAge ago
+func (idx *Index) Fresh(account string, now time.Time) bool {
+	a, ok := idx.Accounts[account]
+	return ok && now.Sub(a.UpdatedAt) < IndexMaxAge
+}
+
+// Has reports whether the account's contacts have been cached
+func (idx *Index) Has(account string) bool {
+	_, ok := idx.Accounts[account]
+	return ok
+}
+
+// Update replaces the account's cached contacts
+func (idx *Index) Update(account string, contacts []output.Contact, now time.Time) {
+	a := &AccountIndex{UpdatedAt: now}
+	for _, c := range contacts {
+		for _, e := range c.Emails {
+			if e.Value != "" {
+				a.Entries = append(a.Entries, Entry{Name: c.Name, Email: e.Value})
+			}
+		}
+	}
+	idx.Accounts[account] = a
+}
+
+// Invalidate forgets the account's cached contacts so they are fetched
+// again on next use
+func (idx *Index) Invalidate(account string) {
+	delete(idx.Accounts, account)
+}
+
+// Lookup finds the account's contacts matching a name or address. Exact
+// matches of the full name or address win; otherwise a word of the name or
+// the start of the address must begin with the query.
+func (idx *Index) Lookup(account, query string) []Entry {
+	a, ok := idx.Accounts[account]
+	if !ok {
+		return nil
+	}
+
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return nil
+	}
+
+	var exact, partial []Entry
+	seen := make(map[string]bool)
+	for _, e := range a.Entries {
+		email := strings.ToLower(e.Email)
+		if seen[email] {
+			continue
+		}
+		name := strings.ToLower(e.Name)
+		local, _, _ := strings.Cut(email, "@")
+
+		switch {
+		case name == q || email == q:
+			exact = append(exact, e)
+		case strings.HasPrefix(name, q) || strings.HasPrefix(local, q):
+			partial = append(partial, e)
+		default:
+			for _, word := range strings.Fields(name) {
+				if strings.HasPrefix(word, q) {
+					partial = append(partial, e)
+					break
+				}
+			}
+		}
+		seen[email] = true
+	}
+
+	if len(exact) > 0 {
+		return exact
+	}
+	return partial
+}
@@ -0,0 +1,206 @@
+package contacts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/output"
+)
+
+// Matcher finds contacts that share an email address or phone number with
+// another contact
+type Matcher struct {
+	contacts []output.Contact
+	byEmail  map[string]int
+	byPhone  map[string]int
+}
+
+// NewMatcher indexes contacts for duplicate detection
+func NewMatcher(list []output.Contact) *Matcher {
+	m := &Matcher{
+		byEmail: make(map[string]int),
+		byPhone: make(map[string]int),
+	}
+	for _, c := range list {
+		m.Add(c)
+	}
+	return m
+}
+
+// Add indexes a contact, replacing an earlier version with the same ID
+func (m *Matcher) Add(c output.Contact) {
+	i := len(m.contacts)
+	for j, existing := range m.contacts {
+		if c.ID != "" && existing.ID == c.ID {
+			i = j
+			break
+		}
+	}
+	if i == len(m.contacts) {
+		m.contacts = append(m.contacts, c)
+	} else {
+		m.contacts[i] = c
+	}
+
+	for _, e := range c.Emails {
+		if key := emailKey(e.Value); key != "" {
+			m.byEmail[key] = i
+		}
+	}
+	for _, p := range c.Phones {
+		if key := phoneKey(p.Value); key != "" {
+			m.byPhone[key] = i
+		}
+	}
+}
+
+// Find returns the first indexed contact sharing an email address or phone
+// number with c
+func (m *Matcher) Find(c output.Contact) (output.Contact, bool) {
+	for _, e := range c.Emails {
+		if i, ok := m.byEmail[emailKey(e.Value)]; ok {
+			return m.contacts[i], true
+		}
+	}
+	for _, p := range c.Phones {
+		if key := phoneKey(p.Value); key != "" {
+			if i, ok := m.byPhone[key]; ok {
+				return m.contacts[i], true
+			}
+		}
+	}
+	return output.Contact{}, false
+}
+
+// emailKey normalizes an email address for comparison
+func emailKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// phoneKey normalizes a phone number for comparison: its digits, without
+// any country code when there are more than ten. Numbers too short to
+// identify anyone have no key.
+func phoneKey(phone string) string {
+	var digits []byte
+	for i := 0; i < len(phone); i++ {
+		if phone[i] >= '0' && phone[i] <= '9' {
+			digits = append(digits, phone[i])
+		}
+	}
+	if len(digits) < 7 {
+		return ""
+	}
+	if len(digits) > 10 {
+		digits = digits[len(digits)-10:]
+	}
+	return string(digits)
+}
+
+// Merge adds what an incoming contact has and an existing one lacks: fields
+// the existing contact leaves empty, and email addresses, phone numbers,
+// addresses and websites it does not have yet. It returns the merged
+// contact, the update that turns the existing contact into it and a
+// description of each addition; no additions means there is nothing to
+// update.
+func Merge(existing, incoming output.Contact) (output.Contact, ContactInput, []string) {
+	merged := existing
+	var input ContactInput
+	var added []string
+
+	if existing.Name == "" && existing.GivenName == "" && existing.FamilyName == "" &&
+		(incoming.Name != "" || incoming.GivenName != "" || incoming.FamilyName != "") {
+		merged.Name, merged.GivenName, merged.FamilyName = incoming.Name, incoming.GivenName, incoming.FamilyName
+		input.Name, input.GivenName, input.FamilyName = incoming.Name, incoming.GivenName, incoming.FamilyName
+		added = append(added, "name")
+	}
+	for _, f := range []struct {
+		label  string
+		want   string
+		merged *string
+		input  *string
+	}{
+		{"organization", incoming.Organization, &merged.Organization, &input.Organization},
+		{"title", incoming.Title, &merged.Title, &input.Title},
+		{"birthday", incoming.Birthday, &merged.Birthday, &input.Birthday},
+		{"notes", incoming.Notes, &merged.Notes, &input.Notes},
+	} {
+		if *f.merged == "" && f.want != "" {
+			*f.merged, *f.input = f.want, f.want
+			added = append(added, f.label)
+		}
+	}
+
+	if emails, n := mergeFields(existing.Emails, incoming.Emails, emailKey); n > 0 {
+		merged.Emails, input.Emails = emails, emails
+		added = append(added, plural(n, "email"))
+	}
+	if phones, n := mergeFields(existing.Phones, incoming.Phones, phoneOrValueKey); n > 0 {
+		merged.Phones, input.Phones = phones, phones
+		added = append(added, plural(n, "phone"))
+	}
+	if urls, n := mergeFields(existing.URLs, incoming.URLs, emailKey); n > 0 {
+		merged.URLs, input.URLs = urls, urls
+		added = append(added, plural(n, "website"))
+	}
+
+	have := make(map[string]bool)
+	for _, a := range existing.Addresses {
+		have[addressKey(a)] = true
+	}
+	addresses := append([]output.ContactAddress{}, existing.Addresses...)
+	for _, a := range incoming.Addresses {
+		if key := addressKey(a); !have[key] {
+			have[key] = true
+			addresses = append(addresses, a)
+		}
+	}
+	if n := len(addresses) - len(existing.Addresses); n > 0 {
+		merged.Addresses, input.Addresses = addresses, addresses
+		added = append(added, plural(n, "address"))
+	}
+
+	return merged, input, added
+}
+
+// mergeFields appends the incoming fields whose keys are not among the
+// existing ones, returning the combined list and how many were added
+func mergeFields(existing, incoming []output.ContactField, key func(string) string) ([]output.ContactField, int) {
+	have := make(map[string]bool, len(existing))
+	for _, f := range existing {
+		have[key(f.Value)] = true
+	}
+	merged := append([]output.ContactField{}, existing...)
+	for _, f := range incoming {
+		if k := key(f.Value); !have[k] {
+			have[k] = true
+			merged = append(merged, f)
+		}
+	}
+	return merged, len(merged) - len(existing)
+}
+
+// phoneOrValueKey compares phone numbers by phoneKey, or as written when
+// they are too short for it
+func phoneOrValueKey(phone string) string {
+	if key := phoneKey(phone); key != "" {
+		return key
+	}
+	return strings.TrimSpace(phone)
+}
+
+// addressKey normalizes an address for comparison
+func addressKey(a output.ContactAddress) string {
+	a.Type = ""
+	return strings.ToLower(strings.Join(strings.Fields(a.String()), " "))
+}
+
+// plural formats a count of things, such as "2 emails"
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	if strings.HasSuffix(noun, "s") {
+		return fmt.Sprintf("%d %ses", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,344 @@
+package contacts
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime/quotedprintable"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/output"
+)
+
+// WriteVCards writes contacts as vCard 3.0
+func WriteVCards(w io.Writer, list []output.Contact) error {
+	bw := bufio.NewWriter(w)
+	for _, c := range list {
+		writeVCardLine(bw, "BEGIN:VCARD")
+		writeVCardLine(bw, "VERSION:3.0")
+
+		name := c.Name
+		if name == "" && len(c.Emails) > 0 {
+			name = c.Emails[0].Value
+		}
+		writeVCardLine(bw, "FN:"+escapeVCard(name))
+		writeVCardLine(bw, "N:"+escapeVCard(c.FamilyName)+";"+escapeVCard(c.GivenName)+";;;")
+
+		for _, e := range c.Emails {
+			writeVCardLine(bw, "EMAIL"+vcardType(e.Type)+":"+escapeVCard(e.Value))
+		}
+		for _, p := range c.Phones {
+			writeVCardLine(bw, "TEL"+vcardType(p.Type)+":"+escapeVCard(p.Value))
+		}
+		for _, a := range c.Addresses {
+			street := a.Street
+			if street == "" && a.City == "" && a.Region == "" && a.PostalCode == "" && a.Country == "" {
+				street = a.Formatted
+			}
+			parts := []string{"", "", street, a.City, a.Region, a.PostalCode, a.Country}
+			for i, p := range parts {
+				parts[i] = escapeVCard(p)
+			}
+			writeVCardLine(bw, "ADR"+vcardType(a.Type)+":"+strings.Join(parts, ";"))
+		}
+		for _, u := range c.URLs {
+			writeVCardLine(bw, "URL"+vcardType(u.Type)+":"+escapeVCard(u.Value))
+		}
+		if c.Organization != "" {
+			writeVCardLine(bw, "ORG:"+escapeVCard(c.Organization))
+		}
+		if c.Title != "" {
+			writeVCardLine(bw, "TITLE:"+escapeVCard(c.Title))
+		}
+		if c.Birthday != "" {
+			writeVCardLine(bw, "BDAY:"+c.Birthday)
+		}
+		if c.Notes != "" {
+			writeVCardLine(bw, "NOTE:"+escapeVCard(c.Notes))
+		}
+
+		writeVCardLine(bw, "END:VCARD")
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write vCards: %w", err)
+	}
+	return nil
+}
+
+// vcardType formats a contact field type as a vCard TYPE parameter
+func vcardType(t string) string {
+	switch t {
+	case "":
+		return ""
+	case "mobile":
+		t = "cell"
+	}
+	return ";TYPE=" + t
+}
+
+// writeVCardLine writes a content line folded so that no line is longer
+// than 75 octets as RFC 6350 requires, counting the space continuation lines
+// start with, without splitting UTF-8 sequences
+func writeVCardLine(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	w.WriteString(line + "\r\n")
+}
+
+// escapeVCard escapes a vCard text value
+func escapeVCard(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// unescapeVCard reverses escapeVCard
+func unescapeVCard(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// splitVCard splits a structured vCard value on unescaped separators
+func splitVCard(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, unescapeVCard(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, unescapeVCard(s[start:]))
+}
+
+// vcardProperty is one content line of a vCard
+type vcardProperty struct {
+	name   string
+	params map[string][]string
+	value  string
+}
+
+// ReadVCards reads contacts from vCard 2.1, 3.0 or 4.0 data
+func ReadVCards(r io.Reader) ([]output.Contact, error) {
+	lines, err := unfoldVCardLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []output.Contact
+	var current *output.Contact
+	for n, line := range lines {
+		prop, ok := parseVCardLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VCARD"):
+			current = &output.Contact{}
+		case current == nil:
+			return nil, fmt.Errorf("line %d: %s outside of a vCard", n+1, prop.name)
+		case prop.name == "END" && strings.EqualFold(prop.value, "VCARD"):
+			list = append(list, *current)
+			current = nil
+		default:
+			applyVCardProperty(current, prop)
+		}
+	}
+	if current != nil {
+		return nil, fmt.Errorf("vCard is missing END:VCARD")
+	}
+	return list, nil
+}
+
+// unfoldVCardLines reads content lines, joining folded continuation lines
+// and quoted-printable soft line breaks
+func unfoldVCardLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		last := len(lines) - 1
+		switch {
+		case last >= 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")):
+			lines[last] += line[1:]
+		case last >= 0 && strings.HasSuffix(lines[last], "=") && isQuotedPrintable(lines[last]):
+			// vCard 2.1 continues quoted-printable values with a soft
+			// line break instead of folding
+			lines[last] += "\r\n" + line
+		default:
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read vCards: %w", err)
+	}
+	return lines, nil
+}
+
+// isQuotedPrintable reports whether a content line's value is
+// quoted-printable encoded
+func isQuotedPrintable(line string) bool {
+	params, _, _ := strings.Cut(line, ":")
+	return strings.Contains(strings.ToUpper(params), "QUOTED-PRINTABLE")
+}
+
+// parseVCardLine splits a content line into its name, parameters and
+// value, dropping any group prefix
+func parseVCardLine(line string) (vcardProperty, bool) {
+	head, value, ok := strings.Cut(line, ":")
+	if !ok || head == "" {
+		return vcardProperty{}, false
+	}
+
+	fields := strings.Split(head, ";")
+	name := strings.ToUpper(fields[0])
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+
+	prop := vcardProperty{name: name, params: make(map[string][]string), value: value}
+	for _, f := range fields[1:] {
+		key, val, hasValue := strings.Cut(f, "=")
+		if !hasValue {
+			// vCard 2.1 allows bare types such as TEL;WORK;VOICE
+			key, val = "TYPE", f
+		}
+		key = strings.ToUpper(key)
+		for _, v := range strings.Split(val, ",") {
+			prop.params[key] = append(prop.params[key], strings.Trim(v, `"`))
+		}
+	}
+
+	if enc := prop.params["ENCODING"]; len(enc) > 0 && strings.EqualFold(enc[0], "QUOTED-PRINTABLE") {
+		if data, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(prop.value))); err == nil {
+			prop.value = string(data)
+		}
+	}
+	return prop, true
+}
+
+// ignoredTypes are vCard types that say nothing about which address or
+// number it is
+var ignoredTypes = map[string]bool{
+	"pref": true, "internet": true, "voice": true, "x400": true, "text": true,
+}
+
+// vcardFieldType picks the most meaningful TYPE of a property, such as
+// home, work or mobile
+func vcardFieldType(prop vcardProperty) string {
+	for _, t := range prop.params["TYPE"] {
+		t = strings.ToLower(t)
+		if !ignoredTypes[t] && t != "" {
+			if t == "cell" {
+				return "mobile"
+			}
+			return t
+		}
+	}
+	return ""
+}
+
+// applyVCardProperty maps a vCard property onto a contact
+func applyVCardProperty(c *output.Contact, prop vcardProperty) {
+	switch prop.name {
+	case "FN":
+		c.Name = unescapeVCard(prop.value)
+	case "N":
+		parts := splitVCard(prop.value, ';')
+		c.FamilyName = parts[0]
+		if len(parts) > 1 {
+			c.GivenName = parts[1]
+		}
+	case "EMAIL":
+		if v := strings.TrimSpace(unescapeVCard(prop.value)); v != "" {
+			c.Emails = append(c.Emails, output.ContactField{Value: v, Type: vcardFieldType(prop)})
+		}
+	case "TEL":
+		v := strings.TrimPrefix(strings.TrimSpace(unescapeVCard(prop.value)), "tel:")
+		if v != "" {
+			c.Phones = append(c.Phones, output.ContactField{Value: v, Type: vcardFieldType(prop)})
+		}
+	case "ADR":
+		parts := splitVCard(prop.value, ';')
+		for len(parts) < 7 {
+			parts = append(parts, "")
+		}
+		// The post office box and extended address are kept on the street
+		// line
+		var street []string
+		for _, p := range parts[:3] {
+			if p = strings.TrimSpace(p); p != "" {
+				street = append(street, p)
+			}
+		}
+		a := output.ContactAddress{
+			Type:       vcardFieldType(prop),
+			Street:     strings.Join(street, ", "),
+			City:       parts[3],
+			Region:     parts[4],
+			PostalCode: parts[5],
+			Country:    parts[6],
+		}
+		if a != (output.ContactAddress{Type: a.Type}) {
+			c.Addresses = append(c.Addresses, a)
+		}
+	case "URL":
+		if v := strings.TrimSpace(unescapeVCard(prop.value)); v != "" {
+			c.URLs = append(c.URLs, output.ContactField{Value: v, Type: vcardFieldType(prop)})
+		}
+	case "ORG":
+		c.Organization = splitVCard(prop.value, ';')[0]
+	case "TITLE":
+		c.Title = unescapeVCard(prop.value)
+	case "BDAY":
+		c.Birthday = normalizeBirthday(prop.value)
+	case "NOTE":
+		c.Notes = unescapeVCard(prop.value)
+	}
+}
+
+// normalizeBirthday converts the date forms used in vCards and CSV files
+// (YYYY-MM-DD, YYYYMMDD, --MM-DD, --MMDD, with an optional time) to
+// YYYY-MM-DD or --MM-DD. Other values are dropped.
+func normalizeBirthday(s string) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "T")
+	digits := strings.ReplaceAll(s, "-", "")
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+
+	switch len(digits) {
+	case 4:
+		return "--" + digits[:2] + "-" + digits[2:]
+	case 8:
+		return digits[:4] + "-" + digits[4:6] + "-" + digits[6:]
+	}
+	return ""
+}
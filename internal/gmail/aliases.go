@@ -0,0 +1,108 @@
+package gmail
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/output"
+)
+
+// ListSendAs lists the addresses the account can send mail as, including
+// the primary address
+func (c *Client) ListSendAs(ctx context.Context) ([]output.SendAs, error) {
+	resp, err := c.service.Users.Settings.SendAs.List("me").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list send-as aliases: %w", err)
+	}
+
+	var aliases []output.SendAs
+	for _, s := range resp.SendAs {
+		aliases = append(aliases, output.SendAs{
+			Account:            c.accountName,
+			Email:              s.SendAsEmail,
+			DisplayName:        s.DisplayName,
+			ReplyTo:            s.ReplyToAddress,
+			IsDefault:          s.IsDefault,
+			IsPrimary:          s.IsPrimary,
+			VerificationStatus: s.VerificationStatus,
+			Signature:          s.Signature,
+		})
+	}
+	return aliases, nil
+}
+
+// ResolveSendAs finds the alias to send from. An empty address selects the
+// account's default alias. Aliases that are not verified are rejected.
+func (c *Client) ResolveSendAs(ctx context.Context, address string) (output.SendAs, error) {
+	aliases, err := c.ListSendAs(ctx)
+	if err != nil {
+		return output.SendAs{}, err
+	}
+
+	if address == "" {
+		for _, a := range aliases {
+			if a.IsDefault {
+				return a, nil
+			}
+		}
+		for _, a := range aliases {
+			if a.IsPrimary {
+				return a, nil
+			}
+		}
+		return output.SendAs{}, fmt.Errorf("no default send-as alias found")
+	}
+
+	// Accept "Name <address>" as well as a bare address
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		address = parsed.Address
+	}
+
+	for _, a := range aliases {
+		if !strings.EqualFold(a.Email, address) {
+			continue
+		}
+		if !a.Verified() {
+			return output.SendAs{}, fmt.Errorf("alias '%s' is not verified", a.Email)
+		}
+		return a, nil
+	}
+	return output.SendAs{}, fmt.Errorf("'%s' is not a send-as alias of this account (see 'gcli mail aliases list')", address)
+}
+
+// SetSender sets the From header to the alias and, when signature is set,
+// appends the alias's signature to the body. The signature goes into the
+// HTML part as configured and into the plain text part converted to text
+// after a "-- " separator.
+func (e *DraftEmail) SetSender(alias output.SendAs, signature bool) {
+	e.From = (&mail.Address{Name: alias.DisplayName, Address: alias.Email}).String()
+
+	if !signature || strings.TrimSpace(alias.Signature) == "" {
+		return
+	}
+
+	htmlSig := `<div class="gmail_signature">` + alias.Signature + `</div>`
+	textSig := "\n\n-- \n" + strings.TrimSpace(htmlToText(alias.Signature)) + "\n"
+
+	switch {
+	case e.HTMLBody != "":
+		e.HTMLBody = appendHTML(e.HTMLBody, htmlSig)
+		e.Body = strings.TrimRight(e.Body, "\n") + textSig
+	case e.IsHTML:
+		e.Body = appendHTML(e.Body, htmlSig)
+	default:
+		e.Body = strings.TrimRight(e.Body, "\n") + textSig
+	}
+}
+
+// appendHTML adds content at the end of an HTML document, inside its body
+// element when it has one
+func appendHTML(doc, content string) string {
+	content = "<br>" + content
+	if i := strings.LastIndex(strings.ToLower(doc), "</body>"); i >= 0 {
+		return doc[:i] + content + doc[i:]
+	}
+	return doc + content
+}
@@ -0,0 +1,316 @@
+package gmail
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/output"
+	"google.golang.org/api/gmail/v1"
+	_ "modernc.org/sqlite"
+)
+
+const cacheFileName = "cache.db"
+
+// ErrNotCached is returned when a message is not available in the cache
+var ErrNotCached = errors.New("message not in local cache")
+
+// cacheSchema creates the cache tables. Messages are stored as the Gmail API
+// JSON so summaries and details can be rebuilt exactly as when online.
+const cacheSchema = `
+CREATE TABLE IF NOT EXISTS accounts (
+	name       TEXT PRIMARY KEY,
+	history_id INTEGER NOT NULL,
+	synced_at  INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS messages (
+	account       TEXT NOT NULL,
+	id            TEXT NOT NULL,
+	thread_id     TEXT NOT NULL,
+	internal_date INTEGER NOT NULL,
+	labels        TEXT NOT NULL,
+	full          INTEGER NOT NULL DEFAULT 0,
+	message       TEXT NOT NULL,
+	PRIMARY KEY (account, id)
+);
+CREATE INDEX IF NOT EXISTS messages_by_date ON messages (account, internal_date DESC);
+`
+
+// Cache is a local SQLite copy of synced mailboxes
+type Cache struct {
+	db *sql.DB
+}
+
+// SyncState records how far an account's cache has been synced
+type SyncState struct {
+	Account   string
+	HistoryID uint64
+	SyncedAt  time.Time
+}
+
+// GetCachePath returns the path to the cache database
+func GetCachePath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, cacheFileName), nil
+}
+
+// OpenCache opens the cache database, creating it if needed
+func OpenCache() (*Cache, error) {
+	if err := config.EnsureConfigDir(); err != nil {
+		return nil, err
+	}
+
+	path, err := GetCachePath()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache: %w", err)
+	}
+	// SQLite allows a single writer; serialize access from concurrent
+	// account goroutines
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(cacheSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize cache: %w", err)
+	}
+
+	// Caches created before the search index existed are indexed once
+	var indexed int
+	err = db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'messages_fts'`).Scan(&indexed)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize cache: %w", err)
+	}
+	if _, err := db.Exec(searchSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize search index: %w", err)
+	}
+
+	cache := &Cache{db: db}
+	if indexed == 0 {
+		if err := cache.reindex(); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return cache, nil
+}
+
+// Close closes the cache database
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+// SyncState returns the sync state of an account, or nil if it has never
+// been synced
+func (c *Cache) SyncState(account string) (*SyncState, error) {
+	var historyID uint64
+	var syncedAt int64
+	err := c.db.QueryRow(`SELECT history_id, synced_at FROM accounts WHERE name = ?`, account).
+		Scan(&historyID, &syncedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync state: %w", err)
+	}
+
+	return &SyncState{
+		Account:   account,
+		HistoryID: historyID,
+		SyncedAt:  time.Unix(syncedAt, 0),
+	}, nil
+}
+
+// setSyncState records the history ID an account has been synced to
+func (c *Cache) setSyncState(account string, historyID uint64) error {
+	_, err := c.db.Exec(`INSERT INTO accounts (name, history_id, synced_at) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET history_id = excluded.history_id, synced_at = excluded.synced_at`,
+		account, historyID, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to save sync state: %w", err)
+	}
+	return nil
+}
+
+// putMessage stores a message fetched in metadata or full format. A full
+// message already in the cache is not replaced by a metadata-only copy.
+func (c *Cache) putMessage(account string, msg *gmail.Message, full bool) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	_, wasFull, err := c.hasMessage(account, msg.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.db.Exec(`INSERT INTO messages (account, id, thread_id, internal_date, labels, full, message)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (account, id) DO UPDATE SET
+			labels = excluded.labels,
+			full = MAX(full, excluded.full),
+			message = CASE WHEN excluded.full >= full THEN excluded.message ELSE message END`,
+		account, msg.Id, msg.ThreadId, msg.InternalDate, joinLabels(msg.LabelIds), full, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to cache message: %w", err)
+	}
+
+	if full || !wasFull {
+		return c.indexMessage(account, msg)
+	}
+	return nil
+}
+
+// setLabels updates the labels of a cached message
+func (c *Cache) setLabels(account, id string, labelIDs []string) error {
+	_, err := c.db.Exec(`UPDATE messages SET labels = ? WHERE account = ? AND id = ?`,
+		joinLabels(labelIDs), account, id)
+	if err != nil {
+		return fmt.Errorf("failed to update cached labels: %w", err)
+	}
+	return nil
+}
+
+// deleteMessage removes a message from the cache
+func (c *Cache) deleteMessage(account, id string) error {
+	if err := c.unindexMessage(account, id); err != nil {
+		return err
+	}
+	if _, err := c.db.Exec(`DELETE FROM messages WHERE account = ? AND id = ?`, account, id); err != nil {
+		return fmt.Errorf("failed to remove cached message: %w", err)
+	}
+	return nil
+}
+
+// hasMessage reports whether a message is cached, and whether its body is
+func (c *Cache) hasMessage(account, id string) (cached, full bool, err error) {
+	err = c.db.QueryRow(`SELECT full FROM messages WHERE account = ? AND id = ?`, account, id).Scan(&full)
+	if err == sql.ErrNoRows {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("failed to read cache: %w", err)
+	}
+	return true, full, nil
+}
+
+// messageIDs returns the IDs of all cached messages for an account
+func (c *Cache) messageIDs(account string) (map[string]bool, error) {
+	rows, err := c.db.Query(`SELECT id FROM messages WHERE account = ?`, account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to read cache: %w", err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// loadMessage reads a cached message
+func (c *Cache) loadMessage(account, id string) (*gmail.Message, bool, error) {
+	var data string
+	var full bool
+	err := c.db.QueryRow(`SELECT message, full FROM messages WHERE account = ? AND id = ?`, account, id).
+		Scan(&data, &full)
+	if err == sql.ErrNoRows {
+		return nil, false, ErrNotCached
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	msg, err := decodeCachedMessage(data)
+	if err != nil {
+		return nil, false, err
+	}
+	return msg, full, nil
+}
+
+// decodeCachedMessage decodes a message stored as Gmail API JSON
+func decodeCachedMessage(data string) (*gmail.Message, error) {
+	var msg gmail.Message
+	if err := json.Unmarshal([]byte(data), &msg); err != nil {
+		return nil, fmt.Errorf("failed to decode cached message: %w", err)
+	}
+	if msg.Payload == nil {
+		msg.Payload = &gmail.MessagePart{}
+	}
+	return &msg, nil
+}
+
+// ListMessages returns the most recent cached messages for an account,
+// excluding spam and trash as the Gmail API does by default. A limit of 0
+// returns every message.
+func (c *Cache) ListMessages(account string, limit int64) ([]output.EmailSummary, error) {
+	query := `SELECT message FROM messages
+		WHERE account = ? AND labels NOT LIKE '% SPAM %' AND labels NOT LIKE '% TRASH %'
+		ORDER BY internal_date DESC`
+	args := []interface{}{account}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := c.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []output.EmailSummary
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read cache: %w", err)
+		}
+		msg, err := decodeCachedMessage(data)
+		if err != nil {
+			continue
+		}
+		summary := summaryFromMessage(msg)
+		summary.Account = account
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
+
+// GetMessage returns a cached message in the given body format. It returns
+// ErrNotCached if the message or its body has not been downloaded.
+func (c *Cache) GetMessage(account, id string, format BodyFormat) (output.EmailDetail, error) {
+	msg, full, err := c.loadMessage(account, id)
+	if err != nil {
+		return output.EmailDetail{}, err
+	}
+	if !full {
+		return output.EmailDetail{}, ErrNotCached
+	}
+	return detailFromMessage(msg, account, format), nil
+}
+
+// joinLabels stores label IDs space-delimited on both ends so a single label
+// can be matched with LIKE '% LABEL %'
+func joinLabels(labelIDs []string) string {
+	return " " + strings.Join(labelIDs, " ") + " "
+}
@@ -0,0 +1,177 @@
+package gmail
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/output"
+	"google.golang.org/api/gmail/v1"
+)
+
+// MessageContent is a message with its decoded headers and attachments
+type MessageContent struct {
+	Detail output.EmailDetail
+	// Headers holds decoded header values keyed by canonical name
+	Headers     map[string]string
+	LabelIDs    []string
+	Attachments []AttachmentRef
+}
+
+// AttachmentRef identifies an attachment of a message without its data
+type AttachmentRef struct {
+	Filename string
+	MimeType string
+	Size     int64
+	// attachmentID is set for data stored separately from the message;
+	// small attachments carry their data inline instead
+	attachmentID string
+	data         string
+}
+
+// GetMessageContent fetches a message with its headers, plain text body and
+// attachment list
+func (c *Client) GetMessageContent(ctx context.Context, id string) (*MessageContent, error) {
+	msg, err := c.getFullMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &MessageContent{
+		Detail:   detailFromMessage(msg, c.accountName, BodyText),
+		Headers:  make(map[string]string),
+		LabelIDs: msg.LabelIds,
+	}
+	for name, value := range headerMap(msg.Payload.Headers) {
+		content.Headers[name] = decodeHeader(value)
+	}
+	content.Attachments = attachmentRefs(msg.Payload)
+
+	return content, nil
+}
+
+// attachmentRefs lists the attachments in a message payload
+func attachmentRefs(part *gmail.MessagePart) []AttachmentRef {
+	var refs []AttachmentRef
+	if part.Filename != "" && part.Body != nil {
+		refs = append(refs, AttachmentRef{
+			Filename:     part.Filename,
+			MimeType:     part.MimeType,
+			Size:         part.Body.Size,
+			attachmentID: part.Body.AttachmentId,
+			data:         part.Body.Data,
+		})
+	}
+	for _, child := range part.Parts {
+		refs = append(refs, attachmentRefs(child)...)
+	}
+	return refs
+}
+
+// GetAttachment downloads an attachment of a message
+func (c *Client) GetAttachment(ctx context.Context, messageID string, ref AttachmentRef) ([]byte, error) {
+	data := ref.data
+	if ref.attachmentID != "" {
+		body, err := c.service.Users.Messages.Attachments.Get("me", messageID, ref.attachmentID).
+			Context(ctx).
+			Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get attachment %s: %w", ref.Filename, err)
+		}
+		data = body.Data
+	}
+
+	decoded, err := base64.URLEncoding.DecodeString(data)
+	if err != nil {
+		decoded, err = base64.RawURLEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode attachment %s: %w", ref.Filename, err)
+		}
+	}
+	return decoded, nil
+}
+
+// LabelMessage adds labels to a message by name, creating any that do not
+// exist, and removes labels by ID
+func (c *Client) LabelMessage(ctx context.Context, id string, addNames, removeLabelIDs []string) error {
+	addIDs, err := c.labelIDs(ctx, addNames, true)
+	if err != nil {
+		return err
+	}
+	return c.ModifyMessages(ctx, []string{id}, addIDs, removeLabelIDs)
+}
+
+// ForwardEmail builds a forward of a message to the given recipients,
+// quoting the original headers and body. Attachments are added by the caller.
+func ForwardEmail(msg *MessageContent, to []string) DraftEmail {
+	d := msg.Detail
+
+	var body strings.Builder
+	body.WriteString("---------- Forwarded message ---------\n")
+	fmt.Fprintf(&body, "From: %s\n", d.From.String())
+	fmt.Fprintf(&body, "Date: %s\n", d.Date.Format("Mon, Jan 2, 2006 at 3:04 PM"))
+	fmt.Fprintf(&body, "Subject: %s\n", d.Subject)
+	fmt.Fprintf(&body, "To: %s\n", output.FormatAddresses(d.To))
+	if len(d.CC) > 0 {
+		fmt.Fprintf(&body, "Cc: %s\n", output.FormatAddresses(d.CC))
+	}
+	body.WriteString("\n")
+	body.WriteString(d.Body)
+
+	return DraftEmail{
+		To:      to,
+		Subject: prefixSubject("Fwd:", d.Subject),
+		Body:    body.String(),
+	}
+}
+
+// ReplyEmail builds the envelope of a reply to a message: recipients,
+// subject and threading headers. The body is set by the caller.
+func ReplyEmail(msg *MessageContent) DraftEmail {
+	d := msg.Detail
+
+	recipients := d.ReplyTo
+	if len(recipients) == 0 {
+		recipients = []output.Address{d.From}
+	}
+	var to []string
+	for _, a := range recipients {
+		to = append(to, a.String())
+	}
+
+	email := DraftEmail{
+		To:       to,
+		Subject:  prefixSubject("Re:", d.Subject),
+		ThreadID: d.ThreadID,
+	}
+	if d.MessageID != "" {
+		id := "<" + d.MessageID + ">"
+		email.InReplyTo = id
+		email.References = append(strings.Fields(msg.Headers["References"]), id)
+	}
+	return email
+}
+
+// prefixSubject adds a prefix such as "Re:" to a subject unless it already
+// starts with it
+func prefixSubject(prefix, subject string) string {
+	if strings.HasPrefix(strings.ToLower(subject), strings.ToLower(prefix)) {
+		return subject
+	}
+	return prefix + " " + subject
+}
+
+// IsAutomated reports whether a message was generated automatically, such
+// as an auto-reply or mailing list post, so it should not be answered
+// automatically (RFC 3834)
+func (m *MessageContent) IsAutomated() bool {
+	if v := strings.ToLower(strings.TrimSpace(m.Headers["Auto-Submitted"])); v != "" && v != "no" {
+		return true
+	}
+	switch strings.ToLower(strings.TrimSpace(m.Headers["Precedence"])) {
+	case "bulk", "junk", "list":
+		return true
+	}
+	return m.Detail.List != nil
+}
@@ -0,0 +1,51 @@
+package gmail
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alexandraswan/gcli/internal/output"
+	"google.golang.org/api/gmail/v1"
+)
+
+// ListDelegates lists the users who can access the account's mailbox
+func (c *Client) ListDelegates(ctx context.Context) ([]output.Delegate, error) {
+	resp, err := c.service.Users.Settings.Delegates.List("me").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delegates: %w", err)
+	}
+
+	var delegates []output.Delegate
+	for _, d := range resp.Delegates {
+		delegates = append(delegates, output.Delegate{
+			Account:            c.accountName,
+			Email:              d.DelegateEmail,
+			VerificationStatus: d.VerificationStatus,
+		})
+	}
+	return delegates, nil
+}
+
+// AddDelegate grants a user access to the account's mailbox. Delegates in
+// the same organization are accepted immediately; others must accept an
+// invitation first.
+func (c *Client) AddDelegate(ctx context.Context, email string) (output.Delegate, error) {
+	d, err := c.service.Users.Settings.Delegates.Create("me", &gmail.Delegate{DelegateEmail: email}).Context(ctx).Do()
+	if err != nil {
+		return output.Delegate{}, fmt.Errorf("failed to add delegate %s: %w", email, err)
+	}
+
+	return output.Delegate{
+		Account:            c.accountName,
+		Email:              d.DelegateEmail,
+		VerificationStatus: d.VerificationStatus,
+	}, nil
+}
+
+// RemoveDelegate revokes a user's access to the account's mailbox
+func (c *Client) RemoveDelegate(ctx context.Context, email string) error {
+	if err := c.service.Users.Settings.Delegates.Delete("me", email).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to remove delegate %s: %w", email, err)
+	}
+	return nil
+}
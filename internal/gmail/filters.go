@@ -0,0 +1,215 @@
+package gmail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/output"
+	"google.golang.org/api/gmail/v1"
+)
+
+// ListFilters lists the account's filters with label IDs resolved to names
+func (c *Client) ListFilters(ctx context.Context) ([]output.Filter, error) {
+	resp, err := c.service.Users.Settings.Filters.List("me").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list filters: %w", err)
+	}
+
+	labels, err := c.listLabels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(labels))
+	for _, l := range labels {
+		names[l.Id] = l.Name
+	}
+	labelNames := func(ids []string) []string {
+		var result []string
+		for _, id := range ids {
+			if name, ok := names[id]; ok {
+				result = append(result, name)
+			} else {
+				result = append(result, id)
+			}
+		}
+		return result
+	}
+
+	var filters []output.Filter
+	for _, f := range resp.Filter {
+		filter := output.Filter{
+			ID:      f.Id,
+			Account: c.accountName,
+		}
+		if cr := f.Criteria; cr != nil {
+			filter.Criteria = output.FilterCriteria{
+				From:           cr.From,
+				To:             cr.To,
+				Subject:        cr.Subject,
+				Query:          cr.Query,
+				NegatedQuery:   cr.NegatedQuery,
+				HasAttachment:  cr.HasAttachment,
+				ExcludeChats:   cr.ExcludeChats,
+				Size:           cr.Size,
+				SizeComparison: cr.SizeComparison,
+			}
+		}
+		if a := f.Action; a != nil {
+			filter.Action = output.FilterAction{
+				AddLabels:    labelNames(a.AddLabelIds),
+				RemoveLabels: labelNames(a.RemoveLabelIds),
+				Forward:      a.Forward,
+			}
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+// CreateFilter creates a filter, creating any labels it adds that do not
+// exist yet
+func (c *Client) CreateFilter(ctx context.Context, filter output.Filter) (string, error) {
+	addIDs, err := c.labelIDs(ctx, filter.Action.AddLabels, true)
+	if err != nil {
+		return "", err
+	}
+	removeIDs, err := c.labelIDs(ctx, filter.Action.RemoveLabels, false)
+	if err != nil {
+		return "", err
+	}
+
+	cr := filter.Criteria
+	resp, err := c.service.Users.Settings.Filters.Create("me", &gmail.Filter{
+		Criteria: &gmail.FilterCriteria{
+			From:           cr.From,
+			To:             cr.To,
+			Subject:        cr.Subject,
+			Query:          cr.Query,
+			NegatedQuery:   cr.NegatedQuery,
+			HasAttachment:  cr.HasAttachment,
+			ExcludeChats:   cr.ExcludeChats,
+			Size:           cr.Size,
+			SizeComparison: cr.SizeComparison,
+		},
+		Action: &gmail.FilterAction{
+			AddLabelIds:    addIDs,
+			RemoveLabelIds: removeIDs,
+			Forward:        filter.Action.Forward,
+		},
+	}).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to create filter: %w", err)
+	}
+
+	return resp.Id, nil
+}
+
+// DeleteFilter deletes a filter
+func (c *Client) DeleteFilter(ctx context.Context, id string) error {
+	if err := c.service.Users.Settings.Filters.Delete("me", id).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to delete filter: %w", err)
+	}
+	return nil
+}
+
+// listLabels lists all system and user labels
+func (c *Client) listLabels(ctx context.Context) ([]*gmail.Label, error) {
+	resp, err := c.service.Users.Labels.List("me").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+	return resp.Labels, nil
+}
+
+// labelIDs resolves label names to IDs, matching case-insensitively.
+// Missing labels are created when create is set.
+func (c *Client) labelIDs(ctx context.Context, names []string, create bool) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	labels, err := c.listLabels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, name := range names {
+		id := ""
+		for _, l := range labels {
+			if strings.EqualFold(l.Name, name) || l.Id == name {
+				id = l.Id
+				break
+			}
+		}
+		if id == "" {
+			if !create {
+				return nil, fmt.Errorf("label '%s' not found", name)
+			}
+			id, err = c.EnsureLabel(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// FilterKey identifies a filter by its criteria and actions, ignoring its ID
+// and account, so the same filter can be matched across accounts
+func FilterKey(f output.Filter) string {
+	normalize := func(labels []string) []string {
+		result := make([]string, len(labels))
+		for i, l := range labels {
+			result[i] = strings.ToLower(l)
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	key := struct {
+		Criteria output.FilterCriteria
+		Add      []string
+		Remove   []string
+		Forward  string
+	}{
+		Criteria: f.Criteria,
+		Add:      normalize(f.Action.AddLabels),
+		Remove:   normalize(f.Action.RemoveLabels),
+		Forward:  strings.ToLower(f.Action.Forward),
+	}
+	key.Criteria.From = strings.ToLower(key.Criteria.From)
+	key.Criteria.To = strings.ToLower(key.Criteria.To)
+
+	data, _ := json.Marshal(key)
+	return string(data)
+}
+
+// DiffFilters compares an account's filters with a desired set. It returns
+// the desired filters that are missing and the current filters that are
+// not in the desired set.
+func DiffFilters(current, desired []output.Filter) (missing, extra []output.Filter) {
+	have := make(map[string]bool, len(current))
+	for _, f := range current {
+		have[FilterKey(f)] = true
+	}
+	want := make(map[string]bool, len(desired))
+	for _, f := range desired {
+		key := FilterKey(f)
+		if !have[key] && !want[key] {
+			missing = append(missing, f)
+		}
+		want[key] = true
+	}
+	for _, f := range current {
+		if !want[FilterKey(f)] {
+			extra = append(extra, f)
+		}
+	}
+	return missing, extra
+}
@@ -0,0 +1,164 @@
+package gmail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"google.golang.org/api/gmail/v1"
+)
+
+const followupsFileName = "followups.json"
+
+// FollowupData represents a sent email awaiting a reply
+type FollowupData struct {
+	ID        string    `json:"id"`
+	Account   string    `json:"account"`
+	MessageID string    `json:"message_id"`
+	ThreadID  string    `json:"thread_id"`
+	To        []string  `json:"to"`
+	Subject   string    `json:"subject"`
+	SentAt    time.Time `json:"sent_at"`
+	Deadline  time.Time `json:"deadline"`
+	// EventID is the calendar reminder created for the deadline, if any
+	EventID   string `json:"event_id,omitempty"`
+	Reinboxed bool   `json:"reinboxed,omitempty"`
+}
+
+// getFollowupsPath returns the path to the follow-ups file
+func getFollowupsPath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, followupsFileName), nil
+}
+
+// LoadFollowups loads all tracked follow-ups
+func LoadFollowups() ([]FollowupData, error) {
+	path, err := getFollowupsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []FollowupData{}, nil
+		}
+		return nil, fmt.Errorf("failed to read follow-ups: %w", err)
+	}
+
+	var followups []FollowupData
+	if err := json.Unmarshal(data, &followups); err != nil {
+		return nil, fmt.Errorf("failed to parse follow-ups: %w", err)
+	}
+
+	return followups, nil
+}
+
+// SaveFollowups saves all tracked follow-ups
+func SaveFollowups(followups []FollowupData) error {
+	if err := config.EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	path, err := getFollowupsPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(followups, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal follow-ups: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write follow-ups: %w", err)
+	}
+
+	return nil
+}
+
+// AddFollowup starts tracking a sent email
+func AddFollowup(followup FollowupData) error {
+	followups, err := LoadFollowups()
+	if err != nil {
+		return err
+	}
+
+	followup.ID = generateID()
+	followups = append(followups, followup)
+	return SaveFollowups(followups)
+}
+
+// GetThreadID returns the thread a message belongs to
+func (c *Client) GetThreadID(ctx context.Context, messageID string) (string, error) {
+	msg, err := c.service.Users.Messages.Get("me", messageID).
+		Format("minimal").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to get message: %w", err)
+	}
+	return msg.ThreadId, nil
+}
+
+// ThreadHasReply reports whether a thread has a message newer than since
+// that was not sent by the account. Auto-replies and bounces are not
+// replies. A deleted thread counts as replied so it stops being tracked.
+func (c *Client) ThreadHasReply(ctx context.Context, threadID string, since time.Time) (bool, error) {
+	thread, err := c.service.Users.Threads.Get("me", threadID).
+		Format("metadata").
+		MetadataHeaders("From", "Auto-Submitted", "Precedence").
+		Context(ctx).
+		Do()
+	if err != nil {
+		if isNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get thread: %w", err)
+	}
+
+	for _, msg := range thread.Messages {
+		if !time.UnixMilli(msg.InternalDate).After(since) {
+			continue
+		}
+		if isReceived(msg) && !isAutoResponse(headerMap(msg.Payload.Headers)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isAutoResponse reports whether a message's headers mark it as generated
+// automatically, such as an out-of-office reply or a bounce, rather than
+// written by a person. Mailing list posts still count as replies.
+func isAutoResponse(headers map[string]string) bool {
+	if v := strings.ToLower(strings.TrimSpace(headers["Auto-Submitted"])); v != "" && v != "no" {
+		return true
+	}
+	switch strings.ToLower(strings.TrimSpace(headers["Precedence"])) {
+	case "auto_reply", "bulk", "junk":
+		return true
+	}
+	local, _, _ := strings.Cut(strings.ToLower(parseAddress(headers["From"]).Email), "@")
+	return local == "mailer-daemon" || local == "postmaster"
+}
+
+// ModifyThread adds and removes labels on every message in a thread
+func (c *Client) ModifyThread(ctx context.Context, threadID string, addLabelIDs, removeLabelIDs []string) error {
+	_, err := c.service.Users.Threads.Modify("me", threadID, &gmail.ModifyThreadRequest{
+		AddLabelIds:    addLabelIDs,
+		RemoveLabelIds: removeLabelIDs,
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to modify thread: %w", err)
+	}
+	return nil
+}
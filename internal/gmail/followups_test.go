@@ -0,0 +1,29 @@
+package gmail
+
+import "testing"
+
+func TestIsAutoResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{"person", map[string]string{"From": "Alice <alice@example.com>"}, false},
+		{"auto-submitted no", map[string]string{"From": "alice@example.com", "Auto-Submitted": "no"}, false},
+		{"mailing list post", map[string]string{"From": "alice@example.com", "Precedence": "list"}, false},
+		{"out of office", map[string]string{"From": "alice@example.com", "Auto-Submitted": "auto-replied"}, true},
+		{"auto-submitted case", map[string]string{"From": "alice@example.com", "Auto-Submitted": " Auto-Generated "}, true},
+		{"precedence auto_reply", map[string]string{"From": "alice@example.com", "Precedence": "auto_reply"}, true},
+		{"precedence bulk", map[string]string{"From": "alice@example.com", "Precedence": "Bulk"}, true},
+		{"mailer-daemon bounce", map[string]string{"From": "Mail Delivery Subsystem <MAILER-DAEMON@example.com>"}, true},
+		{"postmaster bounce", map[string]string{"From": "postmaster@example.com"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isAutoResponse(tt.headers); got != tt.want {
+				t.Errorf("isAutoResponse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
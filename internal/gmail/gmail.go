@@ -1,16 +1,21 @@
 package gmail
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
+	"net/textproto"
 	"strings"
 	"time"
 
 	"github.com/alexandraswan/gcli/internal/auth"
 	"github.com/alexandraswan/gcli/internal/config"
 	"github.com/alexandraswan/gcli/internal/output"
+	"golang.org/x/net/html/charset"
 	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
@@ -22,7 +27,18 @@ type Client struct {
 
 // NewClient creates a new Gmail client for the specified account
 func NewClient(ctx context.Context, accountName string, account config.AccountConfig) (*Client, error) {
-	httpClient, err := auth.GetClient(ctx, accountName, account)
+	return newClient(ctx, accountName, account, auth.GmailScopes)
+}
+
+// NewSharingClient creates a Gmail client that can also manage delegates
+// and forwarding
+func NewSharingClient(ctx context.Context, accountName string, account config.AccountConfig) (*Client, error) {
+	return newClient(ctx, accountName, account, auth.GmailSharingScopes)
+}
+
+// newClient creates a Gmail client requesting the given scopes
+func newClient(ctx context.Context, accountName string, account config.AccountConfig, scopes []string) (*Client, error) {
+	httpClient, err := auth.GetClient(ctx, accountName, account, scopes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get authenticated client: %w", err)
 	}
@@ -67,36 +83,128 @@ func (c *Client) ListMessages(ctx context.Context, query string, maxResults int6
 	return summaries, nil
 }
 
-// getMessageSummary gets a summary of a single message
-func (c *Client) getMessageSummary(ctx context.Context, id string) (output.EmailSummary, error) {
+// MessageRef identifies a message and its thread
+type MessageRef struct {
+	ID       string
+	ThreadID string
+}
+
+// ListMessageIDs lists the IDs of all messages matching the query, following
+// pagination. A limit of 0 returns every match.
+func (c *Client) ListMessageIDs(ctx context.Context, query string, limit int64) ([]MessageRef, error) {
+	var refs []MessageRef
+	pageToken := ""
+
+	for {
+		req := c.service.Users.Messages.List("me")
+		if query != "" {
+			req = req.Q(query)
+		}
+		if pageToken != "" {
+			req = req.PageToken(pageToken)
+		}
+		if limit > 0 && limit-int64(len(refs)) < 500 {
+			req = req.MaxResults(limit - int64(len(refs)))
+		} else {
+			req = req.MaxResults(500)
+		}
+
+		resp, err := req.Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list messages: %w", err)
+		}
+
+		for _, msg := range resp.Messages {
+			refs = append(refs, MessageRef{ID: msg.Id, ThreadID: msg.ThreadId})
+		}
+
+		if resp.NextPageToken == "" || (limit > 0 && int64(len(refs)) >= limit) {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+
+	return refs, nil
+}
+
+// RawMessage is a message in RFC 822 form as stored by Gmail
+type RawMessage struct {
+	ID           string
+	ThreadID     string
+	LabelIDs     []string
+	InternalDate time.Time
+	Raw          []byte
+}
+
+// GetRawMessage downloads the full RFC 822 source of a message
+func (c *Client) GetRawMessage(ctx context.Context, id string) (*RawMessage, error) {
 	msg, err := c.service.Users.Messages.Get("me", id).
-		Format("metadata").
-		MetadataHeaders("From", "Subject", "Date").
+		Format("raw").
 		Context(ctx).
 		Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	raw, err := base64.URLEncoding.DecodeString(msg.Raw)
+	if err != nil {
+		raw, err = base64.RawURLEncoding.DecodeString(msg.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode message: %w", err)
+		}
+	}
+
+	return &RawMessage{
+		ID:           msg.Id,
+		ThreadID:     msg.ThreadId,
+		LabelIDs:     msg.LabelIds,
+		InternalDate: time.UnixMilli(msg.InternalDate),
+		Raw:          raw,
+	}, nil
+}
+
+// getMessageSummary gets a summary of a single message
+func (c *Client) getMessageSummary(ctx context.Context, id string) (output.EmailSummary, error) {
+	msg, err := c.getMessageMetadata(ctx, id)
 	if err != nil {
 		return output.EmailSummary{}, err
 	}
 
+	return summaryFromMessage(msg), nil
+}
+
+// GetMessageSummary gets a summary of a single message
+func (c *Client) GetMessageSummary(ctx context.Context, id string) (output.EmailSummary, error) {
+	summary, err := c.getMessageSummary(ctx, id)
+	if err != nil {
+		return output.EmailSummary{}, fmt.Errorf("failed to get message: %w", err)
+	}
+	summary.Account = c.accountName
+	return summary, nil
+}
+
+// getMessageMetadata fetches a message with only the headers needed for a
+// summary
+func (c *Client) getMessageMetadata(ctx context.Context, id string) (*gmail.Message, error) {
+	return c.service.Users.Messages.Get("me", id).
+		Format("metadata").
+		MetadataHeaders("From", "Subject", "Date").
+		Context(ctx).
+		Do()
+}
+
+// summaryFromMessage builds a summary from a message fetched in metadata or
+// full format
+func summaryFromMessage(msg *gmail.Message) output.EmailSummary {
 	summary := output.EmailSummary{
 		ID:      msg.Id,
 		Snippet: msg.Snippet,
 	}
 
-	for _, header := range msg.Payload.Headers {
-		switch header.Name {
-		case "From":
-			summary.From = header.Value
-		case "Subject":
-			summary.Subject = header.Value
-		case "Date":
-			if t, err := time.Parse(time.RFC1123Z, header.Value); err == nil {
-				summary.Date = t
-			} else if t, err := time.Parse("Mon, 2 Jan 2006 15:04:05 -0700", header.Value); err == nil {
-				summary.Date = t
-			}
-		}
-	}
+	headers := headerMap(msg.Payload.Headers)
+	summary.From = parseAddress(headers["From"]).String()
+	summary.Subject = decodeHeader(headers["Subject"])
+	summary.Date = parseDate(headers["Date"], msg.InternalDate)
 
 	// Check for attachments
 	if msg.Payload.Parts != nil {
@@ -108,99 +216,178 @@ func (c *Client) getMessageSummary(ctx context.Context, id string) (output.Email
 		}
 	}
 
-	return summary, nil
+	return summary
 }
 
 // GetMessage gets detailed information about a message
-func (c *Client) GetMessage(ctx context.Context, id string) (output.EmailDetail, error) {
+func (c *Client) GetMessage(ctx context.Context, id string, format BodyFormat) (output.EmailDetail, error) {
+	msg, err := c.getFullMessage(ctx, id)
+	if err != nil {
+		return output.EmailDetail{}, err
+	}
+
+	return detailFromMessage(msg, c.accountName, format), nil
+}
+
+// getFullMessage fetches a message with its headers and body parts
+func (c *Client) getFullMessage(ctx context.Context, id string) (*gmail.Message, error) {
 	msg, err := c.service.Users.Messages.Get("me", id).
 		Format("full").
 		Context(ctx).
 		Do()
 	if err != nil {
-		return output.EmailDetail{}, fmt.Errorf("failed to get message: %w", err)
+		return nil, fmt.Errorf("failed to get message: %w", err)
 	}
+	return msg, nil
+}
 
+// detailFromMessage builds message details from a message fetched in full
+// format
+func detailFromMessage(msg *gmail.Message, account string, format BodyFormat) output.EmailDetail {
 	detail := output.EmailDetail{
 		ID:       msg.Id,
 		ThreadID: msg.ThreadId,
-		Account:  c.accountName,
+		Account:  account,
 	}
 
 	// Parse headers
-	for _, header := range msg.Payload.Headers {
-		switch header.Name {
-		case "From":
-			detail.From = header.Value
-		case "To":
-			detail.To = parseAddresses(header.Value)
-		case "Cc":
-			detail.CC = parseAddresses(header.Value)
-		case "Subject":
-			detail.Subject = header.Value
-		case "Date":
-			if t, err := time.Parse(time.RFC1123Z, header.Value); err == nil {
-				detail.Date = t
-			} else if t, err := time.Parse("Mon, 2 Jan 2006 15:04:05 -0700", header.Value); err == nil {
-				detail.Date = t
-			}
-		}
-	}
+	headers := headerMap(msg.Payload.Headers)
+	detail.From = parseAddress(headers["From"])
+	detail.To = parseAddressList(headers["To"])
+	detail.CC = parseAddressList(headers["Cc"])
+	detail.ReplyTo = parseAddressList(headers["Reply-To"])
+	detail.Subject = decodeHeader(headers["Subject"])
+	detail.Date = parseDate(headers["Date"], msg.InternalDate)
+	detail.MessageID = parseMessageID(headers["Message-Id"])
+	detail.List = parseListHeaders(headers)
 
 	// Extract body
-	detail.Body = extractBody(msg.Payload)
+	detail.Body = extractBody(msg.Payload, format)
 
 	// Extract attachments
 	detail.Attachments = extractAttachmentNames(msg.Payload)
 
-	return detail, nil
+	return detail
 }
 
-// extractBody extracts the body text from a message payload
-func extractBody(payload *gmail.MessagePart) string {
-	if payload == nil {
-		return ""
+// BodyFormat selects how a message body is rendered
+type BodyFormat string
+
+const (
+	// BodyText renders the plain text part, converting HTML when there is none
+	BodyText BodyFormat = "text"
+	// BodyHTML returns the HTML part, falling back to plain text
+	BodyHTML BodyFormat = "html"
+	// BodyRaw returns the preferred body part exactly as received, without
+	// charset conversion or HTML rendering
+	BodyRaw BodyFormat = "raw"
+)
+
+// ParseBodyFormat validates a body format name
+func ParseBodyFormat(s string) (BodyFormat, error) {
+	switch f := BodyFormat(strings.ToLower(s)); f {
+	case BodyText, BodyHTML, BodyRaw:
+		return f, nil
 	}
+	return "", fmt.Errorf("unknown body format '%s' (use text, html, or raw)", s)
+}
 
-	// Check for direct body
-	if payload.Body != nil && payload.Body.Data != "" {
-		data, err := base64.URLEncoding.DecodeString(payload.Body.Data)
-		if err == nil {
-			return string(data)
+// extractBody extracts the body from a message payload in the given format
+func extractBody(payload *gmail.MessagePart, format BodyFormat) string {
+	plain, htmlPart := findBodyParts(payload)
+
+	switch format {
+	case BodyHTML:
+		if htmlPart != nil {
+			return decodePart(htmlPart, true)
+		}
+		if plain != nil {
+			return decodePart(plain, true)
+		}
+	case BodyRaw:
+		if plain != nil {
+			return decodePart(plain, false)
+		}
+		if htmlPart != nil {
+			return decodePart(htmlPart, false)
+		}
+	default:
+		if plain != nil {
+			return decodePart(plain, true)
+		}
+		if htmlPart != nil {
+			return htmlToText(decodePart(htmlPart, true))
 		}
 	}
 
-	// Recursively check parts
-	if payload.Parts != nil {
-		// Prefer text/plain over text/html
-		for _, part := range payload.Parts {
-			if part.MimeType == "text/plain" && part.Body != nil && part.Body.Data != "" {
-				data, err := base64.URLEncoding.DecodeString(part.Body.Data)
-				if err == nil {
-					return string(data)
-				}
-			}
+	return ""
+}
+
+// findBodyParts returns the first text/plain and text/html parts of a
+// message, skipping attachments
+func findBodyParts(payload *gmail.MessagePart) (plain, htmlPart *gmail.MessagePart) {
+	var visit func(*gmail.MessagePart)
+	visit = func(part *gmail.MessagePart) {
+		if part == nil || isAttachment(part) {
+			return
 		}
 
-		// Fall back to text/html
-		for _, part := range payload.Parts {
-			if part.MimeType == "text/html" && part.Body != nil && part.Body.Data != "" {
-				data, err := base64.URLEncoding.DecodeString(part.Body.Data)
-				if err == nil {
-					return stripHTML(string(data))
-				}
+		hasData := part.Body != nil && part.Body.Data != ""
+		switch strings.ToLower(part.MimeType) {
+		case "text/plain":
+			if plain == nil && hasData {
+				plain = part
+			}
+		case "text/html":
+			if htmlPart == nil && hasData {
+				htmlPart = part
 			}
 		}
 
-		// Recursively check nested parts
-		for _, part := range payload.Parts {
-			if body := extractBody(part); body != "" {
-				return body
-			}
+		for _, child := range part.Parts {
+			visit(child)
+		}
+	}
+	visit(payload)
+	return plain, htmlPart
+}
+
+// isAttachment reports whether a part is an attachment rather than body
+func isAttachment(part *gmail.MessagePart) bool {
+	if part.Filename != "" {
+		return true
+	}
+	disposition := headerMap(part.Headers)["Content-Disposition"]
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(disposition)), "attachment")
+}
+
+// decodePart decodes a part's body data, optionally converting it from
+// its declared charset to UTF-8
+func decodePart(part *gmail.MessagePart, convert bool) string {
+	data, err := base64.URLEncoding.DecodeString(part.Body.Data)
+	if err != nil {
+		data, err = base64.RawURLEncoding.DecodeString(part.Body.Data)
+		if err != nil {
+			return ""
 		}
 	}
+	if !convert {
+		return string(data)
+	}
 
-	return ""
+	contentType := headerMap(part.Headers)["Content-Type"]
+	if contentType == "" {
+		contentType = part.MimeType
+	}
+	r, err := charset.NewReader(bytes.NewReader(data), contentType)
+	if err != nil {
+		return string(data)
+	}
+	converted, err := io.ReadAll(r)
+	if err != nil {
+		return string(data)
+	}
+	return string(converted)
 }
 
 // extractAttachmentNames extracts attachment filenames from a message payload
@@ -220,48 +407,62 @@ func extractAttachmentNames(payload *gmail.MessagePart) []string {
 	return names
 }
 
-// parseAddresses parses a comma-separated list of email addresses
-func parseAddresses(s string) []string {
-	parts := strings.Split(s, ",")
-	var addrs []string
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		if p != "" {
-			addrs = append(addrs, p)
-		}
-	}
-	return addrs
-}
-
-// stripHTML removes HTML tags from a string (simple implementation)
-func stripHTML(s string) string {
-	var result strings.Builder
-	inTag := false
-	for _, r := range s {
-		if r == '<' {
-			inTag = true
-		} else if r == '>' {
-			inTag = false
-		} else if !inTag {
-			result.WriteRune(r)
+// headerMap indexes message headers by canonical name, keeping the first
+// occurrence of repeated headers
+func headerMap(headers []*gmail.MessagePartHeader) map[string]string {
+	m := make(map[string]string, len(headers))
+	for _, h := range headers {
+		key := textproto.CanonicalMIMEHeaderKey(h.Name)
+		if _, exists := m[key]; !exists {
+			m[key] = h.Value
 		}
 	}
-	return result.String()
+	return m
 }
 
 // DraftEmail represents an email to be drafted
 type DraftEmail struct {
+	From    string
 	To      []string
 	CC      []string
 	BCC     []string
 	Subject string
 	Body    string
 	IsHTML  bool
+	// HTMLBody, when set, is sent as the text/html alternative to Body
+	HTMLBody string
+	// InlineImages are embedded alongside HTMLBody and referenced by cid:
+	InlineImages []InlineImage
+	// Attachments are attached as files
+	Attachments []Attachment
+	// InReplyTo and References thread a reply with the message it answers
+	InReplyTo  string
+	References []string
+	// ThreadID places a sent reply in the original message's thread
+	ThreadID string
+}
+
+// Attachment represents a file attached to an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// InlineImage represents an image embedded in an HTML body
+type InlineImage struct {
+	ContentID   string
+	Filename    string
+	ContentType string
+	Data        []byte
 }
 
 // CreateDraft creates a draft email
 func (c *Client) CreateDraft(ctx context.Context, draft DraftEmail) (string, error) {
-	rawMessage := buildRawMessage(draft)
+	rawMessage, err := buildRawMessage(draft)
+	if err != nil {
+		return "", err
+	}
 
 	d := &gmail.Draft{
 		Message: &gmail.Message{
@@ -293,10 +494,14 @@ func (c *Client) SendDraft(ctx context.Context, draftID string) (string, error)
 
 // SendEmail sends an email directly (without creating a draft first)
 func (c *Client) SendEmail(ctx context.Context, email DraftEmail) (string, error) {
-	rawMessage := buildRawMessage(email)
+	rawMessage, err := buildRawMessage(email)
+	if err != nil {
+		return "", err
+	}
 
 	msg := &gmail.Message{
-		Raw: rawMessage,
+		Raw:      rawMessage,
+		ThreadId: email.ThreadID,
 	}
 
 	resp, err := c.service.Users.Messages.Send("me", msg).Context(ctx).Do()
@@ -307,32 +512,76 @@ func (c *Client) SendEmail(ctx context.Context, email DraftEmail) (string, error
 	return resp.Id, nil
 }
 
-// buildRawMessage builds a base64url-encoded RFC 2822 message
-func buildRawMessage(email DraftEmail) string {
-	var msg strings.Builder
+// ImportMessage imports a raw RFC 822 message into the mailbox with the given
+// labels. The message's Date header is used as its internal date and it
+// bypasses spam classification, as with a mailbox migration.
+func (c *Client) ImportMessage(ctx context.Context, raw []byte, labelIDs []string) (string, error) {
+	msg := &gmail.Message{
+		LabelIds: labelIDs,
+	}
+
+	resp, err := c.service.Users.Messages.Import("me", msg).
+		InternalDateSource("dateHeader").
+		NeverMarkSpam(true).
+		Media(bytes.NewReader(raw), googleapi.ContentType("message/rfc822")).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to import message: %w", err)
+	}
+
+	return resp.Id, nil
+}
+
+// HasMessageID reports whether the mailbox already contains a message with
+// the given RFC 822 Message-ID
+func (c *Client) HasMessageID(ctx context.Context, messageID string) (bool, error) {
+	resp, err := c.service.Users.Messages.List("me").
+		Q("rfc822msgid:" + messageID).
+		IncludeSpamTrash(true).
+		MaxResults(1).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return false, fmt.Errorf("failed to search messages: %w", err)
+	}
 
-	msg.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(email.To, ", ")))
-	if len(email.CC) > 0 {
-		msg.WriteString(fmt.Sprintf("Cc: %s\r\n", strings.Join(email.CC, ", ")))
+	return len(resp.Messages) > 0, nil
+}
+
+// EnsureLabel returns the ID of the user label with the given name, creating
+// it if it does not exist
+func (c *Client) EnsureLabel(ctx context.Context, name string) (string, error) {
+	labels, err := c.listLabels(ctx)
+	if err != nil {
+		return "", err
 	}
-	if len(email.BCC) > 0 {
-		msg.WriteString(fmt.Sprintf("Bcc: %s\r\n", strings.Join(email.BCC, ", ")))
+
+	for _, label := range labels {
+		if strings.EqualFold(label.Name, name) {
+			return label.Id, nil
+		}
 	}
-	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", email.Subject))
-	msg.WriteString("MIME-Version: 1.0\r\n")
 
-	if email.IsHTML {
-		msg.WriteString("Content-Type: text/html; charset=utf-8\r\n")
-	} else {
-		msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	label, err := c.service.Users.Labels.Create("me", &gmail.Label{
+		Name:                  name,
+		LabelListVisibility:   "labelShow",
+		MessageListVisibility: "show",
+	}).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to create label: %w", err)
 	}
 
-	msg.WriteString("\r\n")
-	msg.WriteString(email.Body)
+	return label.Id, nil
+}
 
-	// Base64url encode
-	encoded := base64.URLEncoding.EncodeToString([]byte(msg.String()))
-	return encoded
+// InboxUnreadCount returns the number of unread messages in the inbox
+func (c *Client) InboxUnreadCount(ctx context.Context) (int64, error) {
+	label, err := c.service.Users.Labels.Get("me", "INBOX").Context(ctx).Do()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get inbox: %w", err)
+	}
+	return label.MessagesUnread, nil
 }
 
 // GetAccountName returns the account name for this client
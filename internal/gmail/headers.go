@@ -0,0 +1,158 @@
+package gmail
+
+import (
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/output"
+	"golang.org/x/net/html/charset"
+)
+
+// wordDecoder decodes RFC 2047 encoded-words, including non-UTF-8 charsets
+var wordDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+// dateLayouts are tried in order when net/mail cannot parse a Date header
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 02 Jan 06 15:04:05 -0700",
+	"Mon Jan 2 15:04:05 2006",
+	time.RFC3339,
+}
+
+// decodeHeader decodes RFC 2047 encoded-words in a header value, returning
+// the value unchanged if it cannot be decoded
+func decodeHeader(value string) string {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// parseAddressList parses an address header into structured addresses.
+// Display names containing commas and encoded-words are handled; values
+// that are not valid address lists fall back to a best-effort split.
+func parseAddressList(value string) []output.Address {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	parser := mail.AddressParser{WordDecoder: wordDecoder}
+	if list, err := parser.ParseList(value); err == nil {
+		addrs := make([]output.Address, 0, len(list))
+		for _, a := range list {
+			addrs = append(addrs, output.Address{Name: a.Name, Email: a.Address})
+		}
+		return addrs
+	}
+
+	var addrs []output.Address
+	for _, p := range splitAddresses(value) {
+		if a, err := parser.Parse(p); err == nil {
+			addrs = append(addrs, output.Address{Name: a.Name, Email: a.Address})
+		} else {
+			addrs = append(addrs, output.Address{Name: decodeHeader(p)})
+		}
+	}
+	return addrs
+}
+
+// parseAddress parses a single-address header such as From
+func parseAddress(value string) output.Address {
+	addrs := parseAddressList(value)
+	if len(addrs) == 0 {
+		return output.Address{}
+	}
+	return addrs[0]
+}
+
+// splitAddresses splits an address list on commas that are outside quoted
+// strings, comments and angle brackets
+func splitAddresses(s string) []string {
+	var parts []string
+	var current strings.Builder
+	inQuotes, escaped := false, false
+	depth := 0
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && (r == '<' || r == '('):
+			depth++
+		case !inQuotes && (r == '>' || r == ')') && depth > 0:
+			depth--
+		case r == ',' && !inQuotes && depth == 0:
+			if p := strings.TrimSpace(current.String()); p != "" {
+				parts = append(parts, p)
+			}
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	if p := strings.TrimSpace(current.String()); p != "" {
+		parts = append(parts, p)
+	}
+	return parts
+}
+
+// parseDate parses a Date header, falling back to Gmail's internalDate
+// (milliseconds since the epoch) when the header is missing or unparseable
+func parseDate(value string, internalDate int64) time.Time {
+	value = strings.TrimSpace(value)
+	if value != "" {
+		if t, err := mail.ParseDate(value); err == nil {
+			return t
+		}
+
+		// Drop trailing comments such as "(UTC)" and retry common layouts
+		if i := strings.Index(value, "("); i > 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t
+			}
+		}
+	}
+
+	if internalDate > 0 {
+		return time.UnixMilli(internalDate)
+	}
+	return time.Time{}
+}
+
+// parseMessageID strips the angle brackets from a Message-ID header
+func parseMessageID(value string) string {
+	return strings.Trim(strings.TrimSpace(value), "<>")
+}
+
+// parseListHeaders extracts the RFC 2369 / RFC 2919 List-* headers
+func parseListHeaders(headers map[string]string) *output.ListHeaders {
+	list := output.ListHeaders{
+		ID:              decodeHeader(headers["List-Id"]),
+		Unsubscribe:     headers["List-Unsubscribe"],
+		UnsubscribePost: headers["List-Unsubscribe-Post"],
+		Post:            headers["List-Post"],
+		Help:            headers["List-Help"],
+		Archive:         headers["List-Archive"],
+	}
+	if list == (output.ListHeaders{}) {
+		return nil
+	}
+	return &list
+}
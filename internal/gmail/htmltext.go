@@ -0,0 +1,492 @@
+package gmail
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skippedElements are never rendered as text
+var skippedElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Title:    true,
+	atom.Svg:      true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+}
+
+// blockElements start on a new line; paragraph-like ones also get a blank
+// line before and after
+var blockElements = map[atom.Atom]int{
+	atom.P:          2,
+	atom.H1:         2,
+	atom.H2:         2,
+	atom.H3:         2,
+	atom.H4:         2,
+	atom.H5:         2,
+	atom.H6:         2,
+	atom.Blockquote: 2,
+	atom.Pre:        2,
+	atom.Ul:         2,
+	atom.Ol:         2,
+	atom.Dl:         2,
+	atom.Table:      2,
+	atom.Hr:         2,
+	atom.Div:        1,
+	atom.Section:    1,
+	atom.Article:    1,
+	atom.Header:     1,
+	atom.Footer:     1,
+	atom.Main:       1,
+	atom.Nav:        1,
+	atom.Aside:      1,
+	atom.Address:    1,
+	atom.Figure:     1,
+	atom.Figcaption: 1,
+	atom.Form:       1,
+	atom.Fieldset:   1,
+	atom.Center:     1,
+	atom.Tr:         1,
+	atom.Li:         1,
+	atom.Dt:         1,
+	atom.Dd:         1,
+}
+
+var excessBlankLines = regexp.MustCompile(`\n{3,}`)
+
+// htmlToText renders an HTML document as readable plain text. Block
+// structure, lists and tables are preserved, script and style content is
+// dropped, and links are listed as numbered footnotes.
+func htmlToText(src string) string {
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return src
+	}
+
+	var links []string
+	r := newTextRenderer(&links)
+	r.walk(doc)
+
+	text := r.String()
+	if len(links) > 0 {
+		var b strings.Builder
+		b.WriteString(text)
+		b.WriteString("\n\nLinks:\n")
+		for i, link := range links {
+			fmt.Fprintf(&b, "[%d] %s\n", i+1, link)
+		}
+		text = b.String()
+	}
+	return strings.TrimSpace(text)
+}
+
+// textRenderer accumulates text output while walking an HTML tree
+type textRenderer struct {
+	buf         strings.Builder
+	links       *[]string
+	prefixes    []string
+	marker      string
+	newlines    int
+	written     int
+	space       bool
+	preDepth    int
+	listDepth   int
+	atLineStart bool
+}
+
+func newTextRenderer(links *[]string) *textRenderer {
+	return &textRenderer{links: links, atLineStart: true}
+}
+
+// String returns the rendered text with trailing whitespace trimmed from
+// each line and runs of blank lines collapsed
+func (r *textRenderer) String() string {
+	lines := strings.Split(r.buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return excessBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+}
+
+// breakLines requests at least n line breaks before the next text
+func (r *textRenderer) breakLines(n int) {
+	if r.buf.Len() == 0 {
+		return
+	}
+	// Line breaks already written count towards the request
+	if n-r.written > r.newlines {
+		r.newlines = n - r.written
+	}
+	r.space = false
+}
+
+// writeText writes inline text, collapsing whitespace outside <pre>
+func (r *textRenderer) writeText(s string) {
+	if r.preDepth > 0 {
+		for i, line := range strings.Split(s, "\n") {
+			if i > 0 {
+				r.newlines++
+			}
+			if line != "" {
+				r.startLine()
+				r.buf.WriteString(line)
+			}
+		}
+		return
+	}
+
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		if s != "" && !r.atLineStart && r.newlines == 0 {
+			r.space = true
+		}
+		return
+	}
+
+	needSpace := (r.space || isSpace(s[0])) && !r.atLineStart && r.newlines == 0
+	r.startLine()
+	if needSpace {
+		r.buf.WriteString(" ")
+	}
+	r.buf.WriteString(strings.Join(words, " "))
+	r.space = isSpace(s[len(s)-1])
+}
+
+// startLine flushes pending line breaks and, at the start of a line,
+// writes the current indentation and quote prefixes
+func (r *textRenderer) startLine() {
+	r.flushBreaks()
+	r.written = 0
+	if !r.atLineStart {
+		return
+	}
+
+	prefix := strings.Join(r.prefixes, "")
+	if r.marker != "" && len(r.prefixes) > 0 {
+		last := r.prefixes[len(r.prefixes)-1]
+		prefix = strings.Join(r.prefixes[:len(r.prefixes)-1], "") + padRight(r.marker, utf8.RuneCountInString(last))
+		r.marker = ""
+	}
+	r.buf.WriteString(prefix)
+	r.atLineStart = false
+}
+
+// flushBreaks writes pending line breaks. Blank lines inside blockquotes
+// keep the quote prefix.
+func (r *textRenderer) flushBreaks() {
+	if r.newlines > 0 {
+		for i := 0; i < r.newlines; i++ {
+			if i > 0 {
+				r.buf.WriteString(strings.TrimRight(strings.Join(r.quotePrefixes(), ""), " "))
+			}
+			r.buf.WriteString("\n")
+		}
+		r.written += r.newlines
+		r.newlines = 0
+		r.atLineStart = true
+		r.space = false
+	}
+}
+
+// quotePrefixes returns the blockquote prefixes, which continue on blank lines
+func (r *textRenderer) quotePrefixes() []string {
+	var quotes []string
+	for _, p := range r.prefixes {
+		if strings.HasPrefix(p, ">") {
+			quotes = append(quotes, p)
+		}
+	}
+	return quotes
+}
+
+// walk renders a node and its children
+func (r *textRenderer) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.writeText(n.Data)
+		return
+	case html.ElementNode:
+		r.element(n)
+		return
+	case html.CommentNode, html.DoctypeNode:
+		return
+	}
+	r.children(n)
+}
+
+func (r *textRenderer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.walk(c)
+	}
+}
+
+// element renders an element node
+func (r *textRenderer) element(n *html.Node) {
+	if skippedElements[n.DataAtom] {
+		return
+	}
+
+	if lines, ok := blockElements[n.DataAtom]; ok {
+		// Nested lists sit directly under their parent item
+		if (n.DataAtom == atom.Ul || n.DataAtom == atom.Ol) && r.listDepth > 0 {
+			lines = 1
+		}
+		r.breakLines(lines)
+		defer r.breakLines(lines)
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		if r.buf.Len() > 0 && r.newlines < 2 {
+			r.newlines++
+		}
+		r.space = false
+		return
+
+	case atom.Hr:
+		r.writeText("----------------------------------------")
+		return
+
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			r.writeText(" " + alt + " ")
+		}
+		return
+
+	case atom.A:
+		r.children(n)
+		r.link(n)
+		return
+
+	case atom.Pre:
+		r.preDepth++
+		r.children(n)
+		r.preDepth--
+		return
+
+	case atom.Blockquote:
+		r.flushBreaks()
+		r.prefixes = append(r.prefixes, "> ")
+		r.children(n)
+		r.breakLines(2)
+		r.prefixes = r.prefixes[:len(r.prefixes)-1]
+		return
+
+	case atom.Ul, atom.Ol:
+		r.list(n)
+		return
+
+	case atom.Dd:
+		r.prefixes = append(r.prefixes, "    ")
+		r.children(n)
+		r.prefixes = r.prefixes[:len(r.prefixes)-1]
+		return
+
+	case atom.Table:
+		r.table(n)
+		return
+
+	case atom.Td, atom.Th:
+		r.children(n)
+		r.writeText(" ")
+		return
+	}
+
+	r.children(n)
+}
+
+// link records an anchor's target as a footnote when it adds information
+func (r *textRenderer) link(n *html.Node) {
+	href := strings.TrimSpace(attr(n, "href"))
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return
+	}
+	text := strings.TrimSpace(nodeText(n))
+	if text == href || "mailto:"+text == href {
+		return
+	}
+
+	// The marker sits right after the link text, even when that ends in
+	// whitespace such as an image's alt text
+	*r.links = append(*r.links, href)
+	r.space = false
+	r.writeText(fmt.Sprintf("[%d]", len(*r.links)))
+}
+
+// list renders ordered and unordered list items with markers
+func (r *textRenderer) list(n *html.Node) {
+	ordered := n.DataAtom == atom.Ol
+	index := 1
+	if start := attr(n, "start"); start != "" {
+		fmt.Sscanf(start, "%d", &index)
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+			r.walk(c)
+			continue
+		}
+
+		marker := "- "
+		if ordered {
+			marker = fmt.Sprintf("%d. ", index)
+			index++
+		}
+
+		r.breakLines(1)
+		r.prefixes = append(r.prefixes, strings.Repeat(" ", utf8.RuneCountInString(marker)))
+		r.marker = marker
+		r.listDepth++
+		r.children(c)
+		r.listDepth--
+		r.marker = ""
+		r.prefixes = r.prefixes[:len(r.prefixes)-1]
+		r.breakLines(1)
+	}
+}
+
+// table renders data tables as aligned columns. Tables whose cells contain
+// block content are treated as layout tables and rendered cell by cell.
+func (r *textRenderer) table(n *html.Node) {
+	rows := tableRows(n)
+	if !isDataTable(rows) {
+		r.children(n)
+		return
+	}
+
+	var cells [][]string
+	widths := []int{}
+	for _, row := range rows {
+		var texts []string
+		for i, cell := range row {
+			sub := newTextRenderer(r.links)
+			sub.children(cell)
+			text := strings.Join(strings.Fields(sub.String()), " ")
+			texts = append(texts, text)
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := utf8.RuneCountInString(text); w > widths[i] {
+				widths[i] = w
+			}
+		}
+		cells = append(cells, texts)
+	}
+
+	for _, row := range cells {
+		r.breakLines(1)
+		for i, text := range row {
+			if i < len(row)-1 {
+				text = padRight(text, widths[i])
+			}
+			r.startLine()
+			if i > 0 {
+				r.buf.WriteString(" | ")
+			}
+			r.buf.WriteString(text)
+			r.atLineStart = false
+		}
+	}
+}
+
+// tableRows returns the cells of each row in a table, ignoring nested tables
+func tableRows(table *html.Node) [][]*html.Node {
+	var rows [][]*html.Node
+	var visit func(*html.Node)
+	visit = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				visit(c)
+			case atom.Tr:
+				var row []*html.Node
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+						row = append(row, cell)
+					}
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+	visit(table)
+	return rows
+}
+
+// isDataTable reports whether a table holds tabular data rather than layout
+func isDataTable(rows [][]*html.Node) bool {
+	if len(rows) == 0 {
+		return false
+	}
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+		for _, cell := range row {
+			if hasBlockContent(cell) {
+				return false
+			}
+		}
+	}
+	return columns > 1
+}
+
+// hasBlockContent reports whether a node contains block-level elements
+func hasBlockContent(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			if _, ok := blockElements[c.DataAtom]; ok || c.DataAtom == atom.Br {
+				return true
+			}
+			if hasBlockContent(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nodeText returns the concatenated text content of a node
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(nodeText(c))
+	}
+	return b.String()
+}
+
+// attr returns the value of an attribute on a node
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
+
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
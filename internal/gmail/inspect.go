@@ -0,0 +1,327 @@
+package gmail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/output"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"google.golang.org/api/gmail/v1"
+)
+
+// MarkSpam moves messages to spam
+func (c *Client) MarkSpam(ctx context.Context, ids []string) error {
+	return c.ModifyMessages(ctx, ids, []string{"SPAM"}, []string{"INBOX"})
+}
+
+// MarkNotSpam moves messages out of spam and back to the inbox
+func (c *Client) MarkNotSpam(ctx context.Context, ids []string) error {
+	return c.ModifyMessages(ctx, ids, []string{"INBOX"}, []string{"SPAM"})
+}
+
+// InspectMessage examines a message's authentication results, delivery
+// path and links for signs of spoofing or phishing
+func (c *Client) InspectMessage(ctx context.Context, id string) (output.MessageInspection, error) {
+	msg, err := c.getFullMessage(ctx, id)
+	if err != nil {
+		return output.MessageInspection{}, err
+	}
+
+	headers := headerMap(msg.Payload.Headers)
+	inspection := output.MessageInspection{
+		ID:         msg.Id,
+		Account:    c.accountName,
+		From:       parseAddress(headers["From"]),
+		ReplyTo:    parseAddressList(headers["Reply-To"]),
+		ReturnPath: parseMessageID(headers["Return-Path"]),
+		Subject:    decodeHeader(headers["Subject"]),
+	}
+
+	for _, h := range msg.Payload.Headers {
+		switch strings.ToLower(h.Name) {
+		case "authentication-results":
+			inspection.Auth = append(inspection.Auth, parseAuthResults(h.Value)...)
+		case "received":
+			inspection.Hops = append(inspection.Hops, parseReceived(h.Value))
+		}
+	}
+	// Received headers are added at the top by each server; list the
+	// delivery path from the sender onwards
+	for i, j := 0, len(inspection.Hops)-1; i < j; i, j = i+1, j-1 {
+		inspection.Hops[i], inspection.Hops[j] = inspection.Hops[j], inspection.Hops[i]
+	}
+
+	if _, htmlPart := findBodyParts(msg.Payload); htmlPart != nil {
+		inspection.Links = inspectLinks(decodePart(htmlPart, true))
+	}
+	inspection.Warnings = inspectionWarnings(inspection, msg)
+
+	return inspection, nil
+}
+
+// parseAuthResults parses an RFC 8601 Authentication-Results header into
+// one result per method
+func parseAuthResults(value string) []output.AuthResult {
+	parts := splitOutsideComments(unfold(value), ';')
+	if len(parts) == 0 {
+		return nil
+	}
+
+	server := strings.TrimSpace(stripComments(parts[0]))
+	var results []output.AuthResult
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		method, rest, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		result, details, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		results = append(results, output.AuthResult{
+			Server:  server,
+			Method:  strings.ToLower(strings.TrimSpace(method)),
+			Result:  strings.ToLower(result),
+			Details: strings.TrimSpace(details),
+		})
+	}
+	return results
+}
+
+var (
+	receivedFrom = regexp.MustCompile(`(?i)\bfrom\s+(\S+)(?:\s+\(([^)]*)\))?`)
+	receivedBy   = regexp.MustCompile(`(?i)\bby\s+(\S+)`)
+	receivedWith = regexp.MustCompile(`(?i)\bwith\s+(\S+)`)
+	// ipAddress finds an IPv4 or bracketed IPv6 address in a Received
+	// header comment
+	ipAddress = regexp.MustCompile(`\[?(\d{1,3}(?:\.\d{1,3}){3}|[0-9a-fA-F:]*:[0-9a-fA-F:.]+)\]?`)
+)
+
+// parseReceived parses the parts of a Received header used to follow a
+// message's delivery path
+func parseReceived(value string) output.ReceivedHop {
+	value = unfold(value)
+	hop := output.ReceivedHop{Raw: value}
+
+	clauses := value
+	if i := strings.LastIndex(value, ";"); i >= 0 {
+		clauses = value[:i]
+		hop.Date = parseDate(strings.TrimSpace(value[i+1:]), 0)
+	}
+
+	if m := receivedFrom.FindStringSubmatch(clauses); m != nil {
+		hop.From = m[1]
+		if ip := ipAddress.FindStringSubmatch(m[2]); ip != nil {
+			hop.IP = ip[1]
+		}
+	}
+	if m := receivedBy.FindStringSubmatch(clauses); m != nil {
+		hop.By = m[1]
+	}
+	if m := receivedWith.FindStringSubmatch(clauses); m != nil {
+		hop.With = m[1]
+	}
+	return hop
+}
+
+// looksLikeURL matches link text that names a web address
+var looksLikeURL = regexp.MustCompile(`(?i)^(?:https?://)?(?:www\.)?[a-z0-9-]+(?:\.[a-z0-9-]+)+(?:[/:?#]\S*)?$`)
+
+// inspectLinks lists the links in an HTML body, flagging suspicious ones
+func inspectLinks(body string) []output.LinkInspection {
+	doc, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	var links []output.LinkInspection
+	var visit func(*html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			if href := strings.TrimSpace(attr(n, "href")); href != "" {
+				text := strings.Join(strings.Fields(nodeText(n)), " ")
+				links = append(links, inspectLink(text, href))
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			visit(child)
+		}
+	}
+	visit(doc)
+	return links
+}
+
+// inspectLink checks a link for common phishing tricks
+func inspectLink(text, href string) output.LinkInspection {
+	link := output.LinkInspection{Text: text, Href: href}
+
+	u, err := url.Parse(href)
+	if err != nil {
+		link.Reasons = append(link.Reasons, "link cannot be parsed")
+		return link
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+	case "mailto", "tel":
+		return link
+	case "":
+		if strings.HasPrefix(href, "#") {
+			return link
+		}
+		link.Reasons = append(link.Reasons, "link has no scheme")
+		return link
+	default:
+		link.Reasons = append(link.Reasons, fmt.Sprintf("uses %s: scheme", strings.ToLower(u.Scheme)))
+		return link
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if u.User != nil {
+		link.Reasons = append(link.Reasons, "URL contains a user name, hiding the real host after @")
+	}
+	if net.ParseIP(host) != nil {
+		link.Reasons = append(link.Reasons, "links to an IP address")
+	}
+	for _, label := range strings.Split(host, ".") {
+		if strings.HasPrefix(label, "xn--") {
+			link.Reasons = append(link.Reasons, "host uses punycode (look-alike characters)")
+			break
+		}
+	}
+
+	// Text that reads as an address should point to that address
+	if looksLikeURL.MatchString(text) {
+		shown := text
+		if !strings.Contains(shown, "://") {
+			shown = "http://" + shown
+		}
+		if su, err := url.Parse(shown); err == nil {
+			shownHost := strings.TrimPrefix(strings.ToLower(su.Hostname()), "www.")
+			if shownHost != "" && !sameSite(shownHost, strings.TrimPrefix(host, "www.")) {
+				link.Reasons = append(link.Reasons, fmt.Sprintf("text shows %s but links to %s", shownHost, host))
+			}
+		}
+	}
+
+	return link
+}
+
+// sameSite reports whether two hosts are the same or one is a subdomain of
+// the other
+func sameSite(a, b string) bool {
+	return a == b || strings.HasSuffix(a, "."+b) || strings.HasSuffix(b, "."+a)
+}
+
+// inspectionWarnings summarizes signs that a message is not what it claims
+// to be
+func inspectionWarnings(in output.MessageInspection, msg *gmail.Message) []string {
+	var warnings []string
+
+	// The first Authentication-Results header is added by the receiving
+	// server; later ones may come from the sender and cannot be trusted
+	trusted := ""
+	if len(in.Auth) > 0 {
+		trusted = in.Auth[0].Server
+	}
+	for _, method := range []string{"spf", "dkim", "dmarc"} {
+		result := ""
+		for _, a := range in.Auth {
+			if a.Server == trusted && a.Method == method {
+				result = a.Result
+				break
+			}
+		}
+		switch result {
+		case "pass":
+		case "":
+			warnings = append(warnings, fmt.Sprintf("no %s result", strings.ToUpper(method)))
+		default:
+			warnings = append(warnings, fmt.Sprintf("%s %s", strings.ToUpper(method), result))
+		}
+	}
+
+	fromDomain := domainOf(in.From.Email)
+	for _, r := range in.ReplyTo {
+		if d := domainOf(r.Email); d != "" && fromDomain != "" && !sameSite(d, fromDomain) {
+			warnings = append(warnings, fmt.Sprintf("replies go to %s, not the sender's domain %s", r.Email, fromDomain))
+		}
+	}
+	if d := domainOf(in.ReturnPath); d != "" && fromDomain != "" && !sameSite(d, fromDomain) {
+		warnings = append(warnings, fmt.Sprintf("bounces go to %s, not the sender's domain %s", d, fromDomain))
+	}
+
+	suspicious := 0
+	for _, l := range in.Links {
+		if len(l.Reasons) > 0 {
+			suspicious++
+		}
+	}
+	if suspicious > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d suspicious link(s)", suspicious))
+	}
+
+	for _, id := range msg.LabelIds {
+		if id == "SPAM" {
+			warnings = append(warnings, "already in spam")
+		}
+	}
+
+	return warnings
+}
+
+// domainOf returns the lowercase domain of an email address
+func domainOf(email string) string {
+	_, domain, ok := strings.Cut(strings.ToLower(email), "@")
+	if !ok {
+		return ""
+	}
+	return domain
+}
+
+// unfold joins a folded header value onto one line
+func unfold(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// splitOutsideComments splits s on sep where it is not inside a
+// parenthesized comment or quoted string
+func splitOutsideComments(s string, sep rune) []string {
+	var parts []string
+	depth, quoted, start := 0, false, 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case r == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// stripComments removes parenthesized comments from a header value
+func stripComments(s string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
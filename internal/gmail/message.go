@@ -0,0 +1,346 @@
+package gmail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineLength is the recommended maximum line length from RFC 5322
+const maxLineLength = 78
+
+// These are replaced in tests so generated messages are reproducible
+var (
+	now          = time.Now
+	newMessageID = randomMessageID
+	newBoundary  = randomBoundary
+)
+
+// buildRawMessage builds a base64url-encoded RFC 5322 message
+func buildRawMessage(email DraftEmail) (string, error) {
+	msg, err := buildMessage(email)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(msg), nil
+}
+
+// buildMessage builds an RFC 5322 message with MIME encoded headers and bodies
+func buildMessage(email DraftEmail) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if email.From != "" {
+		from, err := formatAddressList([]string{email.From})
+		if err != nil {
+			return nil, fmt.Errorf("invalid From address: %w", err)
+		}
+		writeHeader(&buf, "From", from)
+	}
+
+	to, err := formatAddressList(email.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid To address: %w", err)
+	}
+	writeHeader(&buf, "To", to)
+
+	if len(email.CC) > 0 {
+		cc, err := formatAddressList(email.CC)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Cc address: %w", err)
+		}
+		writeHeader(&buf, "Cc", cc)
+	}
+	if len(email.BCC) > 0 {
+		bcc, err := formatAddressList(email.BCC)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Bcc address: %w", err)
+		}
+		writeHeader(&buf, "Bcc", bcc)
+	}
+
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", email.Subject))
+	writeHeader(&buf, "Date", now().Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", newMessageID(messageIDDomain(email.From)))
+	if email.InReplyTo != "" {
+		writeHeader(&buf, "In-Reply-To", email.InReplyTo)
+	}
+	if len(email.References) > 0 {
+		writeHeader(&buf, "References", strings.Join(email.References, " "))
+	}
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	part := bodyPart(email)
+	if len(email.Attachments) > 0 {
+		part = mixedPart(part, email.Attachments)
+	}
+	writeFields(&buf, part.header)
+	buf.WriteString("\r\n")
+	buf.Write(part.body)
+
+	return buf.Bytes(), nil
+}
+
+// formatAddressList parses addresses and formats them per RFC 5322, encoding
+// non-ASCII display names as RFC 2047 encoded-words
+func formatAddressList(addrs []string) (string, error) {
+	var formatted []string
+	for _, a := range addrs {
+		parsed, err := mail.ParseAddressList(a)
+		if err != nil {
+			return "", fmt.Errorf("%q: %w", a, err)
+		}
+		for _, p := range parsed {
+			if p.Name == "" {
+				formatted = append(formatted, p.Address)
+			} else {
+				formatted = append(formatted, p.String())
+			}
+		}
+	}
+	return strings.Join(formatted, ", "), nil
+}
+
+// writeHeader writes a header field, folding it at whitespace so lines stay
+// within the recommended length where possible
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	line := name + ":"
+	lineLen := len(line)
+	buf.WriteString(line)
+
+	for i, word := range strings.Split(value, " ") {
+		if i > 0 && lineLen+1+len(word) > maxLineLength {
+			buf.WriteString("\r\n")
+			lineLen = 0
+		}
+		buf.WriteString(" ")
+		buf.WriteString(word)
+		lineLen += 1 + len(word)
+	}
+	buf.WriteString("\r\n")
+}
+
+// headerField is a single header in a MIME part. Parts keep their headers
+// as an ordered list so generated messages are byte-for-byte stable.
+type headerField struct {
+	name  string
+	value string
+}
+
+// writeFields writes an ordered list of header fields
+func writeFields(buf *bytes.Buffer, fields []headerField) {
+	for _, f := range fields {
+		writeHeader(buf, f.name, f.value)
+	}
+}
+
+// transferEncoding picks the Content-Transfer-Encoding for a text body.
+// Short-lined ASCII is sent as-is, mostly ASCII text as quoted-printable,
+// and anything else as base64.
+func transferEncoding(body string) string {
+	var nonASCII int
+	longLines := false
+	for _, line := range strings.Split(body, "\n") {
+		if len(line) > maxLineLength {
+			longLines = true
+		}
+	}
+	for i := 0; i < len(body); i++ {
+		if body[i] >= 0x80 || (body[i] < 0x20 && body[i] != '\n' && body[i] != '\r' && body[i] != '\t') {
+			nonASCII++
+		}
+	}
+
+	switch {
+	case nonASCII == 0 && !longLines:
+		return "7bit"
+	case !utf8.ValidString(body) || nonASCII*3 > len(body):
+		return "base64"
+	default:
+		return "quoted-printable"
+	}
+}
+
+// encodeBody encodes body with the given transfer encoding using CRLF line endings
+func encodeBody(body []byte, encoding string) []byte {
+	var buf bytes.Buffer
+	switch encoding {
+	case "base64":
+		buf.WriteString(wrapBase64(body))
+	case "quoted-printable":
+		w := quotedprintable.NewWriter(&buf)
+		w.Write(body)
+		w.Close()
+	default:
+		normalized := strings.ReplaceAll(string(body), "\r\n", "\n")
+		buf.WriteString(strings.ReplaceAll(normalized, "\n", "\r\n"))
+	}
+	return buf.Bytes()
+}
+
+// bodyPart builds the message body: plain text, HTML, or both as
+// multipart/alternative
+func bodyPart(email DraftEmail) mimePart {
+	switch {
+	case email.HTMLBody != "":
+		return alternativePart(email)
+	case email.IsHTML:
+		return textMIMEPart("text/html", email.Body)
+	default:
+		return textMIMEPart("text/plain", email.Body)
+	}
+}
+
+// alternativePart builds a multipart/alternative body carrying both the
+// plain text and HTML versions. When inline images are present the HTML
+// part is wrapped in multipart/related so the images can be referenced by
+// Content-ID.
+func alternativePart(email DraftEmail) mimePart {
+	altBoundary := newBoundary()
+
+	parts := []mimePart{textMIMEPart("text/plain", email.Body)}
+
+	htmlPart := textMIMEPart("text/html", email.HTMLBody)
+	if len(email.InlineImages) == 0 {
+		parts = append(parts, htmlPart)
+	} else {
+		related := []mimePart{htmlPart}
+		for _, img := range email.InlineImages {
+			related = append(related, mimePart{
+				header: []headerField{
+					{"Content-Type", mime.FormatMediaType(img.ContentType, map[string]string{"name": img.Filename})},
+					{"Content-Transfer-Encoding", "base64"},
+					{"Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": img.Filename})},
+					{"Content-ID", "<" + img.ContentID + ">"},
+				},
+				body: []byte(wrapBase64(img.Data)),
+			})
+		}
+
+		relBoundary := newBoundary()
+		var relBody bytes.Buffer
+		writeMultipart(&relBody, relBoundary, related)
+		parts = append(parts, mimePart{
+			header: []headerField{
+				{"Content-Type", fmt.Sprintf("multipart/related; boundary=%q", relBoundary)},
+			},
+			body: relBody.Bytes(),
+		})
+	}
+
+	var body bytes.Buffer
+	writeMultipart(&body, altBoundary, parts)
+	return mimePart{
+		header: []headerField{
+			{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", altBoundary)},
+		},
+		body: body.Bytes(),
+	}
+}
+
+// mixedPart wraps a body and file attachments in multipart/mixed
+func mixedPart(body mimePart, attachments []Attachment) mimePart {
+	boundary := newBoundary()
+
+	parts := []mimePart{body}
+	for _, a := range attachments {
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		parts = append(parts, mimePart{
+			header: []headerField{
+				{"Content-Type", mime.FormatMediaType(contentType, map[string]string{"name": a.Filename})},
+				{"Content-Transfer-Encoding", "base64"},
+				{"Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+			},
+			body: []byte(wrapBase64(a.Data)),
+		})
+	}
+
+	var buf bytes.Buffer
+	writeMultipart(&buf, boundary, parts)
+	return mimePart{
+		header: []headerField{
+			{"Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", boundary)},
+		},
+		body: buf.Bytes(),
+	}
+}
+
+// mimePart is an encoded MIME body part
+type mimePart struct {
+	header []headerField
+	body   []byte
+}
+
+// textMIMEPart builds an encoded UTF-8 text part
+func textMIMEPart(contentType, body string) mimePart {
+	encoding := transferEncoding(body)
+	return mimePart{
+		header: []headerField{
+			{"Content-Type", contentType + "; charset=utf-8"},
+			{"Content-Transfer-Encoding", encoding},
+		},
+		body: encodeBody([]byte(body), encoding),
+	}
+}
+
+// writeMultipart writes parts separated by the given boundary
+func writeMultipart(buf *bytes.Buffer, boundary string, parts []mimePart) {
+	for _, p := range parts {
+		buf.WriteString("--" + boundary + "\r\n")
+		writeFields(buf, p.header)
+		buf.WriteString("\r\n")
+		buf.Write(p.body)
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+}
+
+// wrapBase64 base64-encodes data in 76 character lines
+func wrapBase64(data []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var b strings.Builder
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76])
+		b.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+	return b.String()
+}
+
+// messageIDDomain returns the domain used for generated Message-IDs
+func messageIDDomain(from string) string {
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(addr.Address, "@"); at >= 0 {
+			return addr.Address[at+1:]
+		}
+	}
+	return "gcli.local"
+}
+
+// randomMessageID generates a unique Message-ID for the given domain
+func randomMessageID(domain string) string {
+	return fmt.Sprintf("<%d.%s@%s>", now().UnixNano(), randomHex(8), domain)
+}
+
+// randomBoundary generates a random multipart boundary
+func randomBoundary() string {
+	return "gcli-" + randomHex(16)
+}
+
+// randomHex returns n random bytes as a hex string
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,226 @@
+package gmail
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"mime"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// useFixedMessageValues makes Date, Message-ID and boundaries deterministic
+func useFixedMessageValues(t *testing.T) {
+	t.Helper()
+	origNow, origID, origBoundary := now, newMessageID, newBoundary
+	t.Cleanup(func() {
+		now, newMessageID, newBoundary = origNow, origID, origBoundary
+	})
+
+	now = func() time.Time {
+		return time.Date(2024, 12, 25, 10, 0, 0, 0, time.FixedZone("", -5*60*60))
+	}
+	newMessageID = func(domain string) string {
+		return "<1735138800000000000.0011223344556677@" + domain + ">"
+	}
+	n := 0
+	newBoundary = func() string {
+		n++
+		return fmt.Sprintf("gcli-boundary-%d", n)
+	}
+}
+
+func TestBuildMessageGolden(t *testing.T) {
+	tests := []struct {
+		name  string
+		email DraftEmail
+	}{
+		{
+			name: "plain_ascii",
+			email: DraftEmail{
+				To:      []string{"user@example.com"},
+				Subject: "Hello",
+				Body:    "Hi there,\nThis is a test.\n",
+			},
+		},
+		{
+			name: "unicode_subject",
+			email: DraftEmail{
+				To:      []string{"user@example.com"},
+				Subject: "Café meeting 🎉 会议の議題",
+				Body:    "See you there.\n",
+			},
+		},
+		{
+			name: "display_names",
+			email: DraftEmail{
+				From:    "José Núñez <jose@example.com>",
+				To:      []string{`"Doe, Jane" <jane@example.com>`, "Zoë Smith <zoe@example.com>"},
+				CC:      []string{"team@example.com"},
+				BCC:     []string{"audit@example.com"},
+				Subject: "Quarterly update",
+				Body:    "Hello all.\n",
+			},
+		},
+		{
+			name: "long_lines_quoted_printable",
+			email: DraftEmail{
+				To:      []string{"user@example.com"},
+				Subject: "A very long subject line that needs to be folded across more than one header line to stay within limits",
+				Body:    strings.Repeat("This line is deliberately long so it must be wrapped. ", 5) + "\nNaïve café résumé.\n",
+			},
+		},
+		{
+			name: "mostly_non_ascii_base64",
+			email: DraftEmail{
+				To:      []string{"user@example.com"},
+				Subject: "日本語",
+				Body:    "こんにちは世界。これはテストです。\n",
+			},
+		},
+		{
+			name: "html",
+			email: DraftEmail{
+				To:      []string{"user@example.com"},
+				Subject: "HTML",
+				Body:    "<p>Hello <b>world</b></p>",
+				IsHTML:  true,
+			},
+		},
+		{
+			name: "alternative_with_inline_image",
+			email: DraftEmail{
+				To:       []string{"user@example.com"},
+				Subject:  "Report",
+				Body:     "# Report\n\n![chart](chart.png)\n",
+				HTMLBody: "<h1>Report</h1>\n<p><img src=\"cid:chart@gcli\" alt=\"chart\"></p>\n",
+				InlineImages: []InlineImage{{
+					ContentID:   "chart@gcli",
+					Filename:    "chart.png",
+					ContentType: "image/png",
+					Data:        bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 30),
+				}},
+			},
+		},
+		{
+			name: "reply_with_attachment",
+			email: DraftEmail{
+				To:         []string{"user@example.com"},
+				Subject:    "Re: Invoice",
+				Body:       "Thanks, attached is the receipt.\n",
+				InReplyTo:  "<abc123@example.com>",
+				References: []string{"<root@example.com>", "<abc123@example.com>"},
+				Attachments: []Attachment{{
+					Filename:    "receipt café.pdf",
+					ContentType: "application/pdf",
+					Data:        bytes.Repeat([]byte("%PDF-1.4 "), 20),
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFixedMessageValues(t)
+
+			got, err := buildMessage(tt.email)
+			if err != nil {
+				t.Fatalf("buildMessage: %v", err)
+			}
+
+			golden := filepath.Join("testdata", tt.name+".eml")
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("reading golden file (run with -update to create): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("message does not match %s\n--- got ---\n%s\n--- want ---\n%s", golden, got, want)
+			}
+
+			checkWellFormed(t, got, tt.email)
+		})
+	}
+}
+
+// checkWellFormed verifies a generated message parses back to its inputs
+func checkWellFormed(t *testing.T, raw []byte, email DraftEmail) {
+	t.Helper()
+
+	for i, line := range strings.Split(string(raw), "\r\n") {
+		if len(line) > 998 {
+			t.Errorf("line %d exceeds 998 characters", i+1)
+		}
+		for _, r := range line {
+			if r >= 0x80 {
+				t.Errorf("line %d contains non-ASCII characters: %q", i+1, line)
+				break
+			}
+		}
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("generated message does not parse: %v", err)
+	}
+
+	dec := new(mime.WordDecoder)
+	subject, err := dec.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decoding subject: %v", err)
+	}
+	if subject != email.Subject {
+		t.Errorf("subject round-trip = %q, want %q", subject, email.Subject)
+	}
+
+	to, err := msg.Header.AddressList("To")
+	if err != nil {
+		t.Fatalf("parsing To: %v", err)
+	}
+	if len(to) != len(email.To) {
+		t.Errorf("got %d To addresses, want %d", len(to), len(email.To))
+	}
+
+	if _, err := msg.Header.Date(); err != nil {
+		t.Errorf("parsing Date: %v", err)
+	}
+}
+
+func TestBuildMessageInvalidAddress(t *testing.T) {
+	_, err := buildMessage(DraftEmail{
+		To:      []string{"not an address"},
+		Subject: "Hello",
+		Body:    "Hi",
+	})
+	if err == nil {
+		t.Fatal("expected error for invalid recipient")
+	}
+}
+
+func TestTransferEncoding(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{"short ascii\nlines\n", "7bit"},
+		{strings.Repeat("x", 100), "quoted-printable"},
+		{"mostly ascii with é", "quoted-printable"},
+		{"完全に日本語の本文", "base64"},
+	}
+	for _, tt := range tests {
+		if got := transferEncoding(tt.body); got != tt.want {
+			t.Errorf("transferEncoding(%q) = %s, want %s", tt.body, got, tt.want)
+		}
+	}
+}
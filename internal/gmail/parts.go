@@ -0,0 +1,74 @@
+package gmail
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/output"
+	"google.golang.org/api/gmail/v1"
+)
+
+// GetMessageHeaders returns every header of a message in order, with values
+// exactly as received
+func (c *Client) GetMessageHeaders(ctx context.Context, id string) ([]output.Header, error) {
+	msg, err := c.service.Users.Messages.Get("me", id).
+		Format("metadata").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	headers := make([]output.Header, 0, len(msg.Payload.Headers))
+	for _, h := range msg.Payload.Headers {
+		headers = append(headers, output.Header{Name: h.Name, Value: h.Value})
+	}
+	return headers, nil
+}
+
+// GetMIMETree returns the MIME part structure of a message, marking the
+// parts chosen as its text and HTML bodies
+func (c *Client) GetMIMETree(ctx context.Context, id string) (output.MIMEPart, error) {
+	msg, err := c.getFullMessage(ctx, id)
+	if err != nil {
+		return output.MIMEPart{}, err
+	}
+
+	plain, htmlPart := findBodyParts(msg.Payload)
+	return mimeTree(msg.Payload, plain, htmlPart), nil
+}
+
+// mimeTree describes a message part and its children
+func mimeTree(part, plain, htmlPart *gmail.MessagePart) output.MIMEPart {
+	headers := headerMap(part.Headers)
+
+	node := output.MIMEPart{
+		PartID:   part.PartId,
+		MimeType: part.MimeType,
+		Filename: part.Filename,
+		Encoding: strings.ToLower(headers["Content-Transfer-Encoding"]),
+	}
+	if _, params, err := mime.ParseMediaType(headers["Content-Type"]); err == nil {
+		node.Charset = params["charset"]
+	}
+	if disposition, _, err := mime.ParseMediaType(headers["Content-Disposition"]); err == nil {
+		node.Disposition = disposition
+	}
+	if part.Body != nil {
+		node.Size = part.Body.Size
+	}
+
+	switch part {
+	case plain:
+		node.Body = "text"
+	case htmlPart:
+		node.Body = "html"
+	}
+
+	for _, child := range part.Parts {
+		node.Parts = append(node.Parts, mimeTree(child, plain, htmlPart))
+	}
+	return node
+}
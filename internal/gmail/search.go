@@ -0,0 +1,404 @@
+package gmail
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/alexandraswan/gcli/internal/output"
+	"google.golang.org/api/gmail/v1"
+)
+
+// searchSchema creates the full-text index over cached messages. Index rows
+// share their rowid with the messages table.
+const searchSchema = `
+CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(
+	subject, sender, recipients, body, attachments,
+	tokenize = 'unicode61 remove_diacritics 2'
+);
+`
+
+// searchRank weights matches in the subject and addresses above the body
+const searchRank = `bm25(messages_fts, 10.0, 5.0, 3.0, 1.0, 2.0)`
+
+// searchFields maps query field prefixes to index columns
+var searchFields = map[string]string{
+	"subject":    "subject",
+	"from":       "sender",
+	"to":         "recipients",
+	"cc":         "recipients",
+	"body":       "body",
+	"filename":   "attachments",
+	"attachment": "attachments",
+}
+
+// SearchOptions filters a local search
+type SearchOptions struct {
+	Accounts []string
+	After    time.Time
+	Before   time.Time
+	Limit    int64
+}
+
+// indexMessage replaces the index entry for a cached message
+func (c *Cache) indexMessage(account string, msg *gmail.Message) error {
+	var rowID int64
+	err := c.db.QueryRow(`SELECT rowid FROM messages WHERE account = ? AND id = ?`, account, msg.Id).Scan(&rowID)
+	if err != nil {
+		return fmt.Errorf("failed to index message: %w", err)
+	}
+
+	headers := headerMap(msg.Payload.Headers)
+	var recipients []string
+	for _, a := range append(parseAddressList(headers["To"]), parseAddressList(headers["Cc"])...) {
+		recipients = append(recipients, a.String())
+	}
+
+	if _, err := c.db.Exec(`DELETE FROM messages_fts WHERE rowid = ?`, rowID); err != nil {
+		return fmt.Errorf("failed to index message: %w", err)
+	}
+	_, err = c.db.Exec(`INSERT INTO messages_fts (rowid, subject, sender, recipients, body, attachments)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		rowID,
+		decodeHeader(headers["Subject"]),
+		parseAddress(headers["From"]).String(),
+		strings.Join(recipients, " "),
+		extractBody(msg.Payload, BodyText),
+		strings.Join(extractAttachmentNames(msg.Payload), " "))
+	if err != nil {
+		return fmt.Errorf("failed to index message: %w", err)
+	}
+	return nil
+}
+
+// unindexMessage removes a message from the index
+func (c *Cache) unindexMessage(account, id string) error {
+	_, err := c.db.Exec(`DELETE FROM messages_fts WHERE rowid IN
+		(SELECT rowid FROM messages WHERE account = ? AND id = ?)`, account, id)
+	if err != nil {
+		return fmt.Errorf("failed to remove message from index: %w", err)
+	}
+	return nil
+}
+
+// reindex rebuilds the index from every cached message
+func (c *Cache) reindex() error {
+	rows, err := c.db.Query(`SELECT account, id FROM messages`)
+	if err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	type key struct{ account, id string }
+	var keys []key
+	for rows.Next() {
+		var k key
+		if err := rows.Scan(&k.account, &k.id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read cache: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	for _, k := range keys {
+		msg, _, err := c.loadMessage(k.account, k.id)
+		if err != nil {
+			return err
+		}
+		if err := c.indexMessage(k.account, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Search runs a full-text query over cached messages, best matches first.
+//
+// Terms are ANDed together. Supported syntax: "exact phrase", prefix*,
+// OR, -term or NOT term, parentheses, and field:term where field is one of
+// subject, from, to, cc, body, filename.
+func (c *Cache) Search(query string, opts SearchOptions) ([]output.EmailSummary, error) {
+	match, err := ftsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `SELECT m.account, m.message FROM messages_fts
+		JOIN messages m ON m.rowid = messages_fts.rowid
+		WHERE messages_fts MATCH ?
+		AND m.labels NOT LIKE '% SPAM %' AND m.labels NOT LIKE '% TRASH %'`
+	args := []interface{}{match}
+
+	if len(opts.Accounts) > 0 {
+		sqlQuery += ` AND m.account IN (?` + strings.Repeat(`, ?`, len(opts.Accounts)-1) + `)`
+		for _, a := range opts.Accounts {
+			args = append(args, a)
+		}
+	}
+	if !opts.After.IsZero() {
+		sqlQuery += ` AND m.internal_date >= ?`
+		args = append(args, opts.After.UnixMilli())
+	}
+	if !opts.Before.IsZero() {
+		sqlQuery += ` AND m.internal_date < ?`
+		args = append(args, opts.Before.UnixMilli())
+	}
+
+	sqlQuery += ` ORDER BY ` + searchRank
+	if opts.Limit > 0 {
+		sqlQuery += ` LIMIT ?`
+		args = append(args, opts.Limit)
+	}
+
+	rows, err := c.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search cache: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []output.EmailSummary
+	for rows.Next() {
+		var account, data string
+		if err := rows.Scan(&account, &data); err != nil {
+			return nil, fmt.Errorf("failed to search cache: %w", err)
+		}
+		msg, err := decodeCachedMessage(data)
+		if err != nil {
+			continue
+		}
+		summary := summaryFromMessage(msg)
+		summary.Account = account
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
+
+// ftsQuery translates a search query into an FTS5 MATCH expression. Every
+// term is quoted so punctuation in addresses and file names is matched
+// literally rather than parsed as FTS5 syntax.
+func ftsQuery(query string) (string, error) {
+	p := &queryParser{tokens: tokenizeQuery(query)}
+	if len(p.tokens) == 0 {
+		return "", fmt.Errorf("empty search query")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return "", err
+	}
+	if p.pos < len(p.tokens) {
+		return "", fmt.Errorf("unexpected '%s' in search query", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// tokenizeQuery splits a query into words, quoted phrases and parentheses
+func tokenizeQuery(query string) []string {
+	var tokens []string
+	var cur strings.Builder
+	inQuote := false
+
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for _, r := range query {
+		switch {
+		case inQuote:
+			cur.WriteRune(r)
+			if r == '"' {
+				inQuote = false
+			}
+		case r == '"':
+			cur.WriteRune(r)
+			inQuote = true
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if inQuote {
+		cur.WriteRune('"')
+	}
+	flush()
+	return tokens
+}
+
+// queryParser is a recursive descent parser over query tokens
+type queryParser struct {
+	tokens []string
+	pos    int
+}
+
+// parseOr parses terms separated by OR
+func (p *queryParser) parseOr() (string, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return "", err
+	}
+	parts := []string{left}
+	for p.pos < len(p.tokens) && p.tokens[p.pos] == "OR" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, right)
+	}
+	if len(parts) == 1 {
+		return left, nil
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", nil
+}
+
+// parseAnd parses a run of terms. FTS5 only supports NOT as a binary
+// operator, so negated terms are applied after all positive ones.
+func (p *queryParser) parseAnd() (string, error) {
+	var include, exclude []string
+	for p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+		if tok == "OR" || tok == ")" {
+			break
+		}
+
+		negate := false
+		switch {
+		case tok == "NOT":
+			negate = true
+			p.pos++
+		case tok == "-":
+			// The tokenizer splits "-(" into "-" and "("; a "-" on its own
+			// negates nothing
+			if p.pos+1 >= len(p.tokens) || p.tokens[p.pos+1] != "(" {
+				return "", fmt.Errorf("'-' must be followed by a term without a space, such as -draft")
+			}
+			negate = true
+			p.pos++
+		case strings.HasPrefix(tok, "-"):
+			negate = true
+			p.tokens[p.pos] = tok[1:]
+		case tok == "AND":
+			if len(include) == 0 && len(exclude) == 0 {
+				return "", fmt.Errorf("'AND' must be between two terms")
+			}
+			p.pos++
+		}
+		if err := p.expectTerm(tok); err != nil {
+			return "", err
+		}
+		if tok == "AND" {
+			continue
+		}
+
+		term, err := p.parseTerm()
+		if err != nil {
+			return "", err
+		}
+		if negate {
+			exclude = append(exclude, term)
+		} else {
+			include = append(include, term)
+		}
+	}
+
+	if len(include) == 0 {
+		if len(exclude) > 0 {
+			return "", fmt.Errorf("search query needs at least one term that is not excluded")
+		}
+		return "", fmt.Errorf("incomplete search query")
+	}
+
+	expr := strings.Join(include, " AND ")
+	if len(include) > 1 || len(exclude) > 0 {
+		expr = "(" + expr + ")"
+	}
+	for _, term := range exclude {
+		expr = "(" + expr + " NOT " + term + ")"
+	}
+	return expr, nil
+}
+
+// expectTerm checks that the operator just read is followed by a term
+func (p *queryParser) expectTerm(op string) error {
+	if op != "NOT" && op != "AND" && op != "-" {
+		return nil
+	}
+	if p.pos >= len(p.tokens) {
+		return fmt.Errorf("'%s' must be followed by a term", op)
+	}
+	switch next := p.tokens[p.pos]; next {
+	case "OR", "AND", "NOT", ")":
+		return fmt.Errorf("'%s' must be followed by a term, not '%s'", op, next)
+	}
+	return nil
+}
+
+// parseTerm parses a parenthesized group or a single, optionally fielded,
+// word or phrase
+func (p *queryParser) parseTerm() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("incomplete search query")
+	}
+
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	if tok == "(" {
+		expr, err := p.parseOr()
+		if err != nil {
+			return "", err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return "", fmt.Errorf("missing ')' in search query")
+		}
+		p.pos++
+		return expr, nil
+	}
+	if tok == ")" {
+		return "", fmt.Errorf("unexpected ')' in search query")
+	}
+
+	column := ""
+	// Words with a colon that is not a known field, such as URLs, are
+	// searched for literally
+	if i := strings.Index(tok, ":"); i > 0 && !strings.HasPrefix(tok, `"`) {
+		field := strings.ToLower(tok[:i])
+		if col, ok := searchFields[field]; ok {
+			column = col
+			tok = tok[i+1:]
+			if tok == "" {
+				return "", fmt.Errorf("missing value for '%s:'", field)
+			}
+		}
+	}
+
+	prefix := false
+	if strings.HasSuffix(tok, "*") && !strings.HasSuffix(tok, `"`) {
+		prefix = true
+		tok = strings.TrimSuffix(tok, "*")
+	}
+	tok = strings.Trim(tok, `"`)
+	if tok == "" {
+		return "", fmt.Errorf("empty term in search query")
+	}
+
+	term := `"` + strings.ReplaceAll(tok, `"`, `""`) + `"`
+	if prefix {
+		term += "*"
+	}
+	if column != "" {
+		term = column + " : " + term
+	}
+	return term, nil
+}
@@ -0,0 +1,65 @@
+package gmail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    string
+		wantErr string
+	}{
+		{query: "budget", want: `"budget"`},
+		{query: "budget report", want: `("budget" AND "report")`},
+		{query: "budget AND report", want: `("budget" AND "report")`},
+		{query: `"quarterly report"`, want: `"quarterly report"`},
+		{query: "budget OR forecast", want: `("budget" OR "forecast")`},
+		{query: "budget -draft", want: `(("budget") NOT "draft")`},
+		{query: "budget NOT draft", want: `(("budget") NOT "draft")`},
+		{query: "(budget OR forecast) q3", want: `(("budget" OR "forecast") AND "q3")`},
+		{query: "q3 -(draft OR old)", want: `(("q3") NOT ("draft" OR "old"))`},
+		{query: "q3 NOT (draft OR old)", want: `(("q3") NOT ("draft" OR "old"))`},
+		{query: "budg*", want: `"budg"*`},
+		{query: "from:alice to:Bob", want: `(sender : "alice" AND recipients : "Bob")`},
+		{query: "-from:alice budget", want: `(("budget") NOT sender : "alice")`},
+		{query: `subject:"offsite plan"`, want: `subject : "offsite plan"`},
+		{query: "https://example.com/a", want: `"https://example.com/a"`},
+		{query: `say "hi`, want: `("say" AND "hi")`},
+		{query: `a"b`, want: `"a""b"`},
+
+		{query: "", wantErr: "empty search query"},
+		{query: "-draft", wantErr: "not excluded"},
+		{query: "budget - draft", wantErr: "'-' must be followed by a term"},
+		{query: "budget -", wantErr: "'-' must be followed by a term"},
+		{query: "a AND", wantErr: "'AND' must be followed by a term"},
+		{query: "AND a", wantErr: "'AND' must be between two terms"},
+		{query: "a AND OR b", wantErr: "'AND' must be followed by a term, not 'OR'"},
+		{query: "a NOT", wantErr: "'NOT' must be followed by a term"},
+		{query: "a NOT )", wantErr: "'NOT' must be followed by a term, not ')'"},
+		{query: "a OR", wantErr: "incomplete search query"},
+		{query: "(a OR b", wantErr: "missing ')'"},
+		{query: "a)", wantErr: "unexpected ')'"},
+		{query: "from:", wantErr: "missing value for 'from:'"},
+		{query: `""`, wantErr: "empty term"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := ftsQuery(tt.query)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ftsQuery(%q) = %q, %v, want error containing %q", tt.query, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ftsQuery(%q) error = %v", tt.query, err)
+			}
+			if got != tt.want {
+				t.Errorf("ftsQuery(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
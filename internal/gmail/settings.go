@@ -0,0 +1,195 @@
+package gmail
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/output"
+	"google.golang.org/api/gmail/v1"
+)
+
+// Allowed values of the enumerated settings
+var (
+	Dispositions     = []string{"leaveInInbox", "archive", "trash", "markRead"}
+	ExpungeBehaviors = []string{"archive", "trash", "deleteForever"}
+	AccessWindows    = []string{"disabled", "fromNowOn", "allMail"}
+	MaxFolderSizes   = []int64{0, 1000, 2000, 5000, 10000}
+)
+
+// GetSettings gets the account's forwarding, IMAP, POP and language
+// settings
+func (c *Client) GetSettings(ctx context.Context) (output.MailSettings, error) {
+	settings := output.MailSettings{Account: c.accountName}
+	s := c.service.Users.Settings
+
+	addresses, err := s.ForwardingAddresses.List("me").Context(ctx).Do()
+	if err != nil {
+		return settings, fmt.Errorf("failed to list forwarding addresses: %w", err)
+	}
+	for _, a := range addresses.ForwardingAddresses {
+		settings.ForwardingAddresses = append(settings.ForwardingAddresses, output.ForwardingAddress{
+			Email:              a.ForwardingEmail,
+			VerificationStatus: a.VerificationStatus,
+		})
+	}
+
+	fwd, err := s.GetAutoForwarding("me").Context(ctx).Do()
+	if err != nil {
+		return settings, fmt.Errorf("failed to get auto-forwarding settings: %w", err)
+	}
+	settings.AutoForwarding = &output.AutoForwarding{
+		Enabled:     &fwd.Enabled,
+		Email:       fwd.EmailAddress,
+		Disposition: fwd.Disposition,
+	}
+
+	imap, err := s.GetImap("me").Context(ctx).Do()
+	if err != nil {
+		return settings, fmt.Errorf("failed to get IMAP settings: %w", err)
+	}
+	settings.IMAP = &output.IMAPSettings{
+		Enabled:         &imap.Enabled,
+		AutoExpunge:     &imap.AutoExpunge,
+		ExpungeBehavior: imap.ExpungeBehavior,
+		MaxFolderSize:   &imap.MaxFolderSize,
+	}
+
+	pop, err := s.GetPop("me").Context(ctx).Do()
+	if err != nil {
+		return settings, fmt.Errorf("failed to get POP settings: %w", err)
+	}
+	settings.POP = &output.POPSettings{
+		AccessWindow: pop.AccessWindow,
+		Disposition:  pop.Disposition,
+	}
+
+	lang, err := s.GetLanguage("me").Context(ctx).Do()
+	if err != nil {
+		return settings, fmt.Errorf("failed to get language settings: %w", err)
+	}
+	settings.Language = &output.LanguageSettings{DisplayLanguage: lang.DisplayLanguage}
+
+	return settings, nil
+}
+
+// AddForwardingAddress adds an address mail can be forwarded to. Gmail
+// sends it a verification email unless it belongs to the same domain.
+func (c *Client) AddForwardingAddress(ctx context.Context, email string) (output.ForwardingAddress, error) {
+	a, err := c.service.Users.Settings.ForwardingAddresses.Create("me", &gmail.ForwardingAddress{ForwardingEmail: email}).Context(ctx).Do()
+	if err != nil {
+		return output.ForwardingAddress{}, fmt.Errorf("failed to add forwarding address %s: %w", email, err)
+	}
+	return output.ForwardingAddress{Email: a.ForwardingEmail, VerificationStatus: a.VerificationStatus}, nil
+}
+
+// RemoveForwardingAddress removes a forwarding address
+func (c *Client) RemoveForwardingAddress(ctx context.Context, email string) error {
+	if err := c.service.Users.Settings.ForwardingAddresses.Delete("me", email).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to remove forwarding address %s: %w", email, err)
+	}
+	return nil
+}
+
+// SetAutoForwarding updates the auto-forwarding settings. The address must
+// be a verified forwarding address.
+func (c *Client) SetAutoForwarding(ctx context.Context, f output.AutoForwarding) error {
+	_, err := c.service.Users.Settings.UpdateAutoForwarding("me", &gmail.AutoForwarding{
+		Enabled:         f.IsEnabled(),
+		EmailAddress:    f.Email,
+		Disposition:     f.Disposition,
+		ForceSendFields: []string{"Enabled"},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to update auto-forwarding settings: %w", err)
+	}
+	return nil
+}
+
+// SetIMAP updates the IMAP settings
+func (c *Client) SetIMAP(ctx context.Context, s output.IMAPSettings) error {
+	_, err := c.service.Users.Settings.UpdateImap("me", &gmail.ImapSettings{
+		Enabled:         s.IsEnabled(),
+		AutoExpunge:     s.IsAutoExpunge(),
+		ExpungeBehavior: s.ExpungeBehavior,
+		MaxFolderSize:   s.FolderLimit(),
+		ForceSendFields: []string{"Enabled", "AutoExpunge", "MaxFolderSize"},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to update IMAP settings: %w", err)
+	}
+	return nil
+}
+
+// SetPOP updates the POP settings
+func (c *Client) SetPOP(ctx context.Context, s output.POPSettings) error {
+	_, err := c.service.Users.Settings.UpdatePop("me", &gmail.PopSettings{
+		AccessWindow: s.AccessWindow,
+		Disposition:  s.Disposition,
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to update POP settings: %w", err)
+	}
+	return nil
+}
+
+// SetLanguage updates the display language
+func (c *Client) SetLanguage(ctx context.Context, s output.LanguageSettings) error {
+	_, err := c.service.Users.Settings.UpdateLanguage("me", &gmail.LanguageSettings{
+		DisplayLanguage: s.DisplayLanguage,
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to update language settings: %w", err)
+	}
+	return nil
+}
+
+// ValidateSettings checks the enumerated values in a settings profile.
+// Values left out are not checked, since they keep their current value.
+func ValidateSettings(s output.MailSettings) error {
+	if f := s.AutoForwarding; f != nil {
+		if f.IsEnabled() && f.Email == "" {
+			return fmt.Errorf("auto_forwarding: email is required when enabled")
+		}
+		if f.Disposition != "" {
+			if err := checkValue("auto_forwarding disposition", f.Disposition, Dispositions); err != nil {
+				return err
+			}
+		}
+	}
+	if i := s.IMAP; i != nil {
+		if i.ExpungeBehavior != "" {
+			if err := checkValue("imap expunge_behavior", i.ExpungeBehavior, ExpungeBehaviors); err != nil {
+				return err
+			}
+		}
+		if i.MaxFolderSize != nil && !slices.Contains(MaxFolderSizes, *i.MaxFolderSize) {
+			return fmt.Errorf("invalid imap max_folder_size %d (use 0, 1000, 2000, 5000, or 10000)", *i.MaxFolderSize)
+		}
+	}
+	if p := s.POP; p != nil {
+		if p.AccessWindow != "" {
+			if err := checkValue("pop access_window", p.AccessWindow, AccessWindows); err != nil {
+				return err
+			}
+		}
+		if p.Disposition != "" {
+			if err := checkValue("pop disposition", p.Disposition, Dispositions); err != nil {
+				return err
+			}
+		}
+	}
+	if l := s.Language; l != nil && l.DisplayLanguage == "" {
+		return fmt.Errorf("language: display_language is required")
+	}
+	return nil
+}
+
+// checkValue reports an error unless value is one of allowed
+func checkValue(name, value string, allowed []string) error {
+	if slices.Contains(allowed, value) {
+		return nil
+	}
+	return fmt.Errorf("invalid %s '%s' (use %s)", name, value, strings.Join(allowed, ", "))
+}
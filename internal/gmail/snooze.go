@@ -0,0 +1,218 @@
+package gmail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"github.com/alexandraswan/gcli/internal/output"
+)
+
+const snoozedFileName = "snoozed.json"
+
+// SnoozedLabel is applied to snoozed threads so they can be found in Gmail
+const SnoozedLabel = "gcli/snoozed"
+
+// SnoozedThreadData represents a stored snoozed thread
+type SnoozedThreadData struct {
+	ID        string    `json:"id"`
+	Account   string    `json:"account"`
+	ThreadID  string    `json:"thread_id"`
+	From      string    `json:"from"`
+	Subject   string    `json:"subject"`
+	Until     time.Time `json:"until"`
+	SnoozedAt time.Time `json:"snoozed_at"`
+}
+
+// getSnoozedPath returns the path to the snoozed threads file
+func getSnoozedPath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, snoozedFileName), nil
+}
+
+// LoadSnoozedThreads loads all snoozed threads
+func LoadSnoozedThreads() ([]SnoozedThreadData, error) {
+	path, err := getSnoozedPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []SnoozedThreadData{}, nil
+		}
+		return nil, fmt.Errorf("failed to read snoozed threads: %w", err)
+	}
+
+	var threads []SnoozedThreadData
+	if err := json.Unmarshal(data, &threads); err != nil {
+		return nil, fmt.Errorf("failed to parse snoozed threads: %w", err)
+	}
+
+	return threads, nil
+}
+
+// SaveSnoozedThreads saves all snoozed threads
+func SaveSnoozedThreads(threads []SnoozedThreadData) error {
+	if err := config.EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	path, err := getSnoozedPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(threads, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snoozed threads: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write snoozed threads: %w", err)
+	}
+
+	return nil
+}
+
+// AddSnoozedThread records a snoozed thread, replacing an earlier snooze of
+// the same thread
+func AddSnoozedThread(thread SnoozedThreadData) error {
+	threads, err := LoadSnoozedThreads()
+	if err != nil {
+		return err
+	}
+
+	thread.ID = generateID()
+	thread.SnoozedAt = time.Now()
+
+	var result []SnoozedThreadData
+	for _, t := range threads {
+		if t.Account != thread.Account || t.ThreadID != thread.ThreadID {
+			result = append(result, t)
+		}
+	}
+	result = append(result, thread)
+
+	return SaveSnoozedThreads(result)
+}
+
+// RemoveSnoozedThread removes a snoozed thread by ID
+func RemoveSnoozedThread(id string) error {
+	threads, err := LoadSnoozedThreads()
+	if err != nil {
+		return err
+	}
+
+	var result []SnoozedThreadData
+	found := false
+	for _, t := range threads {
+		if t.ID == id {
+			found = true
+			continue
+		}
+		result = append(result, t)
+	}
+
+	if !found {
+		return fmt.Errorf("snoozed thread '%s' not found", id)
+	}
+
+	return SaveSnoozedThreads(result)
+}
+
+// GetSnoozedThreadsByAccount returns snoozed threads for display, soonest
+// wake-up first
+func GetSnoozedThreadsByAccount(accountName string) ([]output.SnoozedThread, error) {
+	threads, err := LoadSnoozedThreads()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []output.SnoozedThread
+	for _, t := range threads {
+		if accountName == "" || t.Account == accountName {
+			result = append(result, output.SnoozedThread{
+				ID:        t.ID,
+				Account:   t.Account,
+				ThreadID:  t.ThreadID,
+				From:      t.From,
+				Subject:   t.Subject,
+				Until:     t.Until,
+				SnoozedAt: t.SnoozedAt,
+			})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Until.Before(result[j].Until) })
+	return result, nil
+}
+
+// GetDueSnoozedThreads returns snoozed threads whose wake time has passed
+func GetDueSnoozedThreads(accountName string) ([]SnoozedThreadData, error) {
+	threads, err := LoadSnoozedThreads()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var due []SnoozedThreadData
+	for _, t := range threads {
+		if !t.Until.After(now) && (accountName == "" || t.Account == accountName) {
+			due = append(due, t)
+		}
+	}
+
+	return due, nil
+}
+
+// SnoozeMessage archives the thread a message belongs to and labels it as
+// snoozed. It returns the thread's details for recording the snooze.
+func (c *Client) SnoozeMessage(ctx context.Context, messageID string) (SnoozedThreadData, error) {
+	msg, err := c.getMessageMetadata(ctx, messageID)
+	if err != nil {
+		return SnoozedThreadData{}, fmt.Errorf("failed to get message: %w", err)
+	}
+	summary := summaryFromMessage(msg)
+
+	labelID, err := c.EnsureLabel(ctx, SnoozedLabel)
+	if err != nil {
+		return SnoozedThreadData{}, err
+	}
+
+	if err := c.ModifyThread(ctx, msg.ThreadId, []string{labelID}, []string{"INBOX"}); err != nil {
+		return SnoozedThreadData{}, err
+	}
+
+	return SnoozedThreadData{
+		Account:  c.accountName,
+		ThreadID: msg.ThreadId,
+		From:     summary.From,
+		Subject:  summary.Subject,
+	}, nil
+}
+
+// WakeThread moves a snoozed thread back to the inbox, marks it unread and
+// removes the snoozed label
+func (c *Client) WakeThread(ctx context.Context, threadID string) error {
+	labelID, err := c.EnsureLabel(ctx, SnoozedLabel)
+	if err != nil {
+		return err
+	}
+
+	err = c.ModifyThread(ctx, threadID, []string{"INBOX", "UNREAD"}, []string{labelID})
+	if isNotFound(err) {
+		// The thread was deleted while snoozed
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,339 @@
+package gmail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/output"
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// statsWorkers is the number of concurrent metadata requests made when
+// collecting statistics
+const statsWorkers = 8
+
+// statsRetries is how many times a rate-limited or failed metadata request
+// is retried
+const statsRetries = 3
+
+// statsRetryDelay is the wait before the first retry; it doubles after each
+// attempt
+const statsRetryDelay = time.Second
+
+// StatMessage holds the metadata of a message used for mailbox statistics
+type StatMessage struct {
+	Account  string
+	ThreadID string
+	From     output.Address
+	Labels   []string
+	Date     time.Time
+	Sent     bool
+	Unread   bool
+}
+
+// ListStatMessages fetches the metadata needed for statistics for messages
+// matching a query. Only the From header is requested to keep quota use
+// low. Drafts and messages deleted since they were listed are skipped.
+// Messages that still cannot be fetched after retrying are left out and
+// counted in failed.
+func (c *Client) ListStatMessages(ctx context.Context, query string, limit int64) ([]StatMessage, int, error) {
+	refs, err := c.ListMessageIDs(ctx, query, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	labels, err := c.listLabels(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	names := make(map[string]string, len(labels))
+	for _, l := range labels {
+		names[l.Id] = l.Name
+	}
+
+	results := make([]*StatMessage, len(refs))
+	var failed atomic.Int64
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range statsWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				msg, err := c.getStatMetadata(ctx, refs[i].ID)
+				if err != nil {
+					if !isNotFound(err) {
+						failed.Add(1)
+					}
+					continue
+				}
+
+				sm := &StatMessage{
+					Account:  c.accountName,
+					ThreadID: msg.ThreadId,
+					From:     parseAddress(headerMap(msg.Payload.Headers)["From"]),
+					Date:     time.UnixMilli(msg.InternalDate),
+				}
+				skip := false
+				for _, id := range msg.LabelIds {
+					switch id {
+					case "DRAFT":
+						skip = true
+					case "SENT":
+						sm.Sent = true
+					case "UNREAD":
+						sm.Unread = true
+					}
+					if name, ok := names[id]; ok {
+						sm.Labels = append(sm.Labels, name)
+					} else {
+						sm.Labels = append(sm.Labels, id)
+					}
+				}
+				if !skip {
+					results[i] = sm
+				}
+			}
+		}()
+	}
+
+	for i := range refs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var msgs []StatMessage
+	for _, sm := range results {
+		if sm != nil {
+			msgs = append(msgs, *sm)
+		}
+	}
+	return msgs, int(failed.Load()), nil
+}
+
+// getStatMetadata fetches a message's labels and From header, retrying
+// temporary failures with exponential backoff
+func (c *Client) getStatMetadata(ctx context.Context, id string) (*gmail.Message, error) {
+	delay := statsRetryDelay
+	for attempt := 0; ; attempt++ {
+		msg, err := c.service.Users.Messages.Get("me", id).
+			Format("metadata").
+			MetadataHeaders("From").
+			Context(ctx).
+			Do()
+		if err == nil {
+			return msg, nil
+		}
+		if attempt == statsRetries || !isTemporary(err) {
+			return nil, fmt.Errorf("failed to get message: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// isTemporary reports whether an API error is worth retrying: rate limiting
+// or a server error
+func isTemporary(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= 500 {
+		return true
+	}
+	// Gmail also reports rate limiting as 403
+	for _, e := range apiErr.Errors {
+		if e.Reason == "rateLimitExceeded" || e.Reason == "userRateLimitExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
+// ComputeStats aggregates message metadata into mailbox statistics. Sender,
+// domain, time and unread counts cover received mail only. Reply latency is
+// the time from a received message to the next message sent in the same
+// thread. Lists are cut to the top entries when top is positive.
+func ComputeStats(msgs []StatMessage, top int, now time.Time) output.MailStats {
+	var stats output.MailStats
+
+	senders := make(map[string]int)
+	senderNames := make(map[string]output.Address)
+	domains := make(map[string]int)
+	labels := make(map[string]int)
+	weekdays := make([]int, 7)
+	hours := make([]int, 24)
+	threads := make(map[string][]StatMessage)
+
+	unreadBuckets := []string{"< 1 day", "1-7 days", "1-4 weeks", "> 4 weeks"}
+	unread := make([]int, len(unreadBuckets))
+
+	for _, m := range msgs {
+		stats.Messages++
+		key := m.Account + "/" + m.ThreadID
+		threads[key] = append(threads[key], m)
+
+		for _, l := range m.Labels {
+			labels[l]++
+		}
+
+		if m.Sent {
+			stats.Sent++
+			continue
+		}
+		stats.Received++
+
+		email := strings.ToLower(m.From.Email)
+		senders[email]++
+		if _, ok := senderNames[email]; !ok || senderNames[email].Name == "" {
+			senderNames[email] = m.From
+		}
+		if _, domain, ok := strings.Cut(email, "@"); ok {
+			domains[domain]++
+		}
+
+		local := m.Date.Local()
+		// Weeks start on Monday
+		weekdays[(int(local.Weekday())+6)%7]++
+		hours[local.Hour()]++
+
+		if m.Unread {
+			age := now.Sub(m.Date)
+			switch {
+			case age < 24*time.Hour:
+				unread[0]++
+			case age < 7*24*time.Hour:
+				unread[1]++
+			case age < 28*24*time.Hour:
+				unread[2]++
+			default:
+				unread[3]++
+			}
+		}
+	}
+
+	stats.Threads = len(threads)
+
+	for email, n := range senders {
+		stats.Senders = append(stats.Senders, output.StatCount{Key: senderNames[email].String(), Count: n})
+	}
+	stats.Senders = topCounts(stats.Senders, top)
+
+	for domain, n := range domains {
+		stats.Domains = append(stats.Domains, output.StatCount{Key: domain, Count: n})
+	}
+	stats.Domains = topCounts(stats.Domains, top)
+
+	for label, n := range labels {
+		stats.Labels = append(stats.Labels, output.StatCount{Key: label, Count: n})
+	}
+	stats.Labels = topCounts(stats.Labels, top)
+
+	for i, day := range []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"} {
+		stats.Weekdays = append(stats.Weekdays, output.StatCount{Key: day, Count: weekdays[i]})
+	}
+	for h, n := range hours {
+		stats.Hours = append(stats.Hours, output.StatCount{Key: fmt.Sprintf("%02d:00", h), Count: n})
+	}
+	for i, bucket := range unreadBuckets {
+		stats.UnreadAge = append(stats.UnreadAge, output.StatCount{Key: bucket, Count: unread[i]})
+	}
+
+	lengthBuckets := []string{"1", "2", "3-5", "6-10", "> 10"}
+	lengths := make([]int, len(lengthBuckets))
+	var latencies []time.Duration
+	for _, thread := range threads {
+		switch n := len(thread); {
+		case n == 1:
+			lengths[0]++
+		case n == 2:
+			lengths[1]++
+		case n <= 5:
+			lengths[2]++
+		case n <= 10:
+			lengths[3]++
+		default:
+			lengths[4]++
+		}
+
+		sort.Slice(thread, func(i, j int) bool { return thread[i].Date.Before(thread[j].Date) })
+		for i := 1; i < len(thread); i++ {
+			if thread[i].Sent && !thread[i-1].Sent {
+				latencies = append(latencies, thread[i].Date.Sub(thread[i-1].Date))
+			}
+		}
+	}
+	for i, bucket := range lengthBuckets {
+		stats.ThreadLengths = append(stats.ThreadLengths, output.StatCount{Key: bucket, Count: lengths[i]})
+	}
+
+	stats.ReplyLatency = replyLatency(latencies)
+	return stats
+}
+
+// replyLatency summarizes reply times
+func replyLatency(latencies []time.Duration) output.ReplyLatency {
+	bucketNames := []string{"< 1 hour", "1-4 hours", "4-24 hours", "1-3 days", "> 3 days"}
+	buckets := make([]int, len(bucketNames))
+
+	result := output.ReplyLatency{Replies: len(latencies)}
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		var total time.Duration
+		for _, d := range latencies {
+			total += d
+			switch {
+			case d < time.Hour:
+				buckets[0]++
+			case d < 4*time.Hour:
+				buckets[1]++
+			case d < 24*time.Hour:
+				buckets[2]++
+			case d < 72*time.Hour:
+				buckets[3]++
+			default:
+				buckets[4]++
+			}
+		}
+
+		result.MedianSeconds = int64(latencies[len(latencies)/2].Seconds())
+		result.MeanSeconds = int64((total / time.Duration(len(latencies))).Seconds())
+		result.P90Seconds = int64(latencies[len(latencies)*9/10].Seconds())
+	}
+
+	for i, name := range bucketNames {
+		result.Buckets = append(result.Buckets, output.StatCount{Key: name, Count: buckets[i]})
+	}
+	return result
+}
+
+// topCounts sorts counts in descending order and keeps the first n
+func topCounts(counts []output.StatCount, n int) []output.StatCount {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Key < counts[j].Key
+	})
+	if n > 0 && len(counts) > n {
+		counts = counts[:n]
+	}
+	return counts
+}
@@ -0,0 +1,278 @@
+package gmail
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/output"
+)
+
+func TestComputeStats(t *testing.T) {
+	// Weekdays and hours are counted in local time
+	origLocal := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = origLocal })
+
+	now := time.Date(2024, 12, 30, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+	addr := func(email string) output.Address { return output.Address{Email: email} }
+	received := func(account, thread string, from output.Address, date time.Time) StatMessage {
+		return StatMessage{Account: account, ThreadID: thread, From: from, Date: date, Labels: []string{"INBOX"}}
+	}
+	sent := func(thread string, date time.Time) StatMessage {
+		return StatMessage{Account: "work", ThreadID: thread, From: addr("me@example.com"), Date: date, Sent: true, Labels: []string{"SENT"}}
+	}
+	unread := func(m StatMessage) StatMessage {
+		m.Unread = true
+		return m
+	}
+
+	tests := []struct {
+		name          string
+		msgs          []StatMessage
+		top           int
+		messages      int
+		received      int
+		sent          int
+		threads       int
+		senders       []output.StatCount
+		domains       []output.StatCount
+		labels        []output.StatCount
+		weekdays      []int
+		hours         map[int]int
+		unreadAge     []int
+		threadLengths []int
+		latency       output.ReplyLatency
+		replyBuckets  []int
+	}{
+		{
+			name:          "empty",
+			weekdays:      []int{0, 0, 0, 0, 0, 0, 0},
+			unreadAge:     []int{0, 0, 0, 0},
+			threadLengths: []int{0, 0, 0, 0, 0},
+		},
+		{
+			name: "senders merged case-insensitively and cut to top",
+			msgs: []StatMessage{
+				received("work", "t1", addr("alice@a.example"), ago(time.Hour)),
+				received("work", "t2", output.Address{Name: "Alice", Email: "ALICE@a.example"}, ago(time.Hour)),
+				received("work", "t3", addr("bob@b.example"), ago(time.Hour)),
+				received("work", "t4", addr("carol@a.example"), ago(time.Hour)),
+				received("work", "t5", addr("bob@b.example"), ago(time.Hour)),
+				received("work", "t6", addr("alice@a.example"), ago(time.Hour)),
+			},
+			top:      2,
+			messages: 6,
+			received: 6,
+			threads:  6,
+			senders: []output.StatCount{
+				{Key: "Alice <ALICE@a.example>", Count: 3},
+				{Key: "bob@b.example", Count: 2},
+			},
+			domains: []output.StatCount{
+				{Key: "a.example", Count: 4},
+				{Key: "b.example", Count: 2},
+			},
+			labels:        []output.StatCount{{Key: "INBOX", Count: 6}},
+			unreadAge:     []int{0, 0, 0, 0},
+			threadLengths: []int{6, 0, 0, 0, 0},
+		},
+		{
+			name: "sent mail counts towards threads and labels only",
+			msgs: []StatMessage{
+				received("work", "t1", addr("alice@a.example"), ago(time.Hour)),
+				sent("t1", ago(30*time.Minute)),
+				sent("t2", ago(time.Hour)),
+			},
+			messages: 3,
+			received: 1,
+			sent:     2,
+			threads:  2,
+			senders:  []output.StatCount{{Key: "alice@a.example", Count: 1}},
+			domains:  []output.StatCount{{Key: "a.example", Count: 1}},
+			labels: []output.StatCount{
+				{Key: "SENT", Count: 2},
+				{Key: "INBOX", Count: 1},
+			},
+			unreadAge:     []int{0, 0, 0, 0},
+			threadLengths: []int{1, 1, 0, 0, 0},
+			latency: output.ReplyLatency{
+				Replies:       1,
+				MedianSeconds: 1800,
+				MeanSeconds:   1800,
+				P90Seconds:    1800,
+			},
+		},
+		{
+			name: "reply latency only counts replies to received mail",
+			msgs: []StatMessage{
+				received("work", "t1", addr("alice@a.example"), ago(10*time.Hour)),
+				sent("t1", ago(8*time.Hour+30*time.Minute)),
+				received("work", "t2", addr("bob@b.example"), ago(5*time.Hour)),
+				sent("t2", ago(4*time.Hour+30*time.Minute)),
+				// A follow-up to our own email is not a reply
+				sent("t2", ago(4*time.Hour)),
+				received("work", "t3", addr("carol@c.example"), ago(100*time.Hour)),
+				sent("t3", ago(time.Hour)),
+			},
+			top:           1,
+			messages:      7,
+			received:      3,
+			sent:          4,
+			threads:       3,
+			senders:       []output.StatCount{{Key: "alice@a.example", Count: 1}},
+			domains:       []output.StatCount{{Key: "a.example", Count: 1}},
+			labels:        []output.StatCount{{Key: "SENT", Count: 4}},
+			unreadAge:     []int{0, 0, 0, 0},
+			threadLengths: []int{0, 2, 1, 0, 0},
+			// 30 minutes, 90 minutes and 99 hours
+			latency: output.ReplyLatency{
+				Replies:       3,
+				MedianSeconds: 5400,
+				MeanSeconds:   (1800 + 5400 + 99*3600) / 3,
+				P90Seconds:    99 * 3600,
+			},
+			replyBuckets: []int{1, 1, 0, 0, 1},
+		},
+		{
+			name: "unread mail by age",
+			msgs: []StatMessage{
+				unread(received("work", "t1", addr("a@a.example"), ago(2*time.Hour))),
+				unread(received("work", "t2", addr("a@a.example"), ago(3*24*time.Hour))),
+				unread(received("work", "t3", addr("a@a.example"), ago(10*24*time.Hour))),
+				unread(received("work", "t4", addr("a@a.example"), ago(40*24*time.Hour))),
+				unread(received("work", "t5", addr("a@a.example"), ago(50*24*time.Hour))),
+				received("work", "t6", addr("a@a.example"), ago(2*time.Hour)),
+			},
+			top:           1,
+			messages:      6,
+			received:      6,
+			threads:       6,
+			senders:       []output.StatCount{{Key: "a@a.example", Count: 6}},
+			domains:       []output.StatCount{{Key: "a.example", Count: 6}},
+			labels:        []output.StatCount{{Key: "INBOX", Count: 6}},
+			unreadAge:     []int{1, 1, 1, 2},
+			threadLengths: []int{6, 0, 0, 0, 0},
+		},
+		{
+			name: "threads are counted per account",
+			msgs: []StatMessage{
+				received("work", "t1", addr("a@a.example"), ago(time.Hour)),
+				received("personal", "t1", addr("a@a.example"), ago(time.Hour)),
+				received("work", "t2", addr("a@a.example"), ago(time.Hour)),
+				received("work", "t2", addr("a@a.example"), ago(time.Hour)),
+				received("work", "t2", addr("a@a.example"), ago(time.Hour)),
+			},
+			top:           1,
+			messages:      5,
+			received:      5,
+			threads:       3,
+			senders:       []output.StatCount{{Key: "a@a.example", Count: 5}},
+			domains:       []output.StatCount{{Key: "a.example", Count: 5}},
+			labels:        []output.StatCount{{Key: "INBOX", Count: 5}},
+			unreadAge:     []int{0, 0, 0, 0},
+			threadLengths: []int{2, 0, 1, 0, 0},
+		},
+		{
+			name: "weekdays start on Monday",
+			msgs: []StatMessage{
+				received("work", "t1", addr("a@a.example"), time.Date(2024, 12, 23, 8, 15, 0, 0, time.UTC)),
+				received("work", "t2", addr("a@a.example"), time.Date(2024, 12, 29, 23, 59, 0, 0, time.UTC)),
+				received("work", "t3", addr("a@a.example"), time.Date(2024, 12, 29, 8, 0, 0, 0, time.UTC)),
+			},
+			top:           1,
+			messages:      3,
+			received:      3,
+			threads:       3,
+			senders:       []output.StatCount{{Key: "a@a.example", Count: 3}},
+			domains:       []output.StatCount{{Key: "a.example", Count: 3}},
+			labels:        []output.StatCount{{Key: "INBOX", Count: 3}},
+			weekdays:      []int{1, 0, 0, 0, 0, 0, 2},
+			hours:         map[int]int{8: 2, 23: 1},
+			unreadAge:     []int{0, 0, 0, 0},
+			threadLengths: []int{3, 0, 0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := ComputeStats(tt.msgs, tt.top, now)
+
+			if stats.Messages != tt.messages || stats.Received != tt.received || stats.Sent != tt.sent || stats.Threads != tt.threads {
+				t.Errorf("messages/received/sent/threads = %d/%d/%d/%d, want %d/%d/%d/%d",
+					stats.Messages, stats.Received, stats.Sent, stats.Threads,
+					tt.messages, tt.received, tt.sent, tt.threads)
+			}
+			if !reflect.DeepEqual(stats.Senders, tt.senders) {
+				t.Errorf("senders = %v, want %v", stats.Senders, tt.senders)
+			}
+			if !reflect.DeepEqual(stats.Domains, tt.domains) {
+				t.Errorf("domains = %v, want %v", stats.Domains, tt.domains)
+			}
+			if !reflect.DeepEqual(stats.Labels, tt.labels) {
+				t.Errorf("labels = %v, want %v", stats.Labels, tt.labels)
+			}
+			if tt.weekdays != nil && !reflect.DeepEqual(counts(stats.Weekdays), tt.weekdays) {
+				t.Errorf("weekdays = %v, want %v", counts(stats.Weekdays), tt.weekdays)
+			}
+			if tt.hours != nil {
+				for h, n := range counts(stats.Hours) {
+					if n != tt.hours[h] {
+						t.Errorf("hour %02d = %d, want %d", h, n, tt.hours[h])
+					}
+				}
+			}
+			if !reflect.DeepEqual(counts(stats.UnreadAge), tt.unreadAge) {
+				t.Errorf("unread by age = %v, want %v", counts(stats.UnreadAge), tt.unreadAge)
+			}
+			if !reflect.DeepEqual(counts(stats.ThreadLengths), tt.threadLengths) {
+				t.Errorf("thread lengths = %v, want %v", counts(stats.ThreadLengths), tt.threadLengths)
+			}
+
+			latency := stats.ReplyLatency
+			latency.Buckets = nil
+			if !reflect.DeepEqual(latency, tt.latency) {
+				t.Errorf("reply latency = %+v, want %+v", latency, tt.latency)
+			}
+			if tt.replyBuckets != nil && !reflect.DeepEqual(counts(stats.ReplyLatency.Buckets), tt.replyBuckets) {
+				t.Errorf("reply latency buckets = %v, want %v", counts(stats.ReplyLatency.Buckets), tt.replyBuckets)
+			}
+		})
+	}
+}
+
+func TestComputeStatsSections(t *testing.T) {
+	stats := ComputeStats(nil, 0, time.Now())
+
+	for _, section := range []struct {
+		name  string
+		count []output.StatCount
+		want  int
+	}{
+		{"weekdays", stats.Weekdays, 7},
+		{"hours", stats.Hours, 24},
+		{"unread age", stats.UnreadAge, 4},
+		{"thread lengths", stats.ThreadLengths, 5},
+		{"reply latency", stats.ReplyLatency.Buckets, 5},
+	} {
+		if len(section.count) != section.want {
+			t.Errorf("%s has %d buckets, want %d", section.name, len(section.count), section.want)
+		}
+	}
+	if stats.Hours[0].Key != "00:00" || stats.Hours[23].Key != "23:00" {
+		t.Errorf("hour keys = %s..%s, want 00:00..23:00", stats.Hours[0].Key, stats.Hours[23].Key)
+	}
+	if stats.Weekdays[0].Key != "Mon" || stats.Weekdays[6].Key != "Sun" {
+		t.Errorf("weekday keys = %s..%s, want Mon..Sun", stats.Weekdays[0].Key, stats.Weekdays[6].Key)
+	}
+}
+
+// counts returns the counts of a statistics section in order
+func counts(section []output.StatCount) []int {
+	n := make([]int, len(section))
+	for i, c := range section {
+		n[i] = c.Count
+	}
+	return n
+}
@@ -0,0 +1,292 @@
+package gmail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/alexandraswan/gcli/internal/output"
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// SyncOptions controls how a mailbox is synced into the cache
+type SyncOptions struct {
+	// Limit caps the number of messages fetched by a full sync (0 for all)
+	Limit int64
+	// Bodies downloads full message bodies, not just headers
+	Bodies bool
+	// Full discards the cached history position and resyncs everything
+	Full bool
+	// Incremental only applies changes from the History API, returning
+	// ErrHistoryExpired instead of falling back to a full sync
+	Incremental bool
+}
+
+// SyncResult summarizes a sync
+type SyncResult struct {
+	Account   string `json:"account"`
+	Full      bool   `json:"full"`
+	Added     int    `json:"added"`
+	Updated   int    `json:"updated"`
+	Deleted   int    `json:"deleted"`
+	HistoryID uint64 `json:"history_id"`
+}
+
+// Sync brings the cache up to date with the mailbox. The first sync, or one
+// whose history position has expired, lists every message; later syncs only
+// apply changes from the History API.
+func (c *Client) Sync(ctx context.Context, cache *Cache, opts SyncOptions) (SyncResult, error) {
+	state, err := cache.SyncState(c.accountName)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	if state != nil && !opts.Full {
+		result, err := c.syncHistory(ctx, cache, state.HistoryID, opts)
+		if !errors.Is(err, ErrHistoryExpired) || opts.Incremental {
+			return result, err
+		}
+	}
+
+	return c.syncFull(ctx, cache, opts)
+}
+
+// syncFull lists all messages, fetching those not yet cached and removing
+// cached messages that no longer exist
+func (c *Client) syncFull(ctx context.Context, cache *Cache, opts SyncOptions) (SyncResult, error) {
+	result := SyncResult{Account: c.accountName, Full: true}
+
+	// Record the history position before listing so changes made during
+	// the sync are picked up by the next incremental sync
+	profile, err := c.service.Users.GetProfile("me").Context(ctx).Do()
+	if err != nil {
+		return result, fmt.Errorf("failed to get profile: %w", err)
+	}
+	result.HistoryID = profile.HistoryId
+
+	refs, err := c.ListMessageIDs(ctx, "", opts.Limit)
+	if err != nil {
+		return result, err
+	}
+
+	cached, err := cache.messageIDs(c.accountName)
+	if err != nil {
+		return result, err
+	}
+
+	for _, ref := range refs {
+		wasCached := cached[ref.ID]
+		delete(cached, ref.ID)
+
+		// Cached messages are refetched to pick up label changes, but a
+		// body that is already cached is not downloaded again
+		bodies := opts.Bodies
+		if wasCached {
+			_, full, err := cache.hasMessage(c.accountName, ref.ID)
+			if err != nil {
+				return result, err
+			}
+			bodies = bodies && !full
+		}
+
+		if err := c.fetchIntoCache(ctx, cache, ref.ID, bodies); err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return result, err
+		}
+		if wasCached {
+			result.Updated++
+		} else {
+			result.Added++
+		}
+	}
+
+	// A limited sync only sees the newest messages, so older cached
+	// messages cannot be assumed deleted
+	if opts.Limit == 0 {
+		for id := range cached {
+			if err := cache.deleteMessage(c.accountName, id); err != nil {
+				return result, err
+			}
+			result.Deleted++
+		}
+	}
+
+	return result, cache.setSyncState(c.accountName, result.HistoryID)
+}
+
+// syncHistory applies changes recorded since the given history ID
+func (c *Client) syncHistory(ctx context.Context, cache *Cache, startID uint64, opts SyncOptions) (SyncResult, error) {
+	result := SyncResult{Account: c.accountName, HistoryID: startID}
+
+	added := make(map[string]bool)
+	changed := make(map[string]bool)
+	deleted := make(map[string]bool)
+
+	pageToken := ""
+	for {
+		req := c.service.Users.History.List("me").StartHistoryId(startID).MaxResults(500)
+		if pageToken != "" {
+			req = req.PageToken(pageToken)
+		}
+
+		resp, err := req.Context(ctx).Do()
+		if err != nil {
+			if isNotFound(err) {
+				return result, ErrHistoryExpired
+			}
+			return result, fmt.Errorf("failed to list history: %w", err)
+		}
+
+		for _, h := range resp.History {
+			for _, m := range h.MessagesAdded {
+				added[m.Message.Id] = true
+				delete(deleted, m.Message.Id)
+			}
+			for _, m := range h.MessagesDeleted {
+				deleted[m.Message.Id] = true
+				delete(added, m.Message.Id)
+				delete(changed, m.Message.Id)
+			}
+			for _, m := range h.LabelsAdded {
+				changed[m.Message.Id] = true
+			}
+			for _, m := range h.LabelsRemoved {
+				changed[m.Message.Id] = true
+			}
+		}
+
+		if resp.HistoryId > result.HistoryID {
+			result.HistoryID = resp.HistoryId
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+
+	for id := range deleted {
+		if err := cache.deleteMessage(c.accountName, id); err != nil {
+			return result, err
+		}
+		result.Deleted++
+	}
+
+	for id := range added {
+		if err := c.fetchIntoCache(ctx, cache, id, opts.Bodies); err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return result, err
+		}
+		result.Added++
+	}
+
+	for id := range changed {
+		if added[id] {
+			continue
+		}
+		cached, _, err := cache.hasMessage(c.accountName, id)
+		if err != nil {
+			return result, err
+		}
+		if !cached {
+			// Label change on a message outside the synced range
+			continue
+		}
+
+		msg, err := c.service.Users.Messages.Get("me", id).Format("minimal").Context(ctx).Do()
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return result, fmt.Errorf("failed to get message: %w", err)
+		}
+		if err := cache.setLabels(c.accountName, id, msg.LabelIds); err != nil {
+			return result, err
+		}
+		result.Updated++
+	}
+
+	return result, cache.setSyncState(c.accountName, result.HistoryID)
+}
+
+// fetchIntoCache downloads a message and stores it in the cache
+func (c *Client) fetchIntoCache(ctx context.Context, cache *Cache, id string, full bool) error {
+	var msg *gmail.Message
+	var err error
+	if full {
+		msg, err = c.getFullMessage(ctx, id)
+	} else {
+		msg, err = c.getMessageMetadata(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+	return cache.putMessage(c.accountName, msg, full)
+}
+
+// ListMessagesCached lists messages matching the query, serving summaries
+// from the cache and only fetching messages the cache does not have
+func (c *Client) ListMessagesCached(ctx context.Context, cache *Cache, query string, maxResults int64) ([]output.EmailSummary, error) {
+	if query == "" {
+		return cache.ListMessages(c.accountName, maxResults)
+	}
+
+	refs, err := c.ListMessageIDs(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []output.EmailSummary
+	for _, ref := range refs {
+		msg, _, err := cache.loadMessage(c.accountName, ref.ID)
+		if errors.Is(err, ErrNotCached) {
+			msg, err = c.getMessageMetadata(ctx, ref.ID)
+			if err == nil {
+				err = cache.putMessage(c.accountName, msg, false)
+			}
+		}
+		if err != nil {
+			// Log and continue
+			continue
+		}
+
+		summary := summaryFromMessage(msg)
+		summary.Account = c.accountName
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+// GetMessageCached returns message details from the cache, downloading and
+// caching the message body if it has not been fetched yet
+func (c *Client) GetMessageCached(ctx context.Context, cache *Cache, id string, format BodyFormat) (output.EmailDetail, error) {
+	detail, err := cache.GetMessage(c.accountName, id, format)
+	if err == nil {
+		return detail, nil
+	}
+	if !errors.Is(err, ErrNotCached) {
+		return output.EmailDetail{}, err
+	}
+
+	msg, err := c.getFullMessage(ctx, id)
+	if err != nil {
+		return output.EmailDetail{}, err
+	}
+	if err := cache.putMessage(c.accountName, msg, true); err != nil {
+		return output.EmailDetail{}, err
+	}
+
+	return detailFromMessage(msg, c.accountName, format), nil
+}
+
+// isNotFound reports whether an API error is a 404
+func isNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
@@ -0,0 +1,236 @@
+package gmail
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/output"
+	"google.golang.org/api/gmail/v1"
+)
+
+// Unsubscribe methods, in order of preference
+const (
+	// UnsubscribeOneClick is an RFC 8058 one-click POST to an HTTPS URL
+	UnsubscribeOneClick = "one-click"
+	// UnsubscribeMailto is an email sent to the list's unsubscribe address
+	UnsubscribeMailto = "mailto"
+	// UnsubscribeWeb is a web page the user has to visit
+	UnsubscribeWeb = "web"
+)
+
+// unsubscribeTimeout bounds one-click unsubscribe requests
+const unsubscribeTimeout = 30 * time.Second
+
+// ListSubscriptions finds the mailing lists among messages matching a query
+// and groups them by sender, most frequent first. Messages without a
+// List-Unsubscribe header or a sender address are ignored. Messages that
+// cannot be fetched are left out and counted in failed.
+func (c *Client) ListSubscriptions(ctx context.Context, query string, limit int64) ([]output.Subscription, int, error) {
+	refs, err := c.ListMessageIDs(ctx, query, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	failed := 0
+	bySender := make(map[string]*output.Subscription)
+	for _, ref := range refs {
+		msg, err := c.service.Users.Messages.Get("me", ref.ID).
+			Format("metadata").
+			MetadataHeaders("From", "List-Unsubscribe", "List-Unsubscribe-Post").
+			Context(ctx).
+			Do()
+		if err != nil {
+			// Messages deleted since they were listed are not a failure
+			if !isNotFound(err) {
+				failed++
+			}
+			continue
+		}
+
+		headers := headerMap(msg.Payload.Headers)
+		links := headers["List-Unsubscribe"]
+		if links == "" {
+			continue
+		}
+
+		// Without an address the sender cannot be searched for or filtered
+		sender := parseAddress(headers["From"])
+		if sender.Email == "" {
+			continue
+		}
+		key := strings.ToLower(sender.Email)
+		date := time.UnixMilli(msg.InternalDate)
+
+		sub, ok := bySender[key]
+		if !ok {
+			sub = &output.Subscription{Account: c.accountName, Sender: sender}
+			bySender[key] = sub
+		}
+		sub.Messages++
+		sub.MessageIDs = append(sub.MessageIDs, msg.Id)
+
+		// The newest message has the most current unsubscribe links
+		if date.After(sub.Latest) {
+			sub.Latest = date
+			if sender.Name != "" {
+				sub.Sender.Name = sender.Name
+			}
+			setUnsubscribeMethod(sub, links, headers["List-Unsubscribe-Post"])
+		}
+	}
+
+	subs := make([]output.Subscription, 0, len(bySender))
+	for _, sub := range bySender {
+		subs = append(subs, *sub)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		if subs[i].Messages != subs[j].Messages {
+			return subs[i].Messages > subs[j].Messages
+		}
+		return subs[i].Sender.Email < subs[j].Sender.Email
+	})
+	return subs, failed, nil
+}
+
+// setUnsubscribeMethod picks the best unsubscribe method from the
+// List-Unsubscribe and List-Unsubscribe-Post headers (RFC 2369, RFC 8058)
+func setUnsubscribeMethod(sub *output.Subscription, links, post string) {
+	sub.URL, sub.Mailto = "", ""
+	for _, link := range parseListUnsubscribe(links) {
+		switch {
+		case strings.HasPrefix(strings.ToLower(link), "mailto:"):
+			if sub.Mailto == "" {
+				sub.Mailto = link
+			}
+		case strings.HasPrefix(strings.ToLower(link), "https://"), strings.HasPrefix(strings.ToLower(link), "http://"):
+			if sub.URL == "" {
+				sub.URL = link
+			}
+		}
+	}
+
+	oneClick := strings.EqualFold(strings.TrimSpace(post), "List-Unsubscribe=One-Click")
+	switch {
+	case oneClick && strings.HasPrefix(strings.ToLower(sub.URL), "https://"):
+		sub.Method = UnsubscribeOneClick
+	case sub.Mailto != "":
+		sub.Method = UnsubscribeMailto
+	default:
+		sub.Method = UnsubscribeWeb
+	}
+}
+
+// parseListUnsubscribe extracts the URIs from a List-Unsubscribe header,
+// which lists them in angle brackets separated by commas
+func parseListUnsubscribe(value string) []string {
+	var links []string
+	for {
+		start := strings.IndexByte(value, '<')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(value[start:], '>')
+		if end < 0 {
+			break
+		}
+		// Long URIs are sometimes folded across lines
+		link := strings.Join(strings.Fields(value[start+1:start+end]), "")
+		if link != "" {
+			links = append(links, link)
+		}
+		value = value[start+end+1:]
+	}
+	return links
+}
+
+// Unsubscribe unsubscribes from a mailing list using its one-click URL or
+// by sending an email to its mailto address. Lists that only offer a web
+// page cannot be unsubscribed from automatically.
+func (c *Client) Unsubscribe(ctx context.Context, sub output.Subscription) error {
+	switch sub.Method {
+	case UnsubscribeOneClick:
+		return unsubscribeOneClick(ctx, sub.URL)
+	case UnsubscribeMailto:
+		email, err := mailtoEmail(sub.Mailto)
+		if err != nil {
+			return err
+		}
+		_, err = c.SendEmail(ctx, email)
+		return err
+	default:
+		return fmt.Errorf("no automatic unsubscribe method; visit %s", sub.URL)
+	}
+}
+
+// unsubscribeOneClick sends an RFC 8058 one-click unsubscribe request
+func unsubscribeOneClick(ctx context.Context, link string) error {
+	ctx, cancel := context.WithTimeout(ctx, unsubscribeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, link, strings.NewReader("List-Unsubscribe=One-Click"))
+	if err != nil {
+		return fmt.Errorf("invalid unsubscribe URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to unsubscribe: %s", resp.Status)
+	}
+	return nil
+}
+
+// mailtoEmail builds the email for a mailto: unsubscribe link, using the
+// subject and body it specifies
+func mailtoEmail(link string) (DraftEmail, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return DraftEmail{}, fmt.Errorf("invalid unsubscribe address: %w", err)
+	}
+	to, err := url.PathUnescape(u.Opaque)
+	if err != nil || to == "" {
+		return DraftEmail{}, fmt.Errorf("invalid unsubscribe address: %s", link)
+	}
+
+	params := u.Query()
+	email := DraftEmail{
+		To:      strings.Split(to, ","),
+		Subject: params.Get("subject"),
+		Body:    params.Get("body"),
+	}
+	if email.Subject == "" {
+		email.Subject = "unsubscribe"
+	}
+	if email.Body == "" {
+		email.Body = "unsubscribe"
+	}
+	return email, nil
+}
+
+// ModifyMessages adds and removes labels on messages in batches
+func (c *Client) ModifyMessages(ctx context.Context, ids, addLabelIDs, removeLabelIDs []string) error {
+	// batchModify accepts at most 1000 IDs per request
+	const batchSize = 1000
+	for start := 0; start < len(ids); start += batchSize {
+		end := min(start+batchSize, len(ids))
+		err := c.service.Users.Messages.BatchModify("me", &gmail.BatchModifyMessagesRequest{
+			Ids:            ids[start:end],
+			AddLabelIds:    addLabelIDs,
+			RemoveLabelIds: removeLabelIDs,
+		}).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("failed to modify messages: %w", err)
+		}
+	}
+	return nil
+}
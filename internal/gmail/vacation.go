@@ -0,0 +1,60 @@
+package gmail
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/output"
+	"google.golang.org/api/gmail/v1"
+)
+
+// GetVacation gets the vacation auto-reply settings
+func (c *Client) GetVacation(ctx context.Context) (output.VacationSettings, error) {
+	v, err := c.service.Users.Settings.GetVacation("me").Context(ctx).Do()
+	if err != nil {
+		return output.VacationSettings{}, fmt.Errorf("failed to get vacation settings: %w", err)
+	}
+
+	settings := output.VacationSettings{
+		Account:            c.accountName,
+		Enabled:            v.EnableAutoReply,
+		Subject:            v.ResponseSubject,
+		Body:               v.ResponseBodyPlainText,
+		HTMLBody:           v.ResponseBodyHtml,
+		RestrictToContacts: v.RestrictToContacts,
+		RestrictToDomain:   v.RestrictToDomain,
+	}
+	if v.StartTime > 0 {
+		settings.Start = time.UnixMilli(v.StartTime)
+	}
+	if v.EndTime > 0 {
+		settings.End = time.UnixMilli(v.EndTime)
+	}
+	return settings, nil
+}
+
+// SetVacation updates the vacation auto-reply settings
+func (c *Client) SetVacation(ctx context.Context, settings output.VacationSettings) error {
+	v := &gmail.VacationSettings{
+		EnableAutoReply:       settings.Enabled,
+		ResponseSubject:       settings.Subject,
+		ResponseBodyPlainText: settings.Body,
+		ResponseBodyHtml:      settings.HTMLBody,
+		RestrictToContacts:    settings.RestrictToContacts,
+		RestrictToDomain:      settings.RestrictToDomain,
+		// Booleans must be sent explicitly so they can be turned off
+		ForceSendFields: []string{"EnableAutoReply", "RestrictToContacts", "RestrictToDomain"},
+	}
+	if !settings.Start.IsZero() {
+		v.StartTime = settings.Start.UnixMilli()
+	}
+	if !settings.End.IsZero() {
+		v.EndTime = settings.End.UnixMilli()
+	}
+
+	if _, err := c.service.Users.Settings.UpdateVacation("me", v).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to update vacation settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package gmail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// ErrHistoryExpired is returned when a history ID is too old to list
+// changes from and the caller must start again from the current position
+var ErrHistoryExpired = errors.New("history ID expired")
+
+// GetProfile returns the account's email address and current history ID
+func (c *Client) GetProfile(ctx context.Context) (string, uint64, error) {
+	profile, err := c.service.Users.GetProfile("me").Context(ctx).Do()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get profile: %w", err)
+	}
+	return profile.EmailAddress, profile.HistoryId, nil
+}
+
+// NewMessagesSince returns the IDs of messages received after the given
+// history ID, oldest first, and the history ID to continue from. Drafts and
+// sent mail are not included.
+func (c *Client) NewMessagesSince(ctx context.Context, startID uint64) ([]string, uint64, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	latest := startID
+
+	pageToken := ""
+	for {
+		req := c.service.Users.History.List("me").
+			StartHistoryId(startID).
+			HistoryTypes("messageAdded").
+			MaxResults(500)
+		if pageToken != "" {
+			req = req.PageToken(pageToken)
+		}
+
+		resp, err := req.Context(ctx).Do()
+		if err != nil {
+			if isNotFound(err) {
+				return nil, startID, ErrHistoryExpired
+			}
+			return nil, startID, fmt.Errorf("failed to list history: %w", err)
+		}
+
+		for _, h := range resp.History {
+			for _, m := range h.MessagesAdded {
+				if seen[m.Message.Id] || !isReceived(m.Message) {
+					continue
+				}
+				seen[m.Message.Id] = true
+				ids = append(ids, m.Message.Id)
+			}
+		}
+
+		if resp.HistoryId > latest {
+			latest = resp.HistoryId
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+
+	return ids, latest, nil
+}
+
+// MatchesQuery reports whether a message matches a Gmail search query. The
+// search is narrowed to the message by its Message-ID header, or its
+// receipt time when it has none, so the answer does not depend on the
+// message's Date header or on how many other messages match.
+func (c *Client) MatchesQuery(ctx context.Context, id, query string) (bool, error) {
+	msg, err := c.service.Users.Messages.Get("me", id).
+		Format("metadata").
+		MetadataHeaders("Message-ID").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return false, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	var narrow string
+	if messageID := strings.Trim(headerMap(msg.Payload.Headers)["Message-Id"], " <>"); messageID != "" {
+		narrow = "rfc822msgid:" + messageID
+	} else {
+		received := msg.InternalDate / 1000
+		narrow = fmt.Sprintf("after:%d before:%d", received-1, received+1)
+	}
+
+	// Copies of a message, such as one sent to yourself, share its
+	// Message-ID, so the result is checked for this message's ID
+	refs, err := c.ListMessageIDs(ctx, fmt.Sprintf("(%s) %s", query, narrow), 100)
+	if err != nil {
+		return false, err
+	}
+	for _, ref := range refs {
+		if ref.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isReceived reports whether a message was received rather than written
+// by the account owner
+func isReceived(msg *gmail.Message) bool {
+	for _, label := range msg.LabelIds {
+		if label == "DRAFT" || label == "SENT" {
+			return false
+		}
+	}
+	return true
+}
+
+// Watch asks Gmail to publish mailbox changes to a Cloud Pub/Sub topic.
+// It returns the history ID the watch starts from and when it expires.
+func (c *Client) Watch(ctx context.Context, topic string) (uint64, time.Time, error) {
+	resp, err := c.service.Users.Watch("me", &gmail.WatchRequest{
+		TopicName: topic,
+	}).Context(ctx).Do()
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to start watch: %w", err)
+	}
+	return resp.HistoryId, time.UnixMilli(resp.Expiration), nil
+}
+
+// StopWatch stops push notifications for the account
+func (c *Client) StopWatch(ctx context.Context) error {
+	if err := c.service.Users.Stop("me").Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to stop watch: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package mailbox
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// mboxDateLayout is the asctime-style date used on mbox "From " lines
+const mboxDateLayout = "Mon Jan _2 15:04:05 2006"
+
+// fromLine matches lines that must be escaped in mboxrd format
+var fromLine = regexp.MustCompile(`^>*From `)
+
+// Header holds the headers of a message needed for naming and dedup
+type Header struct {
+	MessageID string
+	From      string
+	Subject   string
+	Date      time.Time
+	// Unread and Starred come from mbox Status/X-Status headers or the
+	// X-Gmail-Labels header written by Google Takeout
+	Unread  bool
+	Starred bool
+}
+
+// ParseHeader reads the header block of a raw RFC 5322 message
+func ParseHeader(raw []byte) (Header, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return Header{}, fmt.Errorf("failed to parse message headers: %w", err)
+	}
+
+	dec := new(mime.WordDecoder)
+	subject, err := dec.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	h := Header{
+		MessageID: strings.Trim(strings.TrimSpace(msg.Header.Get("Message-Id")), "<>"),
+		Subject:   subject,
+	}
+
+	if from, err := mail.ParseAddress(msg.Header.Get("From")); err == nil {
+		h.From = from.Address
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		h.Date = date
+	}
+
+	if status := msg.Header.Get("Status"); status != "" {
+		h.Unread = !strings.Contains(status, "R")
+	}
+	if strings.Contains(msg.Header.Get("X-Status"), "F") {
+		h.Starred = true
+	}
+	for _, label := range strings.Split(msg.Header.Get("X-Gmail-Labels"), ",") {
+		switch strings.TrimSpace(label) {
+		case "Unread":
+			h.Unread = true
+		case "Starred":
+			h.Starred = true
+		}
+	}
+
+	return h, nil
+}
+
+// EMLFileName builds a file name for a message from its date and subject,
+// with the message ID appended to keep names unique
+func EMLFileName(date time.Time, subject, id string) string {
+	slug := slugify(subject, 60)
+	if slug == "" {
+		slug = "no-subject"
+	}
+	return fmt.Sprintf("%s_%s_%s.eml", date.Format("2006-01-02_150405"), slug, id)
+}
+
+// slugify converts a string to a lowercase, filesystem-safe slug
+func slugify(s string, maxLen int) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	slug := []rune(strings.TrimRight(b.String(), "-"))
+	if len(slug) > maxLen {
+		slug = slug[:maxLen]
+	}
+	return strings.TrimRight(string(slug), "-")
+}
+
+// MboxWriter writes messages in mboxrd format
+type MboxWriter struct {
+	w io.Writer
+}
+
+// NewMboxWriter returns a writer that appends messages to w
+func NewMboxWriter(w io.Writer) *MboxWriter {
+	return &MboxWriter{w: w}
+}
+
+// WriteMessage writes a single message. Line endings are converted to LF
+// and lines beginning with "From " (after any ">" characters) are escaped.
+func (m *MboxWriter) WriteMessage(sender string, date time.Time, raw []byte) (int64, error) {
+	if sender == "" {
+		sender = "MAILER-DAEMON"
+	}
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From %s %s\n", sender, date.UTC().Format(mboxDateLayout))
+
+	body := strings.ReplaceAll(string(raw), "\r\n", "\n")
+	body = strings.TrimRight(body, "\n")
+	for _, line := range strings.Split(body, "\n") {
+		if fromLine.MatchString(line) {
+			buf.WriteString(">")
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+
+	n, err := m.w.Write(buf.Bytes())
+	if err != nil {
+		return int64(n), fmt.Errorf("failed to write mbox message: %w", err)
+	}
+	return int64(n), nil
+}
+
+// MboxReader reads messages from an mbox file
+type MboxReader struct {
+	scanner *bufio.Scanner
+	pending string
+	started bool
+}
+
+// NewMboxReader returns a reader over the messages in r
+func NewMboxReader(r io.Reader) *MboxReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	return &MboxReader{scanner: scanner}
+}
+
+// MboxMessage is a message read from an mbox file
+type MboxMessage struct {
+	// Envelope is the mbox "From " line without the leading "From "
+	Envelope string
+	// Raw is the message with CRLF line endings and mboxrd escaping removed
+	Raw []byte
+}
+
+// Next returns the next message, or io.EOF when there are no more
+func (m *MboxReader) Next() (*MboxMessage, error) {
+	if !m.started {
+		for m.scanner.Scan() {
+			line := m.scanner.Text()
+			if strings.HasPrefix(line, "From ") {
+				m.pending = line
+				m.started = true
+				break
+			}
+		}
+		if err := m.scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read mbox: %w", err)
+		}
+		if !m.started {
+			return nil, io.EOF
+		}
+	}
+
+	if m.pending == "" {
+		return nil, io.EOF
+	}
+
+	msg := &MboxMessage{Envelope: strings.TrimPrefix(m.pending, "From ")}
+	m.pending = ""
+
+	var lines []string
+	for m.scanner.Scan() {
+		line := m.scanner.Text()
+		if strings.HasPrefix(line, "From ") {
+			m.pending = line
+			break
+		}
+		if fromLine.MatchString(line) && strings.HasPrefix(line, ">") {
+			line = line[1:]
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\r"))
+	}
+	if err := m.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mbox: %w", err)
+	}
+
+	// The blank line separating messages is not part of the message
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	msg.Raw = []byte(strings.Join(lines, "\r\n") + "\r\n")
+	return msg, nil
+}
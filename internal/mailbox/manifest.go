@@ -0,0 +1,79 @@
+package mailbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Manifest records what has been exported so an export can be resumed and
+// audited
+type Manifest struct {
+	Format    string          `json:"format"`
+	Query     string          `json:"query,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Complete  bool            `json:"complete"`
+	Bytes     int64           `json:"bytes"`
+	Messages  []ManifestEntry `json:"messages"`
+}
+
+// ManifestEntry describes a single exported message
+type ManifestEntry struct {
+	Account   string    `json:"account"`
+	ID        string    `json:"id"`
+	ThreadID  string    `json:"thread_id"`
+	MessageID string    `json:"message_id,omitempty"`
+	From      string    `json:"from,omitempty"`
+	Subject   string    `json:"subject"`
+	Date      time.Time `json:"date"`
+	File      string    `json:"file"`
+	Size      int       `json:"size"`
+	SHA256    string    `json:"sha256"`
+}
+
+// LoadManifest loads a manifest, returning nil if it does not exist
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// Save writes the manifest atomically
+func (m *Manifest) Save(path string) error {
+	m.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Exported returns the set of account/message ID pairs already exported
+func (m *Manifest) Exported() map[string]bool {
+	done := make(map[string]bool, len(m.Messages))
+	for _, e := range m.Messages {
+		done[e.Account+"/"+e.ID] = true
+	}
+	return done
+}
@@ -0,0 +1,265 @@
+package markdown
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// Options controls how markdown is rendered to HTML
+type Options struct {
+	// Theme is the name of a built-in inline CSS theme (empty for none)
+	Theme string
+	// BaseDir is the directory relative image paths are resolved against
+	BaseDir string
+}
+
+// Image represents a local image embedded in the rendered HTML
+type Image struct {
+	ContentID   string
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Rendered holds the result of rendering a markdown document
+type Rendered struct {
+	HTML   string
+	Images []Image
+}
+
+// Render converts markdown source into an HTML document suitable for email.
+// Local images are replaced with cid: references and returned for embedding.
+func Render(source string, opts Options) (*Rendered, error) {
+	var theme Theme
+	if opts.Theme != "" {
+		t, ok := Themes[opts.Theme]
+		if !ok {
+			return nil, fmt.Errorf("unknown theme '%s' (available: %s)", opts.Theme, strings.Join(ThemeNames(), ", "))
+		}
+		theme = t
+	}
+
+	// Raw HTML is passed through since the author of the message is the
+	// one writing the markdown
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+	src := []byte(source)
+	doc := md.Parser().Parse(text.NewReader(src))
+
+	result := &Rendered{}
+	embedded := make(map[string]string)
+
+	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		if img, ok := n.(*ast.Image); ok {
+			dest := string(img.Destination)
+			if isLocalPath(dest) {
+				cid, ok := embedded[dest]
+				if !ok {
+					image, err := loadImage(dest, opts.BaseDir)
+					if err != nil {
+						return ast.WalkStop, err
+					}
+					result.Images = append(result.Images, image)
+					cid = image.ContentID
+					embedded[dest] = cid
+				}
+				img.Destination = []byte("cid:" + cid)
+			}
+		}
+
+		if style := theme.styleFor(n); style != "" {
+			n.SetAttributeString("style", []byte(style))
+		}
+
+		return ast.WalkContinue, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, src, doc); err != nil {
+		return nil, fmt.Errorf("failed to render markdown: %w", err)
+	}
+
+	body := buf.String()
+	if theme.Pre != "" {
+		body = strings.ReplaceAll(body, "<pre><code", fmt.Sprintf(`<pre style="%s"><code`, theme.Pre))
+	}
+
+	var page strings.Builder
+	page.WriteString("<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"></head>\n")
+	if theme.Body != "" {
+		page.WriteString(fmt.Sprintf("<body style=\"%s\">\n", theme.Body))
+	} else {
+		page.WriteString("<body>\n")
+	}
+	page.WriteString(body)
+	page.WriteString("</body>\n</html>\n")
+
+	result.HTML = page.String()
+	return result, nil
+}
+
+// isLocalPath reports whether an image destination refers to a local file
+func isLocalPath(dest string) bool {
+	if dest == "" {
+		return false
+	}
+	lower := strings.ToLower(dest)
+	for _, prefix := range []string{"http://", "https://", "cid:", "data:", "mailto:", "//"} {
+		if strings.HasPrefix(lower, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// loadImage reads a local image and assigns it a Content-ID
+func loadImage(dest, baseDir string) (Image, error) {
+	path := strings.TrimPrefix(dest, "file://")
+	if !filepath.IsAbs(path) && baseDir != "" {
+		path = filepath.Join(baseDir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to read image '%s': %w", dest, err)
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return Image{}, fmt.Errorf("'%s' is not an image (%s)", dest, contentType)
+	}
+
+	id, err := randomID()
+	if err != nil {
+		return Image{}, err
+	}
+
+	return Image{
+		ContentID:   id + "@gcli",
+		Filename:    filepath.Base(path),
+		ContentType: contentType,
+		Data:        data,
+	}, nil
+}
+
+// randomID returns a random hex identifier
+func randomID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate content ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Theme holds inline CSS applied to rendered elements. Email clients
+// commonly strip <style> blocks, so styles are set on each element.
+type Theme struct {
+	Body       string
+	Heading    string
+	Paragraph  string
+	Link       string
+	Blockquote string
+	Code       string
+	Pre        string
+	List       string
+	Table      string
+	Cell       string
+	Image      string
+	Rule       string
+}
+
+// styleFor returns the inline style for an AST node
+func (t Theme) styleFor(n ast.Node) string {
+	switch n.(type) {
+	case *ast.Heading:
+		return t.Heading
+	case *ast.Paragraph:
+		return t.Paragraph
+	case *ast.Link, *ast.AutoLink:
+		return t.Link
+	case *ast.Blockquote:
+		return t.Blockquote
+	case *ast.CodeSpan:
+		return t.Code
+	case *ast.List:
+		return t.List
+	case *extast.Table:
+		return t.Table
+	case *extast.TableCell:
+		return t.Cell
+	case *ast.Image:
+		return t.Image
+	case *ast.ThematicBreak:
+		return t.Rule
+	}
+	return ""
+}
+
+// Themes are the built-in inline CSS themes
+var Themes = map[string]Theme{
+	"default": {
+		Body:       "font-family: -apple-system, 'Segoe UI', Helvetica, Arial, sans-serif; font-size: 14px; line-height: 1.5; color: #222;",
+		Heading:    "margin: 16px 0 8px; line-height: 1.25;",
+		Paragraph:  "margin: 0 0 12px;",
+		Link:       "color: #1a73e8;",
+		Blockquote: "margin: 0 0 12px; padding: 0 12px; border-left: 3px solid #ddd; color: #555;",
+		Code:       "font-family: Menlo, Consolas, monospace; font-size: 13px; background: #f3f3f3; padding: 1px 4px; border-radius: 3px;",
+		Pre:        "font-family: Menlo, Consolas, monospace; font-size: 13px; background: #f6f8fa; padding: 12px; border-radius: 4px; overflow: auto;",
+		List:       "margin: 0 0 12px; padding-left: 24px;",
+		Table:      "border-collapse: collapse; margin: 0 0 12px;",
+		Cell:       "border: 1px solid #ddd; padding: 6px 10px;",
+		Image:      "max-width: 100%;",
+		Rule:       "border: none; border-top: 1px solid #ddd; margin: 16px 0;",
+	},
+	"github": {
+		Body:       "font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif; font-size: 16px; line-height: 1.5; color: #1f2328;",
+		Heading:    "margin: 24px 0 16px; font-weight: 600; line-height: 1.25; padding-bottom: 0.3em; border-bottom: 1px solid #d1d9e0;",
+		Paragraph:  "margin: 0 0 16px;",
+		Link:       "color: #0969da; text-decoration: none;",
+		Blockquote: "margin: 0 0 16px; padding: 0 1em; color: #59636e; border-left: 0.25em solid #d1d9e0;",
+		Code:       "font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 85%; background: rgba(129,139,152,0.12); padding: 0.2em 0.4em; border-radius: 6px;",
+		Pre:        "font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 85%; background: #f6f8fa; padding: 16px; border-radius: 6px; overflow: auto;",
+		List:       "margin: 0 0 16px; padding-left: 2em;",
+		Table:      "border-collapse: collapse; margin: 0 0 16px;",
+		Cell:       "border: 1px solid #d1d9e0; padding: 6px 13px;",
+		Image:      "max-width: 100%;",
+		Rule:       "height: 0.25em; padding: 0; margin: 24px 0; background: #d1d9e0; border: 0;",
+	},
+}
+
+// ThemeNames returns the names of the built-in themes in sorted order
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
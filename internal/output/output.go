@@ -1,9 +1,11 @@
 package output
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -33,18 +35,59 @@ type EmailSummary struct {
 	HasAttach bool     `json:"has_attachments"`
 }
 
+// Address represents an email address with an optional display name
+type Address struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email"`
+}
+
+// String formats the address as "Name <email>", or just the email when
+// there is no display name
+func (a Address) String() string {
+	switch {
+	case a.Name == "":
+		return a.Email
+	case a.Email == "":
+		return a.Name
+	default:
+		return fmt.Sprintf("%s <%s>", a.Name, a.Email)
+	}
+}
+
+// ListHeaders holds mailing list headers (RFC 2369 and RFC 2919)
+type ListHeaders struct {
+	ID              string `json:"id,omitempty"`
+	Unsubscribe     string `json:"unsubscribe,omitempty"`
+	UnsubscribePost string `json:"unsubscribe_post,omitempty"`
+	Post            string `json:"post,omitempty"`
+	Help            string `json:"help,omitempty"`
+	Archive         string `json:"archive,omitempty"`
+}
+
 // EmailDetail represents detailed email information
 type EmailDetail struct {
-	ID          string    `json:"id"`
-	Account     string    `json:"account,omitempty"`
-	ThreadID    string    `json:"thread_id"`
-	From        string    `json:"from"`
-	To          []string  `json:"to"`
-	CC          []string  `json:"cc,omitempty"`
-	Subject     string    `json:"subject"`
-	Date        time.Time `json:"date"`
-	Body        string    `json:"body"`
-	Attachments []string  `json:"attachments,omitempty"`
+	ID          string       `json:"id"`
+	Account     string       `json:"account,omitempty"`
+	ThreadID    string       `json:"thread_id"`
+	MessageID   string       `json:"message_id,omitempty"`
+	From        Address      `json:"from"`
+	To          []Address    `json:"to"`
+	CC          []Address    `json:"cc,omitempty"`
+	ReplyTo     []Address    `json:"reply_to,omitempty"`
+	Subject     string       `json:"subject"`
+	Date        time.Time    `json:"date"`
+	List        *ListHeaders `json:"list,omitempty"`
+	Body        string       `json:"body"`
+	Attachments []string     `json:"attachments,omitempty"`
+}
+
+// FormatAddresses joins addresses for display
+func FormatAddresses(addrs []Address) string {
+	parts := make([]string, len(addrs))
+	for i, a := range addrs {
+		parts[i] = a.String()
+	}
+	return strings.Join(parts, ", ")
 }
 
 // CalendarEventSummary represents a summary of a calendar event
@@ -93,6 +136,16 @@ type ScheduledEmail struct {
 	Error       string    `json:"error,omitempty"`
 }
 
+// ImportResult records the outcome of importing a single message
+type ImportResult struct {
+	Source    string `json:"source"`
+	MessageID string `json:"message_id,omitempty"`
+	Subject   string `json:"subject"`
+	Status    string `json:"status"`
+	ID        string `json:"id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
 // PrintEmailList prints a list of emails
 func PrintEmailList(emails []EmailSummary) {
 	if JSONOutput {
@@ -136,12 +189,18 @@ func PrintEmailDetail(email EmailDetail) {
 		fmt.Printf("Account: %s\n", email.Account)
 	}
 	fmt.Printf("From:    %s\n", email.From)
-	fmt.Printf("To:      %s\n", strings.Join(email.To, ", "))
+	fmt.Printf("To:      %s\n", FormatAddresses(email.To))
 	if len(email.CC) > 0 {
-		fmt.Printf("CC:      %s\n", strings.Join(email.CC, ", "))
+		fmt.Printf("CC:      %s\n", FormatAddresses(email.CC))
+	}
+	if len(email.ReplyTo) > 0 {
+		fmt.Printf("Reply-To: %s\n", FormatAddresses(email.ReplyTo))
 	}
 	fmt.Printf("Subject: %s\n", email.Subject)
 	fmt.Printf("Date:    %s\n", email.Date.Format("Mon, 02 Jan 2006 15:04:05 MST"))
+	if email.List != nil && email.List.ID != "" {
+		fmt.Printf("List:    %s\n", email.List.ID)
+	}
 	if len(email.Attachments) > 0 {
 		fmt.Printf("Attachments: %s\n", strings.Join(email.Attachments, ", "))
 	}
@@ -293,13 +352,14 @@ func PrintInfo(format string, args ...interface{}) {
 
 // truncate truncates a string to the specified length
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
 	if maxLen <= 3 {
-		return s[:maxLen]
+		return string(runes[:maxLen])
 	}
-	return s[:maxLen-3] + "..."
+	return string(runes[:maxLen-3]) + "..."
 }
 
 // AccountInfo represents account information for display
@@ -344,3 +404,1346 @@ func PrintAccountList(accounts []AccountInfo) {
 	}
 	w.Flush()
 }
+
+// FilterCriteria selects the messages a filter applies to
+type FilterCriteria struct {
+	From           string `json:"from,omitempty" yaml:"from,omitempty"`
+	To             string `json:"to,omitempty" yaml:"to,omitempty"`
+	Subject        string `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Query          string `json:"query,omitempty" yaml:"query,omitempty"`
+	NegatedQuery   string `json:"negated_query,omitempty" yaml:"negated_query,omitempty"`
+	HasAttachment  bool   `json:"has_attachment,omitempty" yaml:"has_attachment,omitempty"`
+	ExcludeChats   bool   `json:"exclude_chats,omitempty" yaml:"exclude_chats,omitempty"`
+	Size           int64  `json:"size,omitempty" yaml:"size,omitempty"`
+	SizeComparison string `json:"size_comparison,omitempty" yaml:"size_comparison,omitempty"`
+}
+
+// FilterAction is what a filter does to matching messages. Labels are
+// given by name so filter sets can be shared between accounts.
+type FilterAction struct {
+	AddLabels    []string `json:"add_labels,omitempty" yaml:"add_labels,omitempty"`
+	RemoveLabels []string `json:"remove_labels,omitempty" yaml:"remove_labels,omitempty"`
+	Forward      string   `json:"forward,omitempty" yaml:"forward,omitempty"`
+}
+
+// Filter represents a Gmail server-side filter
+type Filter struct {
+	ID       string         `json:"id,omitempty" yaml:"id,omitempty"`
+	Account  string         `json:"account,omitempty" yaml:"-"`
+	Criteria FilterCriteria `json:"criteria" yaml:"criteria"`
+	Action   FilterAction   `json:"action" yaml:"action"`
+}
+
+// String formats the criteria in Gmail search syntax
+func (c FilterCriteria) String() string {
+	var parts []string
+	if c.From != "" {
+		parts = append(parts, "from:"+quoteTerm(c.From))
+	}
+	if c.To != "" {
+		parts = append(parts, "to:"+quoteTerm(c.To))
+	}
+	if c.Subject != "" {
+		parts = append(parts, "subject:"+quoteTerm(c.Subject))
+	}
+	if c.Query != "" {
+		parts = append(parts, c.Query)
+	}
+	if c.NegatedQuery != "" {
+		parts = append(parts, "-("+c.NegatedQuery+")")
+	}
+	if c.HasAttachment {
+		parts = append(parts, "has:attachment")
+	}
+	if c.ExcludeChats {
+		parts = append(parts, "-in:chats")
+	}
+	if c.Size > 0 {
+		parts = append(parts, fmt.Sprintf("%s:%d", c.SizeComparison, c.Size))
+	}
+	return strings.Join(parts, " ")
+}
+
+// String formats the actions compactly, e.g. "+Label -INBOX →fwd@example.com"
+func (a FilterAction) String() string {
+	var parts []string
+	for _, l := range a.AddLabels {
+		parts = append(parts, "+"+l)
+	}
+	for _, l := range a.RemoveLabels {
+		parts = append(parts, "-"+l)
+	}
+	if a.Forward != "" {
+		parts = append(parts, "→"+a.Forward)
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteTerm quotes a search term containing spaces
+func quoteTerm(s string) string {
+	if strings.ContainsAny(s, " \t") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// PrintFilters prints a list of filters
+func PrintFilters(filters []Filter) {
+	if JSONOutput {
+		PrintJSON(filters)
+		return
+	}
+
+	if len(filters) == 0 {
+		fmt.Println("No filters found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCRITERIA\tACTIONS\tACCOUNT")
+	fmt.Fprintln(w, "──\t────────\t───────\t───────")
+
+	for _, f := range filters {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			f.ID, truncate(f.Criteria.String(), 50), truncate(f.Action.String(), 40), f.Account)
+	}
+	w.Flush()
+}
+
+// VacationSettings represents the vacation auto-reply settings
+type VacationSettings struct {
+	Account            string    `json:"account,omitempty"`
+	Enabled            bool      `json:"enabled"`
+	Subject            string    `json:"subject,omitempty"`
+	Body               string    `json:"body,omitempty"`
+	HTMLBody           string    `json:"html_body,omitempty"`
+	RestrictToContacts bool      `json:"restrict_to_contacts"`
+	RestrictToDomain   bool      `json:"restrict_to_domain"`
+	Start              time.Time `json:"start,omitempty"`
+	End                time.Time `json:"end,omitempty"`
+}
+
+// PrintVacationSettings prints vacation auto-reply settings
+func PrintVacationSettings(settings []VacationSettings) {
+	if JSONOutput {
+		PrintJSON(settings)
+		return
+	}
+
+	for i, v := range settings {
+		if i > 0 {
+			fmt.Println()
+		}
+		status := "Off"
+		if v.Enabled {
+			status = "On"
+		}
+		fmt.Printf("Account:  %s\n", v.Account)
+		fmt.Printf("Status:   %s\n", status)
+		if !v.Enabled {
+			continue
+		}
+		if !v.Start.IsZero() {
+			fmt.Printf("Start:    %s\n", v.Start.Local().Format("2006-01-02 15:04"))
+		}
+		if !v.End.IsZero() {
+			fmt.Printf("End:      %s\n", v.End.Local().Format("2006-01-02 15:04"))
+		}
+		switch {
+		case v.RestrictToContacts && v.RestrictToDomain:
+			fmt.Println("Replies:  Contacts in my domain only")
+		case v.RestrictToContacts:
+			fmt.Println("Replies:  Contacts only")
+		case v.RestrictToDomain:
+			fmt.Println("Replies:  My domain only")
+		default:
+			fmt.Println("Replies:  Everyone")
+		}
+		fmt.Printf("Subject:  %s\n", v.Subject)
+		body := v.Body
+		if body == "" && v.HTMLBody != "" {
+			body = "(HTML body)"
+		}
+		fmt.Printf("\n%s\n", body)
+	}
+}
+
+// SendAs represents an address the account can send mail as
+type SendAs struct {
+	Account            string `json:"account,omitempty"`
+	Email              string `json:"email"`
+	DisplayName        string `json:"display_name,omitempty"`
+	ReplyTo            string `json:"reply_to,omitempty"`
+	IsDefault          bool   `json:"is_default"`
+	IsPrimary          bool   `json:"is_primary"`
+	VerificationStatus string `json:"verification_status,omitempty"`
+	// Signature is the HTML signature configured for the address
+	Signature string `json:"signature,omitempty"`
+}
+
+// Verified reports whether mail can be sent as the address
+func (s SendAs) Verified() bool {
+	return s.IsPrimary || s.VerificationStatus == "accepted"
+}
+
+// PrintSendAs prints send-as aliases
+func PrintSendAs(aliases []SendAs) {
+	if JSONOutput {
+		PrintJSON(aliases)
+		return
+	}
+
+	if len(aliases) == 0 {
+		fmt.Println("No aliases found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "EMAIL\tNAME\tDEFAULT\tSTATUS\tSIGNATURE\tACCOUNT")
+	fmt.Fprintln(w, "─────\t────\t───────\t──────\t─────────\t───────")
+
+	for _, a := range aliases {
+		def := ""
+		if a.IsDefault {
+			def = "✓"
+		}
+		status := "Pending"
+		if a.Verified() {
+			status = "Verified"
+		}
+		sig := ""
+		if a.Signature != "" {
+			sig = "✓"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			a.Email, truncate(a.DisplayName, 30), def, status, sig, a.Account)
+	}
+	w.Flush()
+}
+
+// MailSettings holds an account's forwarding, IMAP, POP and language
+// settings. In a settings profile, sections left out are not changed.
+type MailSettings struct {
+	Account             string              `json:"account,omitempty" yaml:"-"`
+	ForwardingAddresses []ForwardingAddress `json:"forwarding_addresses,omitempty" yaml:"forwarding_addresses,omitempty"`
+	AutoForwarding      *AutoForwarding     `json:"auto_forwarding,omitempty" yaml:"auto_forwarding,omitempty"`
+	IMAP                *IMAPSettings       `json:"imap,omitempty" yaml:"imap,omitempty"`
+	POP                 *POPSettings        `json:"pop,omitempty" yaml:"pop,omitempty"`
+	Language            *LanguageSettings   `json:"language,omitempty" yaml:"language,omitempty"`
+}
+
+// ForwardingAddress is an address mail can be forwarded to once verified
+type ForwardingAddress struct {
+	Email              string `json:"email" yaml:"email"`
+	VerificationStatus string `json:"verification_status,omitempty" yaml:"-"`
+}
+
+// AutoForwarding controls forwarding of all incoming mail. In a settings
+// profile, fields left out are not changed.
+type AutoForwarding struct {
+	Enabled *bool  `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Email   string `json:"email,omitempty" yaml:"email,omitempty"`
+	// Disposition is what happens to the original: leaveInInbox, archive,
+	// trash or markRead
+	Disposition string `json:"disposition,omitempty" yaml:"disposition,omitempty"`
+}
+
+// IsEnabled reports whether forwarding is on
+func (f AutoForwarding) IsEnabled() bool {
+	return f.Enabled != nil && *f.Enabled
+}
+
+func (f AutoForwarding) String() string {
+	if !f.IsEnabled() {
+		return "off"
+	}
+	return fmt.Sprintf("to %s, %s", f.Email, f.Disposition)
+}
+
+// IMAPSettings control IMAP access. In a settings profile, fields left out
+// are not changed.
+type IMAPSettings struct {
+	Enabled     *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	AutoExpunge *bool `json:"auto_expunge,omitempty" yaml:"auto_expunge,omitempty"`
+	// ExpungeBehavior is what happens to deleted messages: archive, trash
+	// or deleteForever
+	ExpungeBehavior string `json:"expunge_behavior,omitempty" yaml:"expunge_behavior,omitempty"`
+	// MaxFolderSize limits the messages shown per folder; 0 is no limit
+	MaxFolderSize *int64 `json:"max_folder_size,omitempty" yaml:"max_folder_size,omitempty"`
+}
+
+// IsEnabled reports whether IMAP access is on
+func (s IMAPSettings) IsEnabled() bool {
+	return s.Enabled != nil && *s.Enabled
+}
+
+// IsAutoExpunge reports whether messages are expunged as soon as they are
+// marked deleted
+func (s IMAPSettings) IsAutoExpunge() bool {
+	return s.AutoExpunge != nil && *s.AutoExpunge
+}
+
+// FolderLimit returns the maximum number of messages shown per folder, or
+// 0 for no limit
+func (s IMAPSettings) FolderLimit() int64 {
+	if s.MaxFolderSize == nil {
+		return 0
+	}
+	return *s.MaxFolderSize
+}
+
+func (s IMAPSettings) String() string {
+	if !s.IsEnabled() {
+		return "off"
+	}
+	parts := []string{"on"}
+	if s.IsAutoExpunge() {
+		parts = append(parts, "auto-expunge")
+	}
+	if s.ExpungeBehavior != "" {
+		parts = append(parts, "deleted: "+s.ExpungeBehavior)
+	}
+	if s.FolderLimit() > 0 {
+		parts = append(parts, fmt.Sprintf("folder limit: %d", s.FolderLimit()))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// POPSettings control POP access
+type POPSettings struct {
+	// AccessWindow is disabled, fromNowOn or allMail
+	AccessWindow string `json:"access_window" yaml:"access_window"`
+	// Disposition is what happens to downloaded messages: leaveInInbox,
+	// archive, trash or markRead
+	Disposition string `json:"disposition,omitempty" yaml:"disposition,omitempty"`
+}
+
+func (s POPSettings) String() string {
+	if s.AccessWindow == "disabled" || s.AccessWindow == "" {
+		return "off"
+	}
+	return fmt.Sprintf("%s, %s", s.AccessWindow, s.Disposition)
+}
+
+// LanguageSettings control the language of the Gmail interface
+type LanguageSettings struct {
+	DisplayLanguage string `json:"display_language" yaml:"display_language"`
+}
+
+func (s LanguageSettings) String() string {
+	return s.DisplayLanguage
+}
+
+// PrintMailSettings prints the settings of one or more accounts
+func PrintMailSettings(settings []MailSettings) {
+	if JSONOutput {
+		PrintJSON(settings)
+		return
+	}
+
+	for i, s := range settings {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Account:     %s\n", s.Account)
+		if s.AutoForwarding != nil {
+			fmt.Printf("Forwarding:  %s\n", s.AutoForwarding)
+		}
+		for j, a := range s.ForwardingAddresses {
+			label := ""
+			if j == 0 {
+				label = "Addresses:"
+			}
+			status := "Pending"
+			if a.VerificationStatus == "accepted" {
+				status = "Verified"
+			}
+			fmt.Printf("%-13s%s (%s)\n", label, a.Email, status)
+		}
+		if s.IMAP != nil {
+			fmt.Printf("IMAP:        %s\n", s.IMAP)
+		}
+		if s.POP != nil {
+			fmt.Printf("POP:         %s\n", s.POP)
+		}
+		if s.Language != nil {
+			fmt.Printf("Language:    %s\n", s.Language)
+		}
+	}
+}
+
+// Delegate is a user who can access another account's mailbox
+type Delegate struct {
+	Account            string `json:"account,omitempty"`
+	Email              string `json:"email"`
+	VerificationStatus string `json:"verification_status"`
+}
+
+// PrintDelegates prints mailbox delegates
+func PrintDelegates(delegates []Delegate) {
+	if JSONOutput {
+		PrintJSON(delegates)
+		return
+	}
+
+	if len(delegates) == 0 {
+		fmt.Println("No delegates found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DELEGATE\tSTATUS\tACCOUNT")
+	fmt.Fprintln(w, "────────\t──────\t───────")
+
+	for _, d := range delegates {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", d.Email, delegateStatus(d.VerificationStatus), d.Account)
+	}
+	w.Flush()
+}
+
+// delegateStatus describes a delegate's verification status
+func delegateStatus(status string) string {
+	switch status {
+	case "accepted":
+		return "Accepted"
+	case "pending":
+		return "Pending (invitation sent)"
+	case "rejected":
+		return "Rejected"
+	case "expired":
+		return "Expired"
+	}
+	return status
+}
+
+// ContactField is a contact's email address or phone number with its type,
+// such as home or work
+type ContactField struct {
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
+}
+
+func (f ContactField) String() string {
+	if f.Type == "" {
+		return f.Value
+	}
+	return fmt.Sprintf("%s (%s)", f.Value, f.Type)
+}
+
+// Contact represents a contact for display
+type Contact struct {
+	Account      string           `json:"account,omitempty"`
+	ID           string           `json:"id"`
+	Name         string           `json:"name,omitempty"`
+	GivenName    string           `json:"given_name,omitempty"`
+	FamilyName   string           `json:"family_name,omitempty"`
+	Emails       []ContactField   `json:"emails,omitempty"`
+	Phones       []ContactField   `json:"phones,omitempty"`
+	Addresses    []ContactAddress `json:"addresses,omitempty"`
+	URLs         []ContactField   `json:"urls,omitempty"`
+	Organization string           `json:"organization,omitempty"`
+	Title        string           `json:"title,omitempty"`
+	// Birthday is YYYY-MM-DD, or --MM-DD when the year is not known
+	Birthday string `json:"birthday,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+// ContactAddress is a contact's postal address
+type ContactAddress struct {
+	Type       string `json:"type,omitempty"`
+	Street     string `json:"street,omitempty"`
+	City       string `json:"city,omitempty"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
+	// Formatted is the whole address as written, for addresses that are
+	// not split into parts
+	Formatted string `json:"formatted,omitempty"`
+}
+
+// String formats the address on one line
+func (a ContactAddress) String() string {
+	var parts []string
+	for _, p := range []string{a.Street, a.City, strings.TrimSpace(a.Region + " " + a.PostalCode), a.Country} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	s := strings.Join(parts, ", ")
+	if s == "" {
+		s = strings.Join(strings.Fields(a.Formatted), " ")
+	}
+	if a.Type != "" {
+		s += " (" + a.Type + ")"
+	}
+	return s
+}
+
+// PrintContacts prints a list of contacts
+func PrintContacts(contacts []Contact) {
+	if JSONOutput {
+		PrintJSON(contacts)
+		return
+	}
+
+	if len(contacts) == 0 {
+		fmt.Println("No contacts found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tEMAIL\tPHONE\tORGANIZATION\tACCOUNT")
+	fmt.Fprintln(w, "──\t────\t─────\t─────\t────────────\t───────")
+
+	for _, c := range contacts {
+		email, phone := "", ""
+		if len(c.Emails) > 0 {
+			email = c.Emails[0].Value
+			if len(c.Emails) > 1 {
+				email += fmt.Sprintf(" (+%d)", len(c.Emails)-1)
+			}
+		}
+		if len(c.Phones) > 0 {
+			phone = c.Phones[0].Value
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			c.ID, truncate(c.Name, 30), email, phone, truncate(c.Organization, 25), c.Account)
+	}
+	w.Flush()
+}
+
+// PrintContactDetail prints all fields of a contact
+func PrintContactDetail(c Contact) {
+	if JSONOutput {
+		PrintJSON(c)
+		return
+	}
+
+	fmt.Printf("ID:            %s\n", c.ID)
+	fmt.Printf("Name:          %s\n", c.Name)
+	for i, e := range c.Emails {
+		label := ""
+		if i == 0 {
+			label = "Email:"
+		}
+		fmt.Printf("%-15s%s\n", label, e)
+	}
+	for i, p := range c.Phones {
+		label := ""
+		if i == 0 {
+			label = "Phone:"
+		}
+		fmt.Printf("%-15s%s\n", label, p)
+	}
+	for i, a := range c.Addresses {
+		label := ""
+		if i == 0 {
+			label = "Address:"
+		}
+		fmt.Printf("%-15s%s\n", label, a)
+	}
+	for i, u := range c.URLs {
+		label := ""
+		if i == 0 {
+			label = "Website:"
+		}
+		fmt.Printf("%-15s%s\n", label, u)
+	}
+	if c.Organization != "" {
+		fmt.Printf("Organization:  %s\n", c.Organization)
+	}
+	if c.Title != "" {
+		fmt.Printf("Title:         %s\n", c.Title)
+	}
+	if c.Birthday != "" {
+		fmt.Printf("Birthday:      %s\n", c.Birthday)
+	}
+	if c.Notes != "" {
+		fmt.Printf("\n%s\n", c.Notes)
+	}
+}
+
+// TemplateInfo represents an email template for display
+type TemplateInfo struct {
+	Name    string `json:"name"`
+	Format  string `json:"format"`
+	Subject string `json:"subject,omitempty"`
+	Path    string `json:"path"`
+}
+
+// PrintTemplates prints a list of email templates
+func PrintTemplates(templates []TemplateInfo, dir string) {
+	if JSONOutput {
+		PrintJSON(templates)
+		return
+	}
+
+	if len(templates) == 0 {
+		fmt.Println("No templates found.")
+		fmt.Printf("\nAdd .txt, .html or .md files to %s to create templates.\n", dir)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tFORMAT\tSUBJECT")
+	fmt.Fprintln(w, "────\t──────\t───────")
+
+	for _, t := range templates {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", t.Name, t.Format, truncate(t.Subject, 50))
+	}
+	w.Flush()
+}
+
+// MergeResult represents the outcome of one mail merge row
+type MergeResult struct {
+	Row     int      `json:"row"`
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`
+	Status  string   `json:"status"`
+	ID      string   `json:"id,omitempty"`
+	Body    string   `json:"body,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Subscription represents a mailing list sender that can be unsubscribed
+// from
+type Subscription struct {
+	Account  string    `json:"account,omitempty"`
+	Sender   Address   `json:"sender"`
+	Messages int       `json:"messages"`
+	Latest   time.Time `json:"latest"`
+	// Method is how to unsubscribe: one-click, mailto or web
+	Method     string   `json:"method"`
+	URL        string   `json:"url,omitempty"`
+	Mailto     string   `json:"mailto,omitempty"`
+	MessageIDs []string `json:"-"`
+}
+
+// PrintSubscriptions prints a numbered list of subscriptions for review
+func PrintSubscriptions(subs []Subscription) {
+	if JSONOutput {
+		PrintJSON(subs)
+		return
+	}
+
+	if len(subs) == 0 {
+		fmt.Println("No mailing lists found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tSENDER\tEMAILS\tLATEST\tMETHOD\tACCOUNT")
+	fmt.Fprintln(w, "─\t──────\t──────\t──────\t──────\t───────")
+
+	for i, s := range subs {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\t%s\n",
+			i+1, truncate(s.Sender.String(), 50), s.Messages, s.Latest.Local().Format("2006-01-02"), s.Method, s.Account)
+	}
+	w.Flush()
+}
+
+// UnsubscribeResult represents the outcome of unsubscribing from a sender
+type UnsubscribeResult struct {
+	Account  string `json:"account,omitempty"`
+	Sender   string `json:"sender"`
+	Method   string `json:"method"`
+	Status   string `json:"status"`
+	URL      string `json:"url,omitempty"`
+	Archived int    `json:"archived,omitempty"`
+	FilterID string `json:"filter_id,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// StatCount is a count for one key in mailbox statistics
+type StatCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// ReplyLatency summarizes how long replies to received emails took
+type ReplyLatency struct {
+	Replies       int         `json:"replies"`
+	MedianSeconds int64       `json:"median_seconds"`
+	MeanSeconds   int64       `json:"mean_seconds"`
+	P90Seconds    int64       `json:"p90_seconds"`
+	Buckets       []StatCount `json:"buckets"`
+}
+
+// MailStats represents mailbox statistics over a date range
+type MailStats struct {
+	Accounts      []string     `json:"accounts"`
+	After         time.Time    `json:"after"`
+	Before        time.Time    `json:"before"`
+	Messages      int          `json:"messages"`
+	Received      int          `json:"received"`
+	Sent          int          `json:"sent"`
+	Threads       int          `json:"threads"`
+	Senders       []StatCount  `json:"senders"`
+	Domains       []StatCount  `json:"domains"`
+	Labels        []StatCount  `json:"labels"`
+	Weekdays      []StatCount  `json:"weekdays"`
+	Hours         []StatCount  `json:"hours"`
+	UnreadAge     []StatCount  `json:"unread_age"`
+	ThreadLengths []StatCount  `json:"thread_lengths"`
+	ReplyLatency  ReplyLatency `json:"reply_latency"`
+}
+
+// PrintMailStats prints mailbox statistics as tables with bar charts
+func PrintMailStats(s MailStats) {
+	if JSONOutput {
+		PrintJSON(s)
+		return
+	}
+
+	fmt.Printf("Accounts: %s\n", strings.Join(s.Accounts, ", "))
+	fmt.Printf("Period:   %s to %s\n", s.After.Format("2006-01-02"), s.Before.AddDate(0, 0, -1).Format("2006-01-02"))
+	fmt.Printf("Emails:   %d received, %d sent, %d threads\n", s.Received, s.Sent, s.Threads)
+
+	printStatSection("Top senders", s.Senders, false)
+	printStatSection("Top domains", s.Domains, false)
+	printStatSection("Labels", s.Labels, false)
+	printStatSection("Received by day of week", s.Weekdays, true)
+	printStatSection("Received by hour", s.Hours, true)
+	printStatSection("Unread by age", s.UnreadAge, true)
+	printStatSection("Thread length (emails)", s.ThreadLengths, true)
+
+	r := s.ReplyLatency
+	fmt.Printf("\nReply latency (%d replies)\n", r.Replies)
+	if r.Replies > 0 {
+		fmt.Printf("  Median: %s  Mean: %s  90th percentile: %s\n",
+			formatSeconds(r.MedianSeconds), formatSeconds(r.MeanSeconds), formatSeconds(r.P90Seconds))
+		printStatRows(r.Buckets, true)
+	}
+}
+
+// printStatSection prints a titled list of counts
+func printStatSection(title string, counts []StatCount, bars bool) {
+	fmt.Printf("\n%s\n", title)
+	if len(counts) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+	printStatRows(counts, bars)
+}
+
+// printStatRows prints counts, optionally with bars scaled to the largest
+func printStatRows(counts []StatCount, bars bool) {
+	largest := 0
+	for _, c := range counts {
+		largest = max(largest, c.Count)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, c := range counts {
+		bar := ""
+		if bars && largest > 0 {
+			bar = strings.Repeat("█", c.Count*30/largest)
+		}
+		fmt.Fprintf(w, "  %s\t%d\t%s\n", truncate(c.Key, 50), c.Count, bar)
+	}
+	w.Flush()
+}
+
+// formatSeconds formats a duration in seconds as e.g. "3h12m" or "2d4h"
+func formatSeconds(secs int64) string {
+	d := time.Duration(secs) * time.Second
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", secs)
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
+
+// PrintMailStatsCSV prints mailbox statistics as section,key,count rows
+func PrintMailStatsCSV(s MailStats) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"section", "key", "count"})
+
+	write := func(section string, counts []StatCount) {
+		for _, c := range counts {
+			w.Write([]string{section, c.Key, strconv.Itoa(c.Count)})
+		}
+	}
+	write("total", []StatCount{
+		{Key: "received", Count: s.Received},
+		{Key: "sent", Count: s.Sent},
+		{Key: "threads", Count: s.Threads},
+	})
+	write("sender", s.Senders)
+	write("domain", s.Domains)
+	write("label", s.Labels)
+	write("weekday", s.Weekdays)
+	write("hour", s.Hours)
+	write("unread_age", s.UnreadAge)
+	write("thread_length", s.ThreadLengths)
+	write("reply_latency", s.ReplyLatency.Buckets)
+	w.Write([]string{"reply_latency_seconds", "median", strconv.FormatInt(s.ReplyLatency.MedianSeconds, 10)})
+	w.Write([]string{"reply_latency_seconds", "mean", strconv.FormatInt(s.ReplyLatency.MeanSeconds, 10)})
+	w.Write([]string{"reply_latency_seconds", "p90", strconv.FormatInt(s.ReplyLatency.P90Seconds, 10)})
+
+	w.Flush()
+	return w.Error()
+}
+
+// SnoozedThread represents a snoozed thread for display
+type SnoozedThread struct {
+	ID        string    `json:"id"`
+	Account   string    `json:"account"`
+	ThreadID  string    `json:"thread_id"`
+	From      string    `json:"from"`
+	Subject   string    `json:"subject"`
+	Until     time.Time `json:"until"`
+	SnoozedAt time.Time `json:"snoozed_at"`
+}
+
+// PrintSnoozedThreads prints a list of snoozed threads
+func PrintSnoozedThreads(threads []SnoozedThread) {
+	if JSONOutput {
+		PrintJSON(threads)
+		return
+	}
+
+	if len(threads) == 0 {
+		fmt.Println("No snoozed emails.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tFROM\tSUBJECT\tUNTIL\tACCOUNT")
+	fmt.Fprintln(w, "──\t────\t───────\t─────\t───────")
+
+	for _, t := range threads {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			t.ID, truncate(t.From, 25), truncate(t.Subject, 40), t.Until.Local().Format("2006-01-02 15:04"), t.Account)
+	}
+	w.Flush()
+}
+
+// Followup represents a sent email awaiting a reply
+type Followup struct {
+	ID       string    `json:"id"`
+	Account  string    `json:"account"`
+	To       []string  `json:"to"`
+	Subject  string    `json:"subject"`
+	SentAt   time.Time `json:"sent_at"`
+	Deadline time.Time `json:"deadline"`
+	// Status is waiting, overdue or replied
+	Status    string `json:"status"`
+	Reinboxed bool   `json:"reinboxed,omitempty"`
+}
+
+// PrintFollowups prints a list of tracked follow-ups
+func PrintFollowups(followups []Followup) {
+	if JSONOutput {
+		PrintJSON(followups)
+		return
+	}
+
+	if len(followups) == 0 {
+		fmt.Println("No emails awaiting replies.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTO\tSUBJECT\tSENT\tREPLY BY\tSTATUS\tACCOUNT")
+	fmt.Fprintln(w, "──\t──\t───────\t────\t────────\t──────\t───────")
+
+	for _, f := range followups {
+		var status string
+		switch f.Status {
+		case "replied":
+			status = "✅ Replied"
+		case "overdue":
+			status = "⚠️  Overdue"
+		default:
+			status = "⏳ Waiting"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			f.ID, truncate(strings.Join(f.To, ", "), 25), truncate(f.Subject, 30),
+			f.SentAt.Local().Format("2006-01-02"), f.Deadline.Local().Format("2006-01-02 15:04"), status, f.Account)
+	}
+	w.Flush()
+}
+
+// RuleInfo represents a mail processing rule for display
+type RuleInfo struct {
+	Name       string   `json:"name"`
+	Query      string   `json:"query"`
+	Conditions []string `json:"conditions,omitempty"`
+	Actions    []string `json:"actions"`
+	Stop       bool     `json:"stop,omitempty"`
+}
+
+// PrintRules prints mail processing rules in the order they are applied
+func PrintRules(rules []RuleInfo, path string) {
+	if JSONOutput {
+		PrintJSON(rules)
+		return
+	}
+
+	if len(rules) == 0 {
+		fmt.Printf("No rules in %s.\n", path)
+		return
+	}
+
+	for i, r := range rules {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%d. %s\n", i+1, r.Name)
+		fmt.Printf("   Query: %s\n", r.Query)
+		if len(r.Conditions) > 0 {
+			fmt.Printf("   Match: %s\n", strings.Join(r.Conditions, ", "))
+		}
+		fmt.Printf("   Do:    %s\n", strings.Join(r.Actions, ", "))
+		if r.Stop {
+			fmt.Println("   Stops later rules")
+		}
+	}
+}
+
+// EventConflict is a pair of overlapping calendar events
+type EventConflict struct {
+	First  CalendarEventSummary `json:"first"`
+	Second CalendarEventSummary `json:"second"`
+}
+
+// DigestAccount summarizes one account's mail for the digest
+type DigestAccount struct {
+	Account    string         `json:"account"`
+	Unread     int64          `json:"unread"`
+	TopSenders []StatCount    `json:"top_senders,omitempty"`
+	Starred    []EmailSummary `json:"starred,omitempty"`
+	// MailError and CalendarError report parts of the account that could
+	// not be fetched
+	MailError     string `json:"mail_error,omitempty"`
+	CalendarError string `json:"calendar_error,omitempty"`
+}
+
+// DigestDay lists the events on one day of the digest
+type DigestDay struct {
+	Date   time.Time              `json:"date"`
+	Events []CalendarEventSummary `json:"events"`
+}
+
+// Digest summarizes mail and calendar across accounts
+type Digest struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Accounts    []DigestAccount  `json:"accounts"`
+	Days        []DigestDay      `json:"days"`
+	Conflicts   []EventConflict  `json:"conflicts,omitempty"`
+	Scheduled   []ScheduledEmail `json:"scheduled,omitempty"`
+}
+
+// PrintDigest prints a digest for the terminal
+func PrintDigest(d Digest) {
+	if JSONOutput {
+		PrintJSON(d)
+		return
+	}
+
+	fmt.Printf("📬 Digest for %s\n", d.GeneratedAt.Format("Monday, January 2, 2006"))
+
+	fmt.Println("\nMAIL")
+	for _, a := range d.Accounts {
+		if a.MailError != "" {
+			fmt.Printf("  %s: ❌ %s\n", a.Account, a.MailError)
+			continue
+		}
+		fmt.Printf("  %s: %d unread\n", a.Account, a.Unread)
+		if len(a.TopSenders) > 0 {
+			fmt.Printf("    Top senders: %s\n", formatStatCounts(a.TopSenders))
+		}
+		for _, e := range a.Starred {
+			fmt.Printf("    ⭐ %s — %s (%s)\n", truncate(e.From, 30), truncate(e.Subject, 50), e.Date.Local().Format("Jan 2"))
+		}
+	}
+
+	fmt.Println("\nCALENDAR")
+	for _, a := range d.Accounts {
+		if a.CalendarError != "" {
+			fmt.Printf("  %s: ❌ %s\n", a.Account, a.CalendarError)
+		}
+	}
+	for i, day := range d.Days {
+		fmt.Printf("  %s\n", digestDayTitle(i, day.Date))
+		if len(day.Events) == 0 {
+			fmt.Println("    No events")
+		}
+		for _, e := range day.Events {
+			fmt.Printf("    %-13s %s (%s)\n", digestEventTime(e, day.Date), e.Summary, e.Account)
+		}
+	}
+	if len(d.Conflicts) > 0 {
+		fmt.Println("  ⚠️  Conflicts")
+		for _, c := range d.Conflicts {
+			fmt.Printf("    %s\n", digestConflict(c))
+		}
+	}
+
+	if len(d.Scheduled) > 0 {
+		fmt.Println("\nSCHEDULED")
+		for _, s := range d.Scheduled {
+			fmt.Printf("  %s\n", digestScheduled(s))
+		}
+	}
+}
+
+// DigestMarkdown renders a digest as a markdown document
+func DigestMarkdown(d Digest) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Digest for %s\n\n", d.GeneratedAt.Format("Monday, January 2, 2006"))
+
+	b.WriteString("## Mail\n\n")
+	for _, a := range d.Accounts {
+		if a.MailError != "" {
+			fmt.Fprintf(&b, "**%s**: could not be checked (%s)\n\n", escapeMarkdown(a.Account), escapeMarkdown(a.MailError))
+			continue
+		}
+		fmt.Fprintf(&b, "**%s**: %d unread\n\n", escapeMarkdown(a.Account), a.Unread)
+		if len(a.TopSenders) > 0 {
+			fmt.Fprintf(&b, "Top senders: %s\n\n", escapeMarkdown(formatStatCounts(a.TopSenders)))
+		}
+		if len(a.Starred) > 0 {
+			b.WriteString("Starred:\n\n")
+			for _, e := range a.Starred {
+				fmt.Fprintf(&b, "- %s — %s (%s)\n", escapeMarkdown(e.From), escapeMarkdown(e.Subject), e.Date.Local().Format("Jan 2"))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("## Calendar\n\n")
+	for _, a := range d.Accounts {
+		if a.CalendarError != "" {
+			fmt.Fprintf(&b, "**%s**: could not be checked (%s)\n\n", escapeMarkdown(a.Account), escapeMarkdown(a.CalendarError))
+		}
+	}
+	for i, day := range d.Days {
+		fmt.Fprintf(&b, "### %s\n\n", digestDayTitle(i, day.Date))
+		if len(day.Events) == 0 {
+			b.WriteString("No events\n\n")
+			continue
+		}
+		for _, e := range day.Events {
+			fmt.Fprintf(&b, "- %s %s *(%s)*\n", digestEventTime(e, day.Date), escapeMarkdown(e.Summary), escapeMarkdown(e.Account))
+		}
+		b.WriteString("\n")
+	}
+	if len(d.Conflicts) > 0 {
+		b.WriteString("### Conflicts\n\n")
+		for _, c := range d.Conflicts {
+			fmt.Fprintf(&b, "- %s\n", escapeMarkdown(digestConflict(c)))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(d.Scheduled) > 0 {
+		b.WriteString("## Scheduled emails\n\n")
+		for _, s := range d.Scheduled {
+			fmt.Fprintf(&b, "- %s\n", escapeMarkdown(digestScheduled(s)))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// digestDayTitle names a digest day relative to the first one
+func digestDayTitle(i int, date time.Time) string {
+	switch i {
+	case 0:
+		return "Today, " + date.Format("Mon Jan 2")
+	case 1:
+		return "Tomorrow, " + date.Format("Mon Jan 2")
+	}
+	return date.Format("Monday, Jan 2")
+}
+
+// digestEventTime formats when an event happens on a given day
+func digestEventTime(e CalendarEventSummary, day time.Time) string {
+	if e.AllDay {
+		return "All day"
+	}
+	start, end := e.Start.Local(), e.End.Local()
+	from, to := start.Format("15:04"), end.Format("15:04")
+	if start.Before(day) {
+		from = start.Format("Jan 2 15:04")
+	}
+	if !end.Before(day.AddDate(0, 0, 1)) {
+		to = end.Format("Jan 2 15:04")
+	}
+	return from + "–" + to
+}
+
+// digestConflict describes a pair of overlapping events
+func digestConflict(c EventConflict) string {
+	return fmt.Sprintf("%s %s (%s) overlaps %s %s (%s)",
+		c.First.Start.Local().Format("Mon 15:04"), c.First.Summary, c.First.Account,
+		c.Second.Start.Local().Format("15:04"), c.Second.Summary, c.Second.Account)
+}
+
+// digestScheduled describes a scheduled email
+func digestScheduled(s ScheduledEmail) string {
+	line := fmt.Sprintf("%s %s → %s (%s)", s.ScheduledAt.Local().Format("Mon Jan 2 15:04"), s.Subject, strings.Join(s.To, ", "), s.Account)
+	if s.Error != "" {
+		line += " — failed: " + s.Error
+	}
+	return line
+}
+
+// formatStatCounts formats counts as "key (n), key (n)"
+func formatStatCounts(counts []StatCount) string {
+	parts := make([]string, len(counts))
+	for i, c := range counts {
+		parts[i] = fmt.Sprintf("%s (%d)", c.Key, c.Count)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// markdownEscaper backslash-escapes characters with meaning in markdown so
+// text such as subjects is shown literally
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `#`, `\#`, `|`, `\|`, `~`, `\~`,
+)
+
+// escapeMarkdown escapes text for inclusion in markdown
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// AuthResult is one method's result from an Authentication-Results header
+type AuthResult struct {
+	// Server is the authserv-id of the server that checked the message
+	Server  string `json:"server"`
+	Method  string `json:"method"`
+	Result  string `json:"result"`
+	Details string `json:"details,omitempty"`
+}
+
+// ReceivedHop is one server on a message's delivery path
+type ReceivedHop struct {
+	From string    `json:"from,omitempty"`
+	IP   string    `json:"ip,omitempty"`
+	By   string    `json:"by,omitempty"`
+	With string    `json:"with,omitempty"`
+	Date time.Time `json:"date,omitempty"`
+	Raw  string    `json:"raw"`
+}
+
+// LinkInspection is a link in a message body and why it looks suspicious,
+// if it does
+type LinkInspection struct {
+	Text    string   `json:"text"`
+	Href    string   `json:"href"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// MessageInspection holds the evidence used to triage a suspicious email
+type MessageInspection struct {
+	ID         string           `json:"id"`
+	Account    string           `json:"account,omitempty"`
+	From       Address          `json:"from"`
+	ReplyTo    []Address        `json:"reply_to,omitempty"`
+	ReturnPath string           `json:"return_path,omitempty"`
+	Subject    string           `json:"subject"`
+	Auth       []AuthResult     `json:"authentication"`
+	Hops       []ReceivedHop    `json:"received"`
+	Links      []LinkInspection `json:"links,omitempty"`
+	Warnings   []string         `json:"warnings,omitempty"`
+}
+
+// PrintMessageInspection prints authentication results, the delivery path
+// and suspicious links of a message
+func PrintMessageInspection(in MessageInspection) {
+	if JSONOutput {
+		PrintJSON(in)
+		return
+	}
+
+	fmt.Println(strings.Repeat("─", 80))
+	fmt.Printf("ID:          %s\n", in.ID)
+	fmt.Printf("From:        %s\n", in.From)
+	if len(in.ReplyTo) > 0 {
+		fmt.Printf("Reply-To:    %s\n", FormatAddresses(in.ReplyTo))
+	}
+	if in.ReturnPath != "" {
+		fmt.Printf("Return-Path: %s\n", in.ReturnPath)
+	}
+	fmt.Printf("Subject:     %s\n", in.Subject)
+	fmt.Println(strings.Repeat("─", 80))
+
+	fmt.Println("\nAUTHENTICATION")
+	if len(in.Auth) == 0 {
+		fmt.Println("  No Authentication-Results headers")
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, a := range in.Auth {
+		icon := "❌"
+		switch a.Result {
+		case "pass":
+			icon = "✅"
+		case "none", "neutral":
+			icon = "➖"
+		}
+		fmt.Fprintf(w, "  %s %s\t%s\t%s\t%s\n", icon, strings.ToUpper(a.Method), a.Result, a.Server, truncate(a.Details, 60))
+	}
+	w.Flush()
+
+	fmt.Println("\nDELIVERY PATH (sender first)")
+	if len(in.Hops) == 0 {
+		fmt.Println("  No Received headers")
+	}
+	for i, h := range in.Hops {
+		from := h.From
+		if from == "" {
+			from = "?"
+		}
+		if h.IP != "" {
+			from += " [" + h.IP + "]"
+		}
+		line := fmt.Sprintf("  %d. %s → %s", i+1, from, h.By)
+		if h.With != "" {
+			line += " (" + h.With + ")"
+		}
+		if !h.Date.IsZero() {
+			line += "  " + h.Date.Local().Format("2006-01-02 15:04:05")
+			if i > 0 && !in.Hops[i-1].Date.IsZero() {
+				if delay := h.Date.Sub(in.Hops[i-1].Date); delay >= time.Second || delay < 0 {
+					line += fmt.Sprintf(" (+%s)", delay.Round(time.Second))
+				}
+			}
+		}
+		fmt.Println(line)
+	}
+
+	suspicious := 0
+	for _, l := range in.Links {
+		if len(l.Reasons) > 0 {
+			suspicious++
+		}
+	}
+	fmt.Printf("\nLINKS (%d, %d suspicious)\n", len(in.Links), suspicious)
+	for _, l := range in.Links {
+		if len(l.Reasons) == 0 {
+			continue
+		}
+		fmt.Printf("  ⚠️  %q → %s\n", truncate(l.Text, 50), l.Href)
+		for _, r := range l.Reasons {
+			fmt.Printf("      %s\n", r)
+		}
+	}
+
+	fmt.Println("\nVERDICT")
+	if len(in.Warnings) == 0 {
+		fmt.Println("  ✅ Nothing suspicious found")
+	}
+	for _, warning := range in.Warnings {
+		fmt.Printf("  ⚠️  %s\n", warning)
+	}
+}
+
+// Header is a message header as received
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PrintHeaders prints message headers in order, one per line
+func PrintHeaders(headers []Header) {
+	if JSONOutput {
+		PrintJSON(headers)
+		return
+	}
+
+	for _, h := range headers {
+		fmt.Printf("%s: %s\n", h.Name, h.Value)
+	}
+}
+
+// MIMEPart describes one part of a message's MIME structure
+type MIMEPart struct {
+	PartID      string `json:"part_id"`
+	MimeType    string `json:"mime_type"`
+	Filename    string `json:"filename,omitempty"`
+	Charset     string `json:"charset,omitempty"`
+	Encoding    string `json:"encoding,omitempty"`
+	Disposition string `json:"disposition,omitempty"`
+	// Size is the decoded size of the part's body in bytes
+	Size int64 `json:"size"`
+	// Body is "text" or "html" for the parts shown as the message body
+	Body  string     `json:"body,omitempty"`
+	Parts []MIMEPart `json:"parts,omitempty"`
+}
+
+// PrintMIMETree prints a message's MIME structure as a tree
+func PrintMIMETree(root MIMEPart) {
+	if JSONOutput {
+		PrintJSON(root)
+		return
+	}
+
+	fmt.Println(describeMIMEPart(root))
+	printMIMEChildren(root.Parts, "")
+}
+
+// printMIMEChildren prints the children of a MIME part with tree lines
+func printMIMEChildren(parts []MIMEPart, prefix string) {
+	for i, p := range parts {
+		branch, indent := "├── ", "│   "
+		if i == len(parts)-1 {
+			branch, indent = "└── ", "    "
+		}
+		fmt.Println(prefix + branch + describeMIMEPart(p))
+		printMIMEChildren(p.Parts, prefix+indent)
+	}
+}
+
+// describeMIMEPart summarizes a MIME part on one line
+func describeMIMEPart(p MIMEPart) string {
+	fields := []string{p.MimeType}
+	if p.PartID != "" {
+		fields[0] = "[" + p.PartID + "] " + p.MimeType
+	}
+	if p.Charset != "" {
+		fields = append(fields, "charset="+p.Charset)
+	}
+	if p.Filename != "" {
+		fields = append(fields, fmt.Sprintf("%q", p.Filename))
+	}
+	if p.Disposition != "" {
+		fields = append(fields, p.Disposition)
+	}
+	if p.Encoding != "" {
+		fields = append(fields, p.Encoding)
+	}
+	if !strings.HasPrefix(p.MimeType, "multipart/") {
+		fields = append(fields, formatBytes(p.Size))
+	}
+	line := strings.Join(fields, "  ")
+	switch p.Body {
+	case "text":
+		line += "  ← text body"
+	case "html":
+		line += "  ← HTML body"
+	}
+	return line
+}
+
+// formatBytes formats a byte count for display
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,325 @@
+package rules
+
+import (
+	"fmt"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alexandraswan/gcli/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+const rulesFileName = "rules.yaml"
+
+// DefaultQuery is searched by rules that do not set a query
+const DefaultQuery = "in:inbox"
+
+// File is the layout of a rules file
+type File struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Rule applies actions to messages found by a Gmail search that also meet
+// its conditions
+type Rule struct {
+	Name    string     `yaml:"name"`
+	Query   string     `yaml:"query,omitempty"`
+	Match   Conditions `yaml:"match,omitempty"`
+	Actions Actions    `yaml:"actions"`
+	// Stop prevents later rules from being applied to a matched message
+	Stop bool `yaml:"stop,omitempty"`
+
+	from, to, subject, body, attachment *regexp.Regexp
+	headers                             []headerPattern
+}
+
+// headerPattern is a compiled header condition
+type headerPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// Conditions are regular expressions, matched case-insensitively, that a
+// message must all satisfy
+type Conditions struct {
+	From    string `yaml:"from,omitempty"`
+	To      string `yaml:"to,omitempty"`
+	Subject string `yaml:"subject,omitempty"`
+	Body    string `yaml:"body,omitempty"`
+	// Headers maps header names to patterns; a missing header matches as
+	// an empty value
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Attachment matches the filename or content type of any attachment
+	Attachment string `yaml:"attachment,omitempty"`
+}
+
+// Actions are applied in a fixed order to each matched message
+type Actions struct {
+	Label    []string `yaml:"label,omitempty"`
+	Archive  bool     `yaml:"archive,omitempty"`
+	MarkRead bool     `yaml:"mark_read,omitempty"`
+	Forward  []string `yaml:"forward,omitempty"`
+	// ReplyTemplate names an email template rendered with the message's
+	// fields as variables
+	ReplyTemplate string `yaml:"reply_template,omitempty"`
+	// SaveAttachments is a directory to save attachments to. When the rule
+	// has an attachment condition only matching attachments are saved.
+	SaveAttachments string `yaml:"save_attachments,omitempty"`
+	// Exec is a shell command run with the message as JSON on stdin
+	Exec string `yaml:"exec,omitempty"`
+}
+
+// Message holds the parts of an email that rules match against
+type Message struct {
+	From        string
+	To          []string
+	Subject     string
+	Body        string
+	Headers     map[string]string
+	Attachments []Attachment
+}
+
+// Attachment describes an attachment for matching
+type Attachment struct {
+	Filename string
+	MimeType string
+}
+
+// GetRulesPath returns the path to the default rules file
+func GetRulesPath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, rulesFileName), nil
+}
+
+// Load reads and validates a rules file. An empty path loads the default
+// rules file.
+func Load(path string) ([]*Rule, error) {
+	if path == "" {
+		var err error
+		if path, err = GetRulesPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no rules file at %s", path)
+		}
+		return nil, fmt.Errorf("failed to read rules: %w", err)
+	}
+
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i, r := range file.Rules {
+		if r == nil {
+			return nil, fmt.Errorf("rule %d is empty", i+1)
+		}
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule %d has no name", i+1)
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("duplicate rule name '%s'", r.Name)
+		}
+		seen[r.Name] = true
+
+		if err := r.compile(filepath.Dir(path)); err != nil {
+			return nil, fmt.Errorf("rule '%s': %w", r.Name, err)
+		}
+	}
+
+	return file.Rules, nil
+}
+
+// compile validates a rule and prepares its patterns. Relative attachment
+// directories are resolved against baseDir.
+func (r *Rule) compile(baseDir string) error {
+	if r.Query == "" {
+		r.Query = DefaultQuery
+	}
+
+	if len(r.Actions.Label) == 0 && !r.Actions.Archive && !r.Actions.MarkRead && len(r.Actions.Forward) == 0 &&
+		r.Actions.ReplyTemplate == "" && r.Actions.SaveAttachments == "" && r.Actions.Exec == "" {
+		return fmt.Errorf("no actions")
+	}
+
+	var err error
+	for _, p := range []struct {
+		field   string
+		pattern string
+		dest    **regexp.Regexp
+	}{
+		{"from", r.Match.From, &r.from},
+		{"to", r.Match.To, &r.to},
+		{"subject", r.Match.Subject, &r.subject},
+		{"body", r.Match.Body, &r.body},
+		{"attachment", r.Match.Attachment, &r.attachment},
+	} {
+		if *p.dest, err = compilePattern(p.pattern); err != nil {
+			return fmt.Errorf("invalid %s pattern: %w", p.field, err)
+		}
+	}
+
+	r.headers = nil
+	for _, name := range sortedKeys(r.Match.Headers) {
+		re, err := compilePattern(r.Match.Headers[name])
+		if err != nil {
+			return fmt.Errorf("invalid pattern for header %s: %w", name, err)
+		}
+		if re == nil {
+			// An empty pattern requires the header to be missing or empty
+			re = regexp.MustCompile(`^$`)
+		}
+		r.headers = append(r.headers, headerPattern{textproto.CanonicalMIMEHeaderKey(name), re})
+	}
+
+	if dir := r.Actions.SaveAttachments; dir != "" {
+		if dir == "~" || strings.HasPrefix(dir, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("failed to get home directory: %w", err)
+			}
+			dir = filepath.Join(home, strings.TrimPrefix(dir, "~"))
+		} else if !filepath.IsAbs(dir) {
+			dir = filepath.Join(baseDir, dir)
+		}
+		r.Actions.SaveAttachments = dir
+	}
+
+	return nil
+}
+
+// compilePattern compiles a case-insensitive pattern, returning nil for an
+// empty one
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// Evaluate reports whether a message meets the rule's conditions, explaining
+// each condition that matched. Attachments lists the attachments that met
+// the attachment condition, or all of them when the rule has none.
+func (r *Rule) Evaluate(msg Message) (ok bool, reasons []string, attachments []Attachment) {
+	check := func(field string, re *regexp.Regexp, values ...string) bool {
+		if re == nil {
+			return true
+		}
+		for _, v := range values {
+			if re.MatchString(v) {
+				reasons = append(reasons, fmt.Sprintf("%s %q matches /%s/", field, v, strings.TrimPrefix(re.String(), "(?i)")))
+				return true
+			}
+		}
+		return false
+	}
+
+	if !check("from", r.from, msg.From) ||
+		!check("to", r.to, msg.To...) ||
+		!check("subject", r.subject, msg.Subject) {
+		return false, nil, nil
+	}
+	for _, h := range r.headers {
+		if !check("header "+h.name, h.re, msg.Headers[h.name]) {
+			return false, nil, nil
+		}
+	}
+	if r.body != nil {
+		if !r.body.MatchString(msg.Body) {
+			return false, nil, nil
+		}
+		reasons = append(reasons, fmt.Sprintf("body matches /%s/", strings.TrimPrefix(r.body.String(), "(?i)")))
+	}
+
+	if r.attachment == nil {
+		return true, reasons, msg.Attachments
+	}
+	for _, a := range msg.Attachments {
+		if r.attachment.MatchString(a.Filename) || r.attachment.MatchString(a.MimeType) {
+			attachments = append(attachments, a)
+		}
+	}
+	if len(attachments) == 0 {
+		return false, nil, nil
+	}
+	names := make([]string, len(attachments))
+	for i, a := range attachments {
+		names[i] = a.Filename
+	}
+	reasons = append(reasons, fmt.Sprintf("attachment %s matches /%s/", strings.Join(names, ", "), r.Match.Attachment))
+
+	return true, reasons, attachments
+}
+
+// Describe lists the rule's actions for display
+func (a Actions) Describe() []string {
+	var parts []string
+	if len(a.Label) > 0 {
+		parts = append(parts, "label "+strings.Join(a.Label, ", "))
+	}
+	if a.Archive {
+		parts = append(parts, "archive")
+	}
+	if a.MarkRead {
+		parts = append(parts, "mark read")
+	}
+	if len(a.Forward) > 0 {
+		parts = append(parts, "forward to "+strings.Join(a.Forward, ", "))
+	}
+	if a.ReplyTemplate != "" {
+		parts = append(parts, "reply with template "+a.ReplyTemplate)
+	}
+	if a.SaveAttachments != "" {
+		parts = append(parts, "save attachments to "+a.SaveAttachments)
+	}
+	if a.Exec != "" {
+		parts = append(parts, "run "+a.Exec)
+	}
+	return parts
+}
+
+// Describe lists the rule's conditions for display
+func (c Conditions) Describe() []string {
+	var parts []string
+	for _, p := range []struct{ field, pattern string }{
+		{"from", c.From},
+		{"to", c.To},
+		{"subject", c.Subject},
+		{"body", c.Body},
+		{"attachment", c.Attachment},
+	} {
+		if p.pattern != "" {
+			parts = append(parts, fmt.Sprintf("%s /%s/", p.field, p.pattern))
+		}
+	}
+	for _, name := range sortedKeys(c.Headers) {
+		if c.Headers[name] == "" {
+			parts = append(parts, name+" missing")
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s /%s/", name, c.Headers[name]))
+	}
+	return parts
+}
+
+// sortedKeys returns a map's keys in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
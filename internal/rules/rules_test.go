@@ -0,0 +1,312 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loadRules writes a rules file and loads it
+func loadRules(t *testing.T, content string) ([]*Rule, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return Load(path)
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			content: `rules:
+  - name: a
+    actions: {archive: true}
+  - name: b
+    query: "from:x"
+    match: {subject: "^re:"}
+    actions: {label: [Work]}`,
+		},
+		{
+			name: "no actions",
+			content: `rules:
+  - name: a
+    match: {subject: x}`,
+			wantErr: "rule 'a': no actions",
+		},
+		{
+			name: "duplicate name",
+			content: `rules:
+  - name: a
+    actions: {archive: true}
+  - name: a
+    actions: {mark_read: true}`,
+			wantErr: "duplicate rule name 'a'",
+		},
+		{
+			name: "missing name",
+			content: `rules:
+  - actions: {archive: true}`,
+			wantErr: "rule 1 has no name",
+		},
+		{
+			name: "invalid pattern",
+			content: `rules:
+  - name: a
+    match: {from: "("}
+    actions: {archive: true}`,
+			wantErr: "rule 'a': invalid from pattern",
+		},
+		{
+			name: "invalid header pattern",
+			content: `rules:
+  - name: a
+    match: {headers: {X-Priority: "["}}
+    actions: {archive: true}`,
+			wantErr: "rule 'a': invalid pattern for header X-Priority",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadRules(t, tt.content)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Load() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	ruleSet, err := loadRules(t, `rules:
+  - name: a
+    actions: {save_attachments: invoices}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := ruleSet[0]
+	if r.Query != DefaultQuery {
+		t.Errorf("query = %q, want %q", r.Query, DefaultQuery)
+	}
+	if !filepath.IsAbs(r.Actions.SaveAttachments) || filepath.Base(r.Actions.SaveAttachments) != "invoices" {
+		t.Errorf("save_attachments = %q, want an absolute path next to the rules file", r.Actions.SaveAttachments)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	pdf := Attachment{Filename: "Invoice-42.PDF", MimeType: "application/pdf"}
+	png := Attachment{Filename: "logo.png", MimeType: "image/png"}
+	msg := Message{
+		From:        "Billing <billing@vendor.com>",
+		To:          []string{"me@example.com", "accounting@example.com"},
+		Subject:     "Your INVOICE for March",
+		Body:        "Amount due: $42",
+		Headers:     map[string]string{"X-Priority": "1 (Highest)"},
+		Attachments: []Attachment{pdf, png},
+	}
+
+	tests := []struct {
+		name        string
+		match       string
+		want        bool
+		attachments []Attachment
+	}{
+		{
+			name:        "no conditions match everything",
+			match:       `{}`,
+			want:        true,
+			attachments: []Attachment{pdf, png},
+		},
+		{
+			name:        "matching is case-insensitive",
+			match:       `{from: "BILLING@VENDOR", subject: "invoice"}`,
+			want:        true,
+			attachments: []Attachment{pdf, png},
+		},
+		{
+			name:  "all conditions must match",
+			match: `{from: "billing@", subject: "receipt"}`,
+			want:  false,
+		},
+		{
+			name:        "to also checks cc",
+			match:       `{to: "^accounting@"}`,
+			want:        true,
+			attachments: []Attachment{pdf, png},
+		},
+		{
+			name:  "to without a matching recipient",
+			match: `{to: "sales@"}`,
+			want:  false,
+		},
+		{
+			name:        "body",
+			match:       `{body: "amount due"}`,
+			want:        true,
+			attachments: []Attachment{pdf, png},
+		},
+		{
+			name:        "header name is case-insensitive",
+			match:       `{headers: {x-priority: "^1"}}`,
+			want:        true,
+			attachments: []Attachment{pdf, png},
+		},
+		{
+			name:        "empty header pattern matches a missing header",
+			match:       `{headers: {List-Id: ""}}`,
+			want:        true,
+			attachments: []Attachment{pdf, png},
+		},
+		{
+			name:  "empty header pattern does not match a present header",
+			match: `{headers: {X-Priority: ""}}`,
+			want:  false,
+		},
+		{
+			name:        "attachment by filename keeps only matching files",
+			match:       `{attachment: '\.pdf$'}`,
+			want:        true,
+			attachments: []Attachment{pdf},
+		},
+		{
+			name:        "attachment by content type",
+			match:       `{attachment: "^image/"}`,
+			want:        true,
+			attachments: []Attachment{png},
+		},
+		{
+			name:  "no matching attachment",
+			match: `{attachment: '\.zip$'}`,
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ruleSet, err := loadRules(t, "rules:\n  - name: r\n    match: "+tt.match+"\n    actions: {archive: true}\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ok, reasons, attachments := ruleSet[0].Evaluate(msg)
+			if ok != tt.want {
+				t.Fatalf("Evaluate() = %v, want %v (reasons %v)", ok, tt.want, reasons)
+			}
+			if !reflect.DeepEqual(attachments, tt.attachments) {
+				t.Errorf("attachments = %v, want %v", attachments, tt.attachments)
+			}
+			if !ok && reasons != nil {
+				t.Errorf("reasons = %v, want none for a message that does not match", reasons)
+			}
+		})
+	}
+}
+
+func TestStateIsProcessed(t *testing.T) {
+	tests := []struct {
+		name string
+		mark func(s *State)
+		rule string
+		want bool
+	}{
+		{
+			name: "unprocessed",
+			mark: func(s *State) {},
+			rule: "invoices",
+			want: false,
+		},
+		{
+			name: "all rules run",
+			mark: func(s *State) { s.MarkProcessed("work", "m1") },
+			rule: "invoices",
+			want: true,
+		},
+		{
+			name: "same rule run",
+			mark: func(s *State) { s.MarkRuleProcessed("work", "invoices", "m1") },
+			rule: "invoices",
+			want: true,
+		},
+		{
+			name: "other rule run",
+			mark: func(s *State) { s.MarkRuleProcessed("work", "urgent", "m1") },
+			rule: "invoices",
+			want: false,
+		},
+		{
+			name: "other message",
+			mark: func(s *State) { s.MarkProcessed("work", "m2") },
+			rule: "invoices",
+			want: false,
+		},
+		{
+			name: "other account",
+			mark: func(s *State) {
+				s.MarkProcessed("personal", "m1")
+				s.MarkRuleProcessed("personal", "invoices", "m1")
+			},
+			rule: "invoices",
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &State{
+				Processed: make(map[string]map[string]time.Time),
+				Rules:     make(map[string]map[string]map[string]time.Time),
+			}
+			tt.mark(s)
+			if got := s.IsProcessed("work", tt.rule, "m1"); got != tt.want {
+				t.Errorf("IsProcessed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStateSaveAndLoad(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	state, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.MarkProcessed("work", "m1")
+	state.MarkRuleProcessed("work", "invoices", "m2")
+	old := time.Now().Add(-Retention - time.Hour)
+	state.Processed["work"]["expired"] = old
+	state.MarkRuleProcessed("work", "invoices", "expired")
+	state.Rules["work"]["expired"]["invoices"] = old
+
+	if err := SaveState(state); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !loaded.IsProcessed("work", "urgent", "m1") {
+		t.Error("message processed by all rules was not kept")
+	}
+	if !loaded.IsProcessed("work", "invoices", "m2") || loaded.IsProcessed("work", "urgent", "m2") {
+		t.Error("message processed by one rule was not kept per rule")
+	}
+	if loaded.IsProcessed("work", "invoices", "expired") {
+		t.Error("message processed longer ago than Retention was kept")
+	}
+}
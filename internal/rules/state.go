@@ -0,0 +1,149 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alexandraswan/gcli/internal/config"
+)
+
+const stateFileName = "rules-state.json"
+
+// Retention is how long processed messages are remembered. Rules only look
+// at messages newer than this so forgotten messages are never run again.
+const Retention = 90 * 24 * time.Hour
+
+// State records which messages rules have already been run on
+type State struct {
+	// Processed maps account names to message IDs and when all rules were
+	// run on them
+	Processed map[string]map[string]time.Time `json:"processed"`
+	// Rules maps account names to message IDs and when single rules were
+	// run on them, for runs limited to some rules
+	Rules map[string]map[string]map[string]time.Time `json:"rules,omitempty"`
+}
+
+// getStatePath returns the path to the rules state file
+func getStatePath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, stateFileName), nil
+}
+
+// LoadState loads the record of processed messages
+func LoadState() (*State, error) {
+	path, err := getStatePath()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &State{
+		Processed: make(map[string]map[string]time.Time),
+		Rules:     make(map[string]map[string]map[string]time.Time),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read rules state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse rules state: %w", err)
+	}
+	if state.Processed == nil {
+		state.Processed = make(map[string]map[string]time.Time)
+	}
+	if state.Rules == nil {
+		state.Rules = make(map[string]map[string]map[string]time.Time)
+	}
+
+	return state, nil
+}
+
+// SaveState saves the record of processed messages, forgetting those
+// processed longer ago than Retention
+func SaveState(state *State) error {
+	if err := config.EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	path, err := getStatePath()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-Retention)
+	for account, ids := range state.Processed {
+		for id, at := range ids {
+			if at.Before(cutoff) {
+				delete(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			delete(state.Processed, account)
+		}
+	}
+	for account, ids := range state.Rules {
+		for id, byRule := range ids {
+			for rule, at := range byRule {
+				if at.Before(cutoff) {
+					delete(byRule, rule)
+				}
+			}
+			if len(byRule) == 0 {
+				delete(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			delete(state.Rules, account)
+		}
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal rules state: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write rules state: %w", err)
+	}
+
+	return nil
+}
+
+// IsProcessed reports whether a rule has already been run on a message,
+// either on its own or together with all other rules
+func (s *State) IsProcessed(account, rule, messageID string) bool {
+	if _, ok := s.Processed[account][messageID]; ok {
+		return true
+	}
+	_, ok := s.Rules[account][messageID][rule]
+	return ok
+}
+
+// MarkProcessed records that all rules have been run on a message
+func (s *State) MarkProcessed(account, messageID string) {
+	if s.Processed[account] == nil {
+		s.Processed[account] = make(map[string]time.Time)
+	}
+	s.Processed[account][messageID] = time.Now()
+}
+
+// MarkRuleProcessed records that a single rule has been run on a message
+func (s *State) MarkRuleProcessed(account, rule, messageID string) {
+	if s.Rules[account] == nil {
+		s.Rules[account] = make(map[string]map[string]time.Time)
+	}
+	if s.Rules[account][messageID] == nil {
+		s.Rules[account][messageID] = make(map[string]time.Time)
+	}
+	s.Rules[account][messageID][rule] = time.Now()
+}
@@ -0,0 +1,220 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/alexandraswan/gcli/internal/config"
+)
+
+// Body formats, chosen by the template file's extension
+const (
+	FormatText     = "text"
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+)
+
+// formats maps template file extensions to body formats
+var formats = map[string]string{
+	".txt":  FormatText,
+	".html": FormatHTML,
+	".htm":  FormatHTML,
+	".md":   FormatMarkdown,
+}
+
+// extensions is the order extensions are tried in when loading by name
+var extensions = []string{".md", ".html", ".htm", ".txt"}
+
+// headers are the fields a template may set before its body
+var headers = map[string]bool{
+	"subject": true,
+	"to":      true,
+	"cc":      true,
+	"bcc":     true,
+}
+
+// Template is an email template. Its header fields and body are Go
+// text/template sources; HTML bodies are html/template sources so that
+// values are escaped.
+type Template struct {
+	Name   string
+	Path   string
+	Format string
+	// Headers holds the raw header values keyed by lowercase field name
+	Headers map[string]string
+	Body    string
+}
+
+// Message is a rendered template
+type Message struct {
+	Subject string
+	To      []string
+	CC      []string
+	BCC     []string
+	Body    string
+	Format  string
+}
+
+// GetTemplatesDir returns the directory templates are stored in
+func GetTemplatesDir() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "templates"), nil
+}
+
+// List returns the available templates sorted by name
+func List() ([]*Template, error) {
+	dir, err := GetTemplatesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates directory: %w", err)
+	}
+
+	var list []*Template
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if _, ok := formats[strings.ToLower(filepath.Ext(e.Name()))]; !ok {
+			continue
+		}
+		t, err := loadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Load loads a template by name, or from a path when name contains a path
+// separator or extension
+func Load(name string) (*Template, error) {
+	if strings.ContainsRune(name, filepath.Separator) || filepath.Ext(name) != "" {
+		return loadFile(name)
+	}
+
+	dir, err := GetTemplatesDir()
+	if err != nil {
+		return nil, err
+	}
+	for _, ext := range extensions {
+		path := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return loadFile(path)
+		}
+	}
+	return nil, fmt.Errorf("template '%s' not found in %s", name, dir)
+}
+
+// loadFile reads a template file. An optional block of header fields
+// ("Subject: ...") followed by a blank line precedes the body.
+func loadFile(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+
+	ext := filepath.Ext(path)
+	format, ok := formats[strings.ToLower(ext)]
+	if !ok {
+		format = FormatText
+	}
+
+	t := &Template{
+		Name:    strings.TrimSuffix(filepath.Base(path), ext),
+		Path:    path,
+		Format:  format,
+		Headers: make(map[string]string),
+	}
+
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if head, body, found := strings.Cut(content, "\n\n"); found {
+		fields := make(map[string]string)
+		for _, line := range strings.Split(head, "\n") {
+			key, value, ok := strings.Cut(line, ":")
+			key = strings.ToLower(strings.TrimSpace(key))
+			if !ok || !headers[key] {
+				// Not a header block; the whole file is the body
+				fields = nil
+				break
+			}
+			fields[key] = strings.TrimSpace(value)
+		}
+		if fields != nil {
+			t.Headers = fields
+			content = body
+		}
+	}
+	t.Body = content
+
+	return t, nil
+}
+
+// executor is the part of text/template and html/template Render needs
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// Render executes the template with the given variables. Referencing a
+// variable that is not set is an error. Values are HTML-escaped in the
+// body of HTML templates.
+func (t *Template) Render(vars map[string]string) (*Message, error) {
+	render := func(field, src string) (string, error) {
+		var tmpl executor
+		var err error
+		if field == "body" && t.Format == FormatHTML {
+			tmpl, err = htmltemplate.New(field).Option("missingkey=error").Parse(src)
+		} else {
+			tmpl, err = template.New(field).Option("missingkey=error").Parse(src)
+		}
+		if err != nil {
+			return "", fmt.Errorf("template %s: invalid %s: %w", t.Name, field, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, vars); err != nil {
+			return "", fmt.Errorf("template %s: failed to render %s: %w", t.Name, field, err)
+		}
+		return buf.String(), nil
+	}
+
+	msg := &Message{Format: t.Format}
+
+	var err error
+	if msg.Subject, err = render("subject", t.Headers["subject"]); err != nil {
+		return nil, err
+	}
+	if msg.Body, err = render("body", t.Body); err != nil {
+		return nil, err
+	}
+
+	for field, dest := range map[string]*[]string{"to": &msg.To, "cc": &msg.CC, "bcc": &msg.BCC} {
+		value, err := render(field, t.Headers[field])
+		if err != nil {
+			return nil, err
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			*dest = []string{value}
+		}
+	}
+
+	return msg, nil
+}
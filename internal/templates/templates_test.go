@@ -0,0 +1,87 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	vars := map[string]string{"name": "<b>&", "email": "a@example.com"}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		subject string
+		to      []string
+		body    string
+	}{
+		{
+			name:    "text body is not escaped",
+			file:    "hello.txt",
+			content: "Subject: Hi {{.name}}\nTo: {{.email}}\n\nHello {{.name}}\n",
+			subject: "Hi <b>&",
+			to:      []string{"a@example.com"},
+			body:    "Hello <b>&\n",
+		},
+		{
+			name:    "markdown body is not escaped",
+			file:    "hello.md",
+			content: "Subject: Hi {{.name}}\n\n**Hello** {{.name}}\n",
+			subject: "Hi <b>&",
+			body:    "**Hello** <b>&\n",
+		},
+		{
+			name:    "html body is escaped but subject is not",
+			file:    "hello.html",
+			content: "Subject: Hi {{.name}}\n\n<p>Hello {{.name}}</p>\n",
+			subject: "Hi <b>&",
+			body:    "<p>Hello &lt;b&gt;&amp;</p>\n",
+		},
+		{
+			name:    "html attributes are escaped",
+			file:    "link.htm",
+			content: `<a title="{{.name}}">x</a>`,
+			body:    `<a title="&lt;b&gt;&amp;">x</a>`,
+		},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			tmpl, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msg, err := tmpl.Render(vars)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg.Subject != tt.subject {
+				t.Errorf("subject = %q, want %q", msg.Subject, tt.subject)
+			}
+			if strings.Join(msg.To, ",") != strings.Join(tt.to, ",") {
+				t.Errorf("to = %v, want %v", msg.To, tt.to)
+			}
+			if msg.Body != tt.body {
+				t.Errorf("body = %q, want %q", msg.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestRenderMissingVariable(t *testing.T) {
+	for _, format := range []string{FormatText, FormatHTML} {
+		tmpl := &Template{Name: "t", Format: format, Headers: map[string]string{}, Body: "Hi {{.name}}"}
+		if _, err := tmpl.Render(map[string]string{}); err == nil {
+			t.Errorf("%s: rendering with a missing variable succeeded, want error", format)
+		}
+	}
+}